@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewAPIKeyCmd())
+}
+
+// NewAPIKeyCmd returns a new `apikey` command to be used as a sub-command to root
+func NewAPIKeyCmd() *cobra.Command {
+	var email string
+
+	apiKeyCmd := cobra.Command{
+		Use:              "apikey",
+		Short:            "Manage the API keys of a user",
+		PersistentPreRun: validateConfig(&cfg.Database),
+	}
+	apiKeyCmd.PersistentFlags().StringVar(&email, "email", "", "email of the user owning the keys")
+	_ = apiKeyCmd.MarkPersistentFlagRequired("email")
+
+	var name, scope string
+	createCmd := cobra.Command{
+		Use:   "create",
+		Short: "Issue a new API key",
+		Example: `  # Issue a read-only key for a CI job
+  todo-cli apikey create --email me@example.com --name ci --scope read
+`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if scope != string(model.ReadScope) && scope != string(model.WriteScope) {
+				log.Fatalf("unknown scope: %s", scope)
+			}
+			apiKey, key, err := apiKeyService(email).Create(name, model.Scope(scope))
+			if err != nil {
+				log.Fatalf("failed to create API key err: %s", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "API key %d (%s, %s) created. It will not be shown again:\n%s\n",
+				apiKey.ID, apiKey.Name, apiKey.Scope, key)
+		},
+	}
+	createCmd.Flags().StringVar(&name, "name", "", "name describing the use of the key")
+	createCmd.Flags().StringVar(&scope, "scope", string(model.ReadScope), "Scope of the key. One of: read|write")
+	_ = createCmd.MarkFlagRequired("name")
+
+	listCmd := cobra.Command{
+		Use:   "list",
+		Short: "List API keys",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			keys, err := apiKeyService(email).FindAll()
+			if err != nil {
+				log.Fatalf("failed to list API keys err: %s", err)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPE\tCREATED\tLAST USED\tREVOKED")
+			for _, k := range keys {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					k.ID, k.Name, k.Prefix, k.Scope, formatTime(&k.CreatedAt), formatTime(k.LastUsedAt), formatTime(k.RevokedAt))
+			}
+			_ = w.Flush()
+		},
+	}
+
+	revokeCmd := cobra.Command{
+		Use:   "revoke ID",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				log.Fatalf("invalid API key id: %s", args[0])
+			}
+			if err := apiKeyService(email).Revoke(id); err != nil {
+				log.Fatalf("failed to revoke API key %d err: %s", id, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "API key %d revoked.\n", id)
+		},
+	}
+
+	apiKeyCmd.AddCommand(&createCmd, &listCmd, &revokeCmd)
+	return &apiKeyCmd
+}
+
+// apiKeyService returns the API key service of the user with the given email.
+func apiKeyService(email string) service.APIKey {
+	dbInstance, err := db.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to open %s database err: %s", cfg.Database.Driver, err)
+	}
+	user, err := repository.NewUser(dbInstance).FindByEmail(email)
+	if err != nil {
+		log.Fatalf("failed to find user: %s err: %s", email, err)
+	}
+	return service.NewAPIKey(repository.NewAPIKey(dbInstance)).ForOwner(user.ID)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
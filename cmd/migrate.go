@@ -3,37 +3,180 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 )
 
-// migrateCmd represents the migrate command
-var migrateCmd = &cobra.Command{
-	Use:   "migrate",
-	Short: "Migrate the database",
-	Run: func(_ *cobra.Command, _ []string) {
-		dbDir := filepath.Dir(cfg.SQLite.DBFilename)
-		if err := os.MkdirAll(dbDir, os.ModePerm); err != nil {
-			log.Fatalf("failed to create directory: %s err: %s", dbDir, err)
-			return
-		}
+func init() {
+	rootCmd.AddCommand(NewMigrateCmd())
+}
+
+// NewMigrateCmd returns a new `migrate` command to be used as a sub-command to root
+func NewMigrateCmd() *cobra.Command {
+	var dryRun bool
+	var owner string
 
-		dbInstance, err := db.New(cfg.SQLite.DBFilename)
-		if err != nil {
-			log.Fatalf("failed to open database filename: %s err: %s", cfg.SQLite.DBFilename, err)
-			return
+	up := func(cmd *cobra.Command, _ []string) {
+		dbInstance := migrate(cmd.OutOrStdout(), dryRun, func(m *db.Migrator) ([]db.Migration, error) { return m.Up() })
+		if owner != "" && !dryRun {
+			claimUnowned(cmd.OutOrStdout(), dbInstance, owner)
 		}
-		if err := db.Migrate(dbInstance); err != nil {
-			log.Fatalf("failed to migrate database err: %s", err)
+	}
+	migrateCmd := cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the database",
+		Long:  "Migrate the database. Without a sub-command the pending migrations are applied as with `migrate up`.",
+		Example: `  # Give the todos created before there were users to a registered user
+  todo-cli migrate --owner me@example.com
+`,
+		Args:             cobra.NoArgs,
+		PersistentPreRun: validateConfig(&cfg.Database),
+		Run:              up,
+	}
+	migrateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the SQL of the migrations instead of running it")
+	migrateCmd.Flags().StringVar(&owner, "owner", "", "email of the registered user given the todos, projects and tags without an owner")
+
+	upCmd := cobra.Command{
+		Use:   "up",
+		Short: "Apply the pending migrations",
+		Args:  cobra.NoArgs,
+		Run:   up,
+	}
+	upCmd.Flags().StringVar(&owner, "owner", "", "email of the registered user given the todos, projects and tags without an owner")
+
+	downCmd := cobra.Command{
+		Use:   "down [N]",
+		Short: "Revert the last N applied migrations, 1 by default",
+		Example: `  # Print the SQL reverting the last two migrations
+  todo-cli migrate down 2 --dry-run
+`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			n := 1
+			if len(args) > 0 {
+				var err error
+				if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+					log.Fatalf("invalid number of migrations: %s", args[0])
+				}
+			}
+			migrate(cmd.OutOrStdout(), dryRun, func(m *db.Migrator) ([]db.Migration, error) { return m.Down(n) })
+		},
+	}
+
+	gotoCmd := cobra.Command{
+		Use:   "goto V",
+		Short: "Apply or revert the migrations to bring the schema to version V, 0 reverting them all",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version < 0 {
+				log.Fatalf("invalid migration version: %s", args[0])
+			}
+			migrate(cmd.OutOrStdout(), dryRun, func(m *db.Migrator) ([]db.Migration, error) { return m.Goto(version) })
+		},
+	}
+
+	statusCmd := cobra.Command{
+		Use:   "status",
+		Short: "List the migrations and when they were applied",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			_, m := migrator(nil)
+			statuses, err := m.Status()
+			if err != nil {
+				log.Fatalf("failed to read migration status err: %s", err)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+			for _, s := range statuses {
+				applied := "pending"
+				if s.AppliedAt != nil && s.AppliedAt.IsZero() {
+					applied = "found applied"
+				} else if s.AppliedAt != nil {
+					applied = formatTime(s.AppliedAt)
+				}
+				fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+			}
+			_ = w.Flush()
+		},
+	}
+
+	migrateCmd.AddCommand(&upCmd, &downCmd, &gotoCmd, &statusCmd)
+	return &migrateCmd
+}
+
+// migrate runs fn on the migrator of the database and reports the migrations it applied or reverted.
+// It returns the database.
+func migrate(out io.Writer, dryRun bool, fn func(m *db.Migrator) ([]db.Migration, error)) *gorm.DB {
+	var sqlOut io.Writer
+	if dryRun {
+		sqlOut = out
+	}
+	dbInstance, m := migrator(sqlOut)
+	migrations, err := fn(m)
+	if err != nil {
+		log.Fatalf("failed to migrate database err: %s", err)
+	}
+	if dryRun {
+		return dbInstance
+	}
+
+	version, err := m.Version()
+	if err != nil {
+		log.Fatalf("failed to read migration version err: %s", err)
+	}
+	// The search index is built on the todos table, which only exists from the first migration on.
+	if version > 0 {
+		if err := db.MigrateSearch(dbInstance); err != nil {
+			log.Fatalf("failed to migrate search index err: %s", err)
 		}
-		fmt.Println("Migration completed. SQLite.DBFilename: ", cfg.SQLite.DBFilename)
-	},
+	}
+	for _, migration := range migrations {
+		fmt.Fprintf(out, "%04d_%s\n", migration.Version, migration.Name)
+	}
+	fmt.Fprintf(out, "Migration completed at version %d. Database.Driver: %s\n", version, cfg.Database.Driver)
+	return dbInstance
 }
 
-func init() {
-	rootCmd.AddCommand(migrateCmd)
+// claimUnowned gives the data created before there were users to the registered user of the email.
+func claimUnowned(out io.Writer, dbInstance *gorm.DB, email string) {
+	users := repository.NewUser(dbInstance)
+	user, err := users.FindByEmail(email)
+	if err != nil {
+		log.Fatalf("failed to find the owner %s err: %s", email, err)
+	}
+	claimed, err := users.ClaimUnowned(user.ID)
+	if err != nil {
+		log.Fatalf("failed to claim the data without an owner err: %s", err)
+	}
+	fmt.Fprintf(out, "%d todo(s) without an owner given to %s\n", claimed, user.Email)
+}
+
+// migrator opens the configured database and returns it with its migrator, writing the SQL to dryRun when set.
+func migrator(dryRun io.Writer) (*gorm.DB, *db.Migrator) {
+	if cfg.Database.Driver == db.SQLite {
+		dbDir := filepath.Dir(cfg.Database.DSN)
+		if err := os.MkdirAll(dbDir, os.ModePerm); err != nil {
+			log.Fatalf("failed to create directory: %s err: %s", dbDir, err)
+		}
+	}
+
+	dbInstance, err := db.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to open %s database err: %s", cfg.Database.Driver, err)
+	}
+	m, err := db.NewMigrator(dbInstance, dryRun)
+	if err != nil {
+		log.Fatalf("failed to load migrations err: %s", err)
+	}
+	return dbInstance, m
 }
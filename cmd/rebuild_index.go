@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// rebuildIndexCmd represents the rebuild-index command
+var rebuildIndexCmd = &cobra.Command{
+	Use:    "rebuild-index",
+	Short:  "Rebuild the full-text search index of todos",
+	PreRun: validateConfig(&cfg.Database),
+	Run: func(_ *cobra.Command, _ []string) {
+		dbInstance, err := db.New(cfg.Database)
+		if err != nil {
+			log.Fatalf("failed to open %s database err: %s", cfg.Database.Driver, err)
+			return
+		}
+		if !db.SearchAvailable(dbInstance) {
+			log.Fatalf("full-text search index not found, run the migrate command with a build supporting FTS5")
+			return
+		}
+		if err := db.RebuildSearchIndex(dbInstance); err != nil {
+			log.Fatalf("failed to rebuild search index err: %s", err)
+		}
+		fmt.Println("Search index rebuilt. Database.Driver: ", cfg.Database.Driver)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rebuildIndexCmd)
+}
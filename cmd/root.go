@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/fardinabir/todo-manager-app/internal/model"
 	"github.com/go-playground/validator/v10"
@@ -64,6 +65,17 @@ func initConfig() {
 	cfg = model.Config{
 		APIServer:     model.Server{Enable: true, Port: 8080},
 		SwaggerServer: model.Server{Enable: false, Port: 1314},
+		GRPCServer:    model.Server{Enable: true, Port: 9090},
+		Database:      model.Database{Driver: "sqlite"},
+		Auth:          model.Auth{TokenTTL: 24 * time.Hour},
+		Trash:         model.Trash{Retention: 30 * 24 * time.Hour, SweepInterval: time.Hour},
+		Webhooks: model.Webhooks{
+			PollInterval: 5 * time.Second,
+			Timeout:      10 * time.Second,
+			MaxAttempts:  8,
+			Backoff:      30 * time.Second,
+			MaxBackoff:   6 * time.Hour,
+		},
 	}
 
 	err := viper.Unmarshal(&cfg)
@@ -71,8 +83,20 @@ func initConfig() {
 		log.Fatalf("unable to decode into struct, %v", err)
 	}
 
-	validate := validator.New()
-	if err := validate.Struct(&cfg); err != nil {
-		log.Fatalf("config validation failed: %v", err)
+	if cfg.ApplySQLite() {
+		log.Warnf("sqLite.dbFilename is deprecated, set database.driver: sqlite and database.dsn: %q instead", cfg.Database.DSN)
+	}
+}
+
+// validateConfig returns the pre-run of a command checking the sections of the configuration it uses,
+// the sections it does not use may be left unset.
+func validateConfig(sections ...interface{}) func(*cobra.Command, []string) {
+	return func(_ *cobra.Command, _ []string) {
+		validate := validator.New()
+		for _, section := range sections {
+			if err := validate.Struct(section); err != nil {
+				log.Fatalf("config validation failed: %v", err)
+			}
+		}
 	}
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,6 +25,7 @@ func NewServerCmd() *cobra.Command {
 		Example: `  # Print the full version of client and server to stdout
   todo-cli server
 `,
+		PreRun: validateConfig(&cfg),
 		Run: func(_ *cobra.Command, _ []string) {
 			var servers []server.Server
 
@@ -39,12 +41,38 @@ func NewServerCmd() *cobra.Command {
 
 			if cfg.SwaggerServer.Enable {
 				SwaggerOpts := server.SwaggerServerOpts{
-					ListenPort: cfg.SwaggerServer.Port,
+					ListenPort:      cfg.SwaggerServer.Port,
+					GraphQLEndpoint: fmt.Sprintf("http://localhost:%d/api/v1/graphql", cfg.APIServer.Port),
 				}
 				swagServer := server.NewSwagger(SwaggerOpts)
 				servers = append(servers, swagServer)
 			}
 
+			if cfg.GRPCServer.Enable {
+				grpcServer, err := server.NewGRPC(server.GRPCServerOpts{
+					ListenPort: cfg.GRPCServer.Port,
+					Config:     cfg,
+				})
+				if err != nil {
+					log.Fatal(err)
+				}
+				servers = append(servers, grpcServer)
+			}
+
+			if cfg.Trash.Retention > 0 {
+				sweeper, err := server.NewTrashSweeper(server.TrashSweeperOpts{Config: cfg})
+				if err != nil {
+					log.Fatal(err)
+				}
+				servers = append(servers, sweeper)
+			}
+
+			dispatcher, err := server.NewWebhookDispatcher(server.WebhookDispatcherOpts{Config: cfg})
+			if err != nil {
+				log.Fatal(err)
+			}
+			servers = append(servers, dispatcher)
+
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 			defer stop()
 
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/fardinabir/todo-manager-app/internal/transfer"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewExportCmd(), NewImportCmd())
+}
+
+// NewExportCmd returns a new `export` command to be used as a sub-command to root
+func NewExportCmd() *cobra.Command {
+	var email, format, output string
+
+	exportCmd := cobra.Command{
+		Use:   "export",
+		Short: "Export the todos of a user to a file",
+		Example: `  # Export as CSV
+  todo-cli export --email me@example.com --format csv --output todos.csv
+`,
+		Args:   cobra.NoArgs,
+		PreRun: validateConfig(&cfg.Database),
+		Run: func(cmd *cobra.Command, _ []string) {
+			out := cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					log.Fatalf("failed to create file: %s err: %s", output, err)
+				}
+				defer f.Close()
+				out = f
+			}
+			w, err := transfer.NewWriter(transfer.Format(format), out)
+			if err != nil {
+				log.Fatalf("failed to export err: %s", err)
+			}
+			n, err := transfer.Export(todoService(email), w)
+			if err != nil {
+				log.Fatalf("failed to export err: %s", err)
+			}
+			log.Infof("exported %d todos", n)
+		},
+	}
+	exportCmd.Flags().StringVar(&email, "email", "", "email of the user owning the todos")
+	exportCmd.Flags().StringVar(&format, "format", string(transfer.JSON), "Format of the file. One of: json|csv|ics|todotxt")
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "file to write, standard output by default")
+	_ = exportCmd.MarkFlagRequired("email")
+	return &exportCmd
+}
+
+// NewImportCmd returns a new `import` command to be used as a sub-command to root
+func NewImportCmd() *cobra.Command {
+	var (
+		email, format string
+		opts          transfer.ImportOptions
+	)
+
+	importCmd := cobra.Command{
+		Use:   "import FILE",
+		Short: "Import todos for a user from a file",
+		Long: `Import todos for a user from a file, or from the standard input when FILE is -.
+Rows that are invalid are reported and skipped, the others are imported.`,
+		Example: `  # Import a CSV export into another environment, keeping the ids and timestamps
+  todo-cli import --email me@example.com --keep-ids --keep-timestamps todos.csv
+`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: validateConfig(&cfg.Database),
+		Run: func(cmd *cobra.Command, args []string) {
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					log.Fatalf("failed to open file: %s err: %s", args[0], err)
+				}
+				defer f.Close()
+				in = f
+			}
+			if format == "" {
+				format = strings.TrimPrefix(filepath.Ext(args[0]), ".")
+				if strings.EqualFold(format, "txt") {
+					format = string(transfer.TodoTxt)
+				}
+			}
+			r, err := transfer.NewReader(transfer.Format(strings.ToLower(format)), in)
+			if err != nil {
+				log.Fatalf("failed to import err: %s", err)
+			}
+			res, err := transfer.Import(todoService(email), r, opts)
+			for _, rejected := range res.Rejected {
+				fmt.Fprintf(cmd.ErrOrStderr(), "rejected %s\n", rejected)
+			}
+			if err != nil {
+				log.Fatalf("failed to import after %d todos err: %s", res.Imported, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d todos, rejected %d.\n", res.Imported, len(res.Rejected))
+		},
+	}
+	importCmd.Flags().StringVar(&email, "email", "", "email of the user owning the todos")
+	importCmd.Flags().StringVar(&format, "format", "", "Format of the file. One of: json|csv|ics|todotxt, guessed from the file extension by default")
+	importCmd.Flags().BoolVar(&opts.KeepIDs, "keep-ids", false, "keep the ids of the file instead of assigning new ones")
+	importCmd.Flags().BoolVar(&opts.KeepTimestamps, "keep-timestamps", false, "keep the creation and update times of the file")
+	_ = importCmd.MarkFlagRequired("email")
+	return &importCmd
+}
+
+// todoService returns the todo service of the user with the given email.
+func todoService(email string) service.Todo {
+	dbInstance, err := db.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to open %s database err: %s", cfg.Database.Driver, err)
+	}
+	user, err := repository.NewUser(dbInstance).FindByEmail(email)
+	if err != nil {
+		log.Fatalf("failed to find user: %s err: %s", email, err)
+	}
+	return service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance)).ForOwner(user.ID)
+}
@@ -2,16 +2,50 @@
 package db
 
 import (
+	"fmt"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-// New creates a new database connection
-func New(filename string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(filename), &gorm.Config{})
+// The supported database drivers.
+const (
+	SQLite   = "sqlite"
+	Postgres = "postgres"
+)
+
+// New creates a new database connection with the driver of the configuration
+func New(cfg model.Database) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case SQLite:
+		dialector = sqlite.Open(cfg.DSN)
+	case Postgres:
+		dialector = postgres.Open(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %q", cfg.Driver)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// Zero keeps the defaults of database/sql.
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
 	return db, nil
 }
 
@@ -23,3 +57,8 @@ func NewMemory() (*gorm.DB, error) {
 	}
 	return db, nil
 }
+
+// IsPostgres reports whether the connection is to a Postgres database.
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == Postgres
+}
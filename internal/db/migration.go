@@ -1,13 +1,349 @@
 package db
 
 import (
-	"github.com/fardinabir/todo-manager-app/internal/model"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-// Migrate runs the auto-migration for the database
+// MigrationsTable records the versions of the applied migrations.
+const MigrationsTable = "schema_migrations"
+
+// migrationsSchema creates the MigrationsTable, in SQL common to every driver.
+const migrationsSchema = `CREATE TABLE IF NOT EXISTS schema_migrations (version integer PRIMARY KEY, name text NOT NULL, applied_at timestamp NOT NULL)`
+
+// migrationFiles has the migrations of every driver, named <version>_<name>.<up|down>.sql in the directory of the driver.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationFile matches the name of a migration file.
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// autoMigrated is the table, or the column of a table, each migration added to the schema formerly created by
+// AutoMigrate. A database without the MigrationsTable is at the latest version whose addition it has.
+var autoMigrated = []struct {
+	version int
+	table   string
+	column  string
+}{
+	{version: 1, table: "todos"},
+	{version: 2, table: "todos", column: "due_at"},
+	{version: 3, table: "series"},
+	{version: 4, table: "todos", column: "parent_id"},
+	{version: 5, table: "tags"},
+	{version: 6, table: "projects"},
+	{version: 7, table: "users"},
+	{version: 8, table: "api_keys"},
+	{version: 9, table: "todos", column: "deleted_at"},
+	{version: 10, table: "revisions"},
+	{version: 11, table: "todos", column: "version"},
+	{version: 12, table: "webhooks"},
+	{version: 13, table: "tags", column: "user_id"},
+}
+
+// Migration is a numbered change of the schema with the SQL applying and reverting it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration with when it was applied, nil while pending. It is the zero time for the
+// migrations found applied to a database created before them, until the database is migrated.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns the migrations of the driver in the order of their versions.
+func Migrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database driver: %q", driver)
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		sql, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.Up = string(sql)
+		} else {
+			m.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts the migrations of a database, recording them in the MigrationsTable.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	// dryRun receives the SQL instead of the database when set.
+	dryRun io.Writer
+}
+
+// NewMigrator returns the migrator of the database. With dryRun set, the SQL the migrations would run
+// is written to it and the database is left untouched.
+func NewMigrator(db *gorm.DB, dryRun io.Writer) (*Migrator, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, dryRun: dryRun}, nil
+}
+
+// Status returns every migration with when it was applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		res[i] = MigrationStatus{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			res[i].AppliedAt = &at
+		}
+	}
+	return res, nil
+}
+
+// Version returns the latest applied version, 0 when none is.
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Up applies the pending migrations and returns them.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.Goto(m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverts the last n applied migrations and returns them.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if n > len(applied) {
+		return nil, fmt.Errorf("cannot revert %d migrations, %d are applied", n, len(applied))
+	}
+	var reverted []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < n; i-- {
+		if _, ok := applied[m.migrations[i].Version]; ok {
+			reverted = append(reverted, m.migrations[i])
+		}
+	}
+	if len(reverted) < n {
+		return nil, fmt.Errorf("cannot revert %d migrations, %d of the applied ones are unknown", n, n-len(reverted))
+	}
+	return reverted, m.run(reverted, false)
+}
+
+// Goto applies or reverts the migrations to bring the schema to the given version, 0 reverting them all.
+// It returns the migrations applied or reverted.
+func (m *Migrator) Goto(version int) ([]Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("unknown migration version: %d", version)
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var up, down []Migration
+	for _, migration := range m.migrations {
+		_, ok := applied[migration.Version]
+		if !ok && migration.Version <= version {
+			up = append(up, migration)
+		}
+		if ok && migration.Version > version {
+			down = append([]Migration{migration}, down...)
+		}
+	}
+	if err := m.run(down, false); err != nil {
+		return nil, err
+	}
+	if err := m.run(up, true); err != nil {
+		return nil, err
+	}
+	return append(down, up...), nil
+}
+
+// run applies or reverts the migrations in order, each one in a transaction with its record.
+func (m *Migrator) run(migrations []Migration, up bool) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+	if m.dryRun == nil {
+		if err := m.createTable(); err != nil {
+			return err
+		}
+	}
+	for _, migration := range migrations {
+		sql, record := migration.Down, fmt.Sprintf("DELETE FROM %s WHERE version = %d", MigrationsTable, migration.Version)
+		if up {
+			sql, record = migration.Up, recordSQL(migration)
+		}
+
+		if m.dryRun != nil {
+			if _, err := fmt.Fprintf(m.dryRun, "-- %04d_%s\n%s\n%s;\n\n", migration.Version, migration.Name, sql, record); err != nil {
+				return err
+			}
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(sql).Error; err != nil {
+				return err
+			}
+			return tx.Exec(record).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// createTable creates the MigrationsTable, recording the migrations already applied to a database created
+// before them.
+func (m *Migrator) createTable() error {
+	if m.db.Migrator().HasTable(MigrationsTable) {
+		return nil
+	}
+	version := m.autoMigratedVersion()
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migrationsSchema).Error; err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := tx.Exec(recordSQL(migration)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// autoMigratedVersion returns the version of the schema of a database created by AutoMigrate, 0 for an empty one.
+func (m *Migrator) autoMigratedVersion() int {
+	version := 0
+	for _, added := range autoMigrated {
+		if !m.db.Migrator().HasTable(added.table) || (added.column != "" && !m.db.Migrator().HasColumn(added.table, added.column)) {
+			continue
+		}
+		version = added.version
+	}
+	return version
+}
+
+// applied returns when the applied migrations were applied by version.
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	res := map[int]time.Time{}
+	if !m.db.Migrator().HasTable(MigrationsTable) {
+		for v := 1; v <= m.autoMigratedVersion(); v++ {
+			res[v] = time.Time{}
+		}
+		return res, nil
+	}
+	var rows []struct {
+		Version   int
+		AppliedAt time.Time
+	}
+	if err := m.db.Table(MigrationsTable).Select("version, applied_at").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.Version] = row.AppliedAt
+	}
+	return res, nil
+}
+
+// recordSQL returns the statement recording the migration as applied.
+func recordSQL(migration Migration) string {
+	return fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (%d, '%s', CURRENT_TIMESTAMP)",
+		MigrationsTable, migration.Version, migration.Name)
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// Migrate applies the pending migrations and sets up the full-text search index
 func Migrate(db *gorm.DB) error {
-	if err := db.AutoMigrate(&model.Todo{}); err != nil {
+	m, err := NewMigrator(db, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := m.Up(); err != nil {
+		return err
+	}
+	return MigrateSearch(db)
+}
+
+// MigrateSearch sets up the full-text search index when the database supports it.
+func MigrateSearch(db *gorm.DB) error {
+	if IsPostgres(db) {
+		log.Warn("Full-text search is only indexed on SQLite, it falls back to LIKE matching on Postgres")
+		return nil
+	}
+	if !SearchSupported(db) {
+		log.Warn("SQLite was built without FTS5, full-text search falls back to LIKE matching")
+		return nil
+	}
+	if err := migrateSearch(db); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestDB returns an empty in-memory database of its own, unlike the shared one of NewMemory.
+func newTestDB(t *testing.T) *gorm.DB {
+	gdb, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := gdb.DB()
+		_ = sqlDB.Close()
+	})
+	return gdb
+}
+
+// models are the models of the schema.
+var models = []interface{}{&model.User{}, &model.APIKey{}, &model.Series{}, &model.Tag{}, &model.Project{}, &model.Todo{}, &model.Revision{},
+	&model.Webhook{}, &model.Delivery{}}
+
+func TestMigrations(t *testing.T) {
+	sqliteMigrations, err := Migrations(SQLite)
+	require.NoError(t, err)
+	postgresMigrations, err := Migrations(Postgres)
+	require.NoError(t, err)
+
+	require.Equal(t, len(sqliteMigrations), len(postgresMigrations), "every migration exists for both drivers")
+	for i, m := range sqliteMigrations {
+		assert.Equal(t, i+1, m.Version, "versions follow each other")
+		assert.Equal(t, m.Name, postgresMigrations[i].Name)
+	}
+
+	_, err = Migrations("mysql")
+	assert.Error(t, err)
+}
+
+// TestMigrations_MatchModels checks that the migrations create the columns of every model.
+func TestMigrations_MatchModels(t *testing.T) {
+	migrated := newTestDB(t)
+	m, err := NewMigrator(migrated, nil)
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+
+	autoMigrated := newTestDB(t)
+	require.NoError(t, autoMigrated.AutoMigrate(models...))
+	tables, err := autoMigrated.Migrator().GetTables()
+	require.NoError(t, err)
+
+	for _, table := range tables {
+		want, err := autoMigrated.Migrator().ColumnTypes(table)
+		require.NoError(t, err)
+		got, err := migrated.Migrator().ColumnTypes(table)
+		require.NoError(t, err, table)
+		// The columns added by later migrations come last, they are compared by name.
+		types := map[string]string{}
+		for _, column := range got {
+			types[column.Name()] = column.DatabaseTypeName()
+		}
+		assert.Len(t, got, len(want), table)
+		for _, column := range want {
+			assert.Equal(t, column.DatabaseTypeName(), types[column.Name()], table+"."+column.Name())
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	gdb := newTestDB(t)
+	m, err := NewMigrator(gdb, nil)
+	require.NoError(t, err)
+	latest := m.migrations[len(m.migrations)-1].Version
+
+	applied, err := m.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(m.migrations))
+	version, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+	assert.True(t, gdb.Migrator().HasTable(&model.Todo{}))
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt, s.Name)
+	}
+
+	applied, err = m.Up()
+	require.NoError(t, err)
+	assert.Empty(t, applied, "nothing is pending")
+
+	_, err = m.Down(latest + 1)
+	assert.Error(t, err)
+	reverted, err := m.Down(latest)
+	require.NoError(t, err)
+	assert.Len(t, reverted, latest)
+	assert.False(t, gdb.Migrator().HasTable(&model.Todo{}))
+	version, err = m.Version()
+	require.NoError(t, err)
+	assert.Zero(t, version)
+
+	_, err = m.Goto(latest + 1)
+	assert.Error(t, err)
+	migrated, err := m.Goto(latest)
+	require.NoError(t, err)
+	assert.Len(t, migrated, latest)
+	assert.True(t, gdb.Migrator().HasTable(&model.Todo{}))
+	reverted, err = m.Goto(0)
+	require.NoError(t, err)
+	assert.Len(t, reverted, latest)
+	assert.False(t, gdb.Migrator().HasTable(&model.Todo{}))
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	gdb := newTestDB(t)
+	var out bytes.Buffer
+	m, err := NewMigrator(gdb, &out)
+	require.NoError(t, err)
+
+	applied, err := m.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(m.migrations))
+	assert.Contains(t, out.String(), "-- 0001_init\n")
+	assert.Contains(t, out.String(), "CREATE TABLE IF NOT EXISTS `todos`")
+	assert.Contains(t, out.String(), "INSERT INTO schema_migrations (version, name, applied_at) VALUES (1, 'init', CURRENT_TIMESTAMP);")
+
+	assert.False(t, gdb.Migrator().HasTable(&model.Todo{}))
+	assert.False(t, gdb.Migrator().HasTable(MigrationsTable))
+}
+
+// baselineTodo is the todo of the first release, before the migrations.
+type baselineTodo struct {
+	ID        int `gorm:"primaryKey"`
+	Task      string
+	Status    model.Status
+	Priority  model.Priority
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (baselineTodo) TableName() string {
+	return "todos"
+}
+
+// TestMigrate_Baseline checks that a database of the first release is upgraded with its todos.
+func TestMigrate_Baseline(t *testing.T) {
+	gdb := newTestDB(t)
+	require.NoError(t, gdb.AutoMigrate(&baselineTodo{}))
+	require.NoError(t, gdb.Create(&baselineTodo{Task: "Kept", Status: model.Created, Priority: model.Low}).Error)
+
+	m, err := NewMigrator(gdb, nil)
+	require.NoError(t, err)
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt, "the baseline is found applied")
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	applied, err := m.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(m.migrations)-1)
+	var todo model.Todo
+	require.NoError(t, gdb.Take(&todo).Error)
+	assert.Equal(t, "Kept", todo.Task)
+	assert.Equal(t, 1, todo.Version)
+
+	reverted, err := m.Goto(1)
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(m.migrations)-1)
+	columns, err := gdb.Migrator().ColumnTypes("todos")
+	require.NoError(t, err)
+	assert.Len(t, columns, 6)
+	var n int64
+	require.NoError(t, gdb.Table("todos").Count(&n).Error)
+	assert.EqualValues(t, 1, n)
+}
+
+// TestMigrate_AutoMigrated checks that a database created by AutoMigrate adopts the migrations.
+func TestMigrate_AutoMigrated(t *testing.T) {
+	gdb := newTestDB(t)
+	require.NoError(t, gdb.AutoMigrate(models...))
+	require.NoError(t, gdb.Create(&model.Tag{Name: "kept"}).Error)
+
+	m, err := NewMigrator(gdb, nil)
+	require.NoError(t, err)
+	version, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, m.migrations[len(m.migrations)-1].Version, version, "every migration is found applied")
+
+	require.NoError(t, Migrate(gdb))
+	var n int64
+	require.NoError(t, gdb.Model(&model.Tag{}).Count(&n).Error)
+	assert.EqualValues(t, 1, n)
+}
+
+// TestMigrate_TagOwners checks that a tag shared by the todos of several users is split between them.
+func TestMigrate_TagOwners(t *testing.T) {
+	gdb := newTestDB(t)
+	m, err := NewMigrator(gdb, nil)
+	require.NoError(t, err)
+	_, err = m.Goto(12)
+	require.NoError(t, err)
+	require.NoError(t, gdb.Exec("INSERT INTO todos (id, task, user_id) VALUES (1, 'Alice', 1), (2, 'Bob', 2), (3, 'Bob too', 2)").Error)
+	require.NoError(t, gdb.Exec("INSERT INTO tags (id, name) VALUES (1, 'work'), (2, 'unused')").Error)
+	require.NoError(t, gdb.Exec("INSERT INTO todo_tags (todo_id, tag_id) VALUES (1, 1), (2, 1), (3, 1)").Error)
+
+	_, err = m.Goto(13)
+	require.NoError(t, err)
+	var owners []struct {
+		TodoID int
+		TagID  int
+		UserID int
+	}
+	require.NoError(t, gdb.Raw("SELECT todo_id, tag_id, tags.user_id FROM todo_tags JOIN tags ON tags.id = tag_id ORDER BY todo_id").
+		Scan(&owners).Error)
+	require.Len(t, owners, 3)
+	assert.Equal(t, 1, owners[0].TagID, "the first user keeps the tag")
+	assert.Equal(t, 1, owners[0].UserID)
+	assert.NotEqual(t, 1, owners[1].TagID)
+	assert.Equal(t, owners[1].TagID, owners[2].TagID, "the todos of a user share their copy")
+	assert.Equal(t, 2, owners[2].UserID)
+
+	_, err = m.Goto(12)
+	require.NoError(t, err)
+	var tags []string
+	require.NoError(t, gdb.Raw("SELECT name FROM tags ORDER BY name").Scan(&tags).Error)
+	assert.Equal(t, []string{"unused", "work"}, tags)
+	var tagIDs []int
+	require.NoError(t, gdb.Raw("SELECT tag_id FROM todo_tags").Scan(&tagIDs).Error)
+	assert.Equal(t, []int{1, 1, 1}, tagIDs)
+}
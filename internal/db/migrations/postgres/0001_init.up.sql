@@ -0,0 +1,2 @@
+-- The schema of the first release. Databases created by it have no schema_migrations and already have the table.
+CREATE TABLE IF NOT EXISTS "todos" ("id" bigserial,"task" text,"status" text,"priority" bigint,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
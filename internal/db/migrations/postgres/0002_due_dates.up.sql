@@ -0,0 +1,3 @@
+ALTER TABLE "todos" ADD COLUMN "due_at" timestamptz;
+ALTER TABLE "todos" ADD COLUMN "scheduled_for" timestamptz;
+CREATE INDEX "idx_todos_due_at" ON "todos" ("due_at");
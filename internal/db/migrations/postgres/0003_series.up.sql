@@ -0,0 +1,5 @@
+CREATE TABLE "series" ("id" bigserial,"task" text,"priority" bigint,"recurrence" text,"start" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+ALTER TABLE "todos" ADD COLUMN "series_id" bigint;
+ALTER TABLE "todos" ADD COLUMN "occurrence_at" timestamptz;
+ALTER TABLE "todos" ADD CONSTRAINT "fk_todos_series" FOREIGN KEY ("series_id") REFERENCES "series"("id");
+CREATE INDEX "idx_todos_series_id" ON "todos" ("series_id");
@@ -0,0 +1,4 @@
+CREATE TABLE "projects" ("id" bigserial,"name" text,"color" text,"archived" boolean,"position" bigint,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX "idx_projects_archived" ON "projects" ("archived");
+ALTER TABLE "todos" ADD COLUMN "project_id" bigint;
+CREATE INDEX "idx_todos_project_id" ON "todos" ("project_id");
@@ -0,0 +1,3 @@
+CREATE TABLE "api_keys" ("id" bigserial,"name" text,"prefix" text,"hash" text,"scope" text,"created_at" timestamptz,"last_used_at" timestamptz,"revoked_at" timestamptz,"user_id" bigint,PRIMARY KEY ("id"));
+CREATE INDEX "idx_api_keys_user_id" ON "api_keys" ("user_id");
+CREATE UNIQUE INDEX "idx_api_keys_hash" ON "api_keys" ("hash");
@@ -0,0 +1,2 @@
+CREATE TABLE "revisions" ("id" bigserial,"todo_id" bigint,"action" text,"actor_id" bigint,"created_at" timestamptz,"changes" text,"reverted_to" bigint,PRIMARY KEY ("id"));
+CREATE INDEX "idx_revisions_todo_id" ON "revisions" ("todo_id");
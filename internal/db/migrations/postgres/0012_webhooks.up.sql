@@ -0,0 +1,5 @@
+CREATE TABLE "webhooks" ("id" bigserial,"url" text,"secret" text,"events" text,"created_at" timestamptz,"user_id" bigint,PRIMARY KEY ("id"));
+CREATE INDEX "idx_webhooks_user_id" ON "webhooks" ("user_id");
+CREATE TABLE "deliveries" ("id" bigserial,"webhook_id" bigint,"event" text,"payload" text,"status" text,"attempts" bigint,"next_attempt_at" timestamptz,"last_attempt_at" timestamptz,"response_status" bigint,"last_error" text,"replay_of" bigint,"created_at" timestamptz,PRIMARY KEY ("id"));
+CREATE INDEX "idx_deliveries_next_attempt_at" ON "deliveries" ("next_attempt_at");
+CREATE INDEX "idx_deliveries_webhook_id" ON "deliveries" ("webhook_id");
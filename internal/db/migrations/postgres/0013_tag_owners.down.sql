@@ -0,0 +1,6 @@
+DROP INDEX "idx_tags_user_id_name";
+-- The copies of a tag are merged back into the first one.
+UPDATE "todo_tags" SET "tag_id" = (SELECT MIN("copies"."id") FROM "tags" JOIN "tags" AS "copies" ON "copies"."name" = "tags"."name" WHERE "tags"."id" = "todo_tags"."tag_id");
+DELETE FROM "tags" WHERE "id" NOT IN (SELECT MIN("id") FROM "tags" GROUP BY "name");
+ALTER TABLE "tags" DROP COLUMN "user_id";
+CREATE UNIQUE INDEX "idx_tags_name" ON "tags" ("name");
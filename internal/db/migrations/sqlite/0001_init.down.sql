@@ -0,0 +1,3 @@
+-- The full-text search index is created outside of the migrations on builds supporting FTS5.
+DROP TABLE IF EXISTS `todos_fts`;
+DROP TABLE `todos`;
@@ -0,0 +1,2 @@
+-- The schema of the first release. Databases created by it have no schema_migrations and already have the table.
+CREATE TABLE IF NOT EXISTS `todos` (`id` integer PRIMARY KEY AUTOINCREMENT,`task` text,`status` text,`priority` integer,`created_at` datetime,`updated_at` datetime);
@@ -0,0 +1,3 @@
+DROP INDEX `idx_todos_due_at`;
+ALTER TABLE `todos` DROP COLUMN `scheduled_for`;
+ALTER TABLE `todos` DROP COLUMN `due_at`;
@@ -0,0 +1,3 @@
+ALTER TABLE `todos` ADD COLUMN `due_at` datetime;
+ALTER TABLE `todos` ADD COLUMN `scheduled_for` datetime;
+CREATE INDEX `idx_todos_due_at` ON `todos`(`due_at`);
@@ -0,0 +1,4 @@
+DROP INDEX `idx_todos_series_id`;
+ALTER TABLE `todos` DROP COLUMN `occurrence_at`;
+ALTER TABLE `todos` DROP COLUMN `series_id`;
+DROP TABLE `series`;
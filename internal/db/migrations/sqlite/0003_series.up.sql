@@ -0,0 +1,4 @@
+CREATE TABLE `series` (`id` integer PRIMARY KEY AUTOINCREMENT,`task` text,`priority` integer,`recurrence` text,`start` datetime,`created_at` datetime,`updated_at` datetime);
+ALTER TABLE `todos` ADD COLUMN `series_id` integer;
+ALTER TABLE `todos` ADD COLUMN `occurrence_at` datetime;
+CREATE INDEX `idx_todos_series_id` ON `todos`(`series_id`);
@@ -0,0 +1,3 @@
+CREATE TABLE `tags` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text,`created_at` datetime,`updated_at` datetime);
+CREATE UNIQUE INDEX `idx_tags_name` ON `tags`(`name`);
+CREATE TABLE `todo_tags` (`todo_id` integer,`tag_id` integer,PRIMARY KEY (`todo_id`,`tag_id`),CONSTRAINT `fk_todo_tags_todo` FOREIGN KEY (`todo_id`) REFERENCES `todos`(`id`),CONSTRAINT `fk_todo_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`));
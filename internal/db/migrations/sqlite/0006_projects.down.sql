@@ -0,0 +1,3 @@
+DROP INDEX `idx_todos_project_id`;
+ALTER TABLE `todos` DROP COLUMN `project_id`;
+DROP TABLE `projects`;
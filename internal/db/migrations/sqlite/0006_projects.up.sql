@@ -0,0 +1,4 @@
+CREATE TABLE `projects` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text,`color` text,`archived` numeric,`position` integer,`created_at` datetime,`updated_at` datetime);
+CREATE INDEX `idx_projects_archived` ON `projects`(`archived`);
+ALTER TABLE `todos` ADD COLUMN `project_id` integer;
+CREATE INDEX `idx_todos_project_id` ON `todos`(`project_id`);
@@ -0,0 +1,5 @@
+DROP INDEX `idx_projects_user_id`;
+ALTER TABLE `projects` DROP COLUMN `user_id`;
+DROP INDEX `idx_todos_user_id`;
+ALTER TABLE `todos` DROP COLUMN `user_id`;
+DROP TABLE `users`;
@@ -0,0 +1,6 @@
+CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`email` text,`password_hash` text,`created_at` datetime,`updated_at` datetime);
+CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`);
+ALTER TABLE `todos` ADD COLUMN `user_id` integer;
+CREATE INDEX `idx_todos_user_id` ON `todos`(`user_id`);
+ALTER TABLE `projects` ADD COLUMN `user_id` integer;
+CREATE INDEX `idx_projects_user_id` ON `projects`(`user_id`);
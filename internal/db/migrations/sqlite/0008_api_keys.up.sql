@@ -0,0 +1,3 @@
+CREATE TABLE `api_keys` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text,`prefix` text,`hash` text,`scope` text,`created_at` datetime,`last_used_at` datetime,`revoked_at` datetime,`user_id` integer);
+CREATE INDEX `idx_api_keys_user_id` ON `api_keys`(`user_id`);
+CREATE UNIQUE INDEX `idx_api_keys_hash` ON `api_keys`(`hash`);
@@ -0,0 +1,2 @@
+CREATE TABLE `revisions` (`id` integer PRIMARY KEY AUTOINCREMENT,`todo_id` integer,`action` text,`actor_id` integer,`created_at` datetime,`changes` text,`reverted_to` integer);
+CREATE INDEX `idx_revisions_todo_id` ON `revisions`(`todo_id`);
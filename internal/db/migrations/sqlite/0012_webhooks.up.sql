@@ -0,0 +1,5 @@
+CREATE TABLE `webhooks` (`id` integer PRIMARY KEY AUTOINCREMENT,`url` text,`secret` text,`events` text,`created_at` datetime,`user_id` integer);
+CREATE INDEX `idx_webhooks_user_id` ON `webhooks`(`user_id`);
+CREATE TABLE `deliveries` (`id` integer PRIMARY KEY AUTOINCREMENT,`webhook_id` integer,`event` text,`payload` text,`status` text,`attempts` integer,`next_attempt_at` datetime,`last_attempt_at` datetime,`response_status` integer,`last_error` text,`replay_of` integer,`created_at` datetime);
+CREATE INDEX `idx_deliveries_next_attempt_at` ON `deliveries`(`next_attempt_at`);
+CREATE INDEX `idx_deliveries_webhook_id` ON `deliveries`(`webhook_id`);
@@ -0,0 +1,7 @@
+ALTER TABLE `tags` ADD COLUMN `user_id` integer;
+DROP INDEX `idx_tags_name`;
+-- A tag shared by the todos of several users goes to the first of them, the others get a copy.
+UPDATE `tags` SET `user_id` = (SELECT MIN(`todos`.`user_id`) FROM `todo_tags` JOIN `todos` ON `todos`.`id` = `todo_tags`.`todo_id` WHERE `todo_tags`.`tag_id` = `tags`.`id`);
+INSERT INTO `tags` (`name`,`user_id`,`created_at`,`updated_at`) SELECT DISTINCT `tags`.`name`,`todos`.`user_id`,`tags`.`created_at`,`tags`.`updated_at` FROM `todo_tags` JOIN `tags` ON `tags`.`id` = `todo_tags`.`tag_id` JOIN `todos` ON `todos`.`id` = `todo_tags`.`todo_id` WHERE `todos`.`user_id` <> `tags`.`user_id`;
+UPDATE `todo_tags` SET `tag_id` = (SELECT `copies`.`id` FROM `tags` JOIN `tags` AS `copies` ON `copies`.`name` = `tags`.`name` JOIN `todos` ON `todos`.`user_id` = `copies`.`user_id` WHERE `tags`.`id` = `todo_tags`.`tag_id` AND `todos`.`id` = `todo_tags`.`todo_id`) WHERE EXISTS (SELECT 1 FROM `tags` JOIN `todos` ON `todos`.`user_id` <> `tags`.`user_id` WHERE `tags`.`id` = `todo_tags`.`tag_id` AND `todos`.`id` = `todo_tags`.`todo_id`);
+CREATE UNIQUE INDEX `idx_tags_user_id_name` ON `tags`(`user_id`,`name`);
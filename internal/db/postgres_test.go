@@ -0,0 +1,97 @@
+//go:build postgres
+
+package db_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// postgresPort is the port of the ephemeral Postgres started by the tests.
+const postgresPort = 54329
+
+// newPostgres starts an ephemeral Postgres server and returns the migrated database on it.
+// The binaries are downloaded on the first run and cached in ~/.embedded-postgres-go.
+func newPostgres(t *testing.T) *gorm.DB {
+	server := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(postgresPort).
+		RuntimePath(t.TempDir()))
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { _ = server.Stop() })
+
+	gdb, err := db.New(model.Database{
+		Driver:       db.Postgres,
+		DSN:          fmt.Sprintf("host=localhost port=%d user=postgres password=postgres dbname=postgres sslmode=disable", postgresPort),
+		MaxOpenConns: 4,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(gdb))
+	t.Cleanup(func() {
+		sqlDB, _ := gdb.DB()
+		_ = sqlDB.Close()
+	})
+	return gdb
+}
+
+func TestPostgres(t *testing.T) {
+	gdb := newPostgres(t)
+	assert.True(t, db.IsPostgres(gdb))
+	assert.False(t, db.SearchSupported(gdb))
+
+	user := &model.User{Email: "postgres@example.com"}
+	require.NoError(t, repository.NewUser(gdb).Create(user))
+	assert.Equal(t, model.ErrDuplicate, repository.NewUser(gdb).Create(&model.User{Email: "postgres@example.com"}))
+	todos := repository.NewTodo(gdb).ForOwner(user.ID)
+
+	for _, task := range []string{"Buy MILK", "buy bread", "Call mom"} {
+		require.NoError(t, todos.Create(&model.Todo{Task: task, Status: model.Created, Priority: model.Medium}))
+	}
+
+	t.Run("Case-insensitive search", func(t *testing.T) {
+		for _, key := range []string{"q", "task"} {
+			page, err := todos.FindAll(map[string]interface{}{key: "BUY"}, model.NewPageRequest(10, "", true, nil))
+			require.NoError(t, err)
+			assert.Len(t, page.Todos, 2, key)
+			assert.EqualValues(t, 2, *page.Total, key)
+		}
+	})
+
+	t.Run("Imported ids", func(t *testing.T) {
+		err := todos.Transaction(func(r repository.Todo) error {
+			require.NoError(t, r.Create(&model.Todo{ID: 100, Task: "Imported", Status: model.Done, Priority: model.Low}))
+			// The collision doesn't abort the transaction of the import.
+			assert.Equal(t, model.ErrDuplicate, r.Create(&model.Todo{ID: 100, Task: "Duplicate", Status: model.Done, Priority: model.Low}))
+			return r.Create(&model.Todo{ID: 101, Task: "Imported too", Status: model.Done, Priority: model.Low})
+		})
+		require.NoError(t, err)
+
+		next := &model.Todo{Task: "Created after the import", Status: model.Created, Priority: model.Low}
+		require.NoError(t, todos.Create(next))
+		assert.Greater(t, next.ID, 101)
+	})
+
+	t.Run("Migrations", func(t *testing.T) {
+		m, err := db.NewMigrator(gdb, nil)
+		require.NoError(t, err)
+		_, err = m.Goto(0)
+		require.NoError(t, err)
+		assert.False(t, gdb.Migrator().HasTable(&model.Todo{}))
+
+		_, err = m.Up()
+		require.NoError(t, err)
+		assert.True(t, gdb.Migrator().HasTable(&model.Todo{}))
+		statuses, err := m.Status()
+		require.NoError(t, err)
+		for _, s := range statuses {
+			assert.NotNil(t, s.AppliedAt, s.Name)
+		}
+	})
+}
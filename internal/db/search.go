@@ -0,0 +1,60 @@
+package db
+
+import (
+	"gorm.io/gorm"
+)
+
+// SearchTable is the FTS5 virtual table indexing the task text of todos.
+const SearchTable = "todos_fts"
+
+// searchSchema creates the external content FTS5 table over todos and the
+// triggers keeping it in sync with inserts, updates and deletes.
+var searchSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS todos_fts USING fts5(task, content='todos', content_rowid='id')`,
+	`CREATE TRIGGER IF NOT EXISTS todos_fts_ai AFTER INSERT ON todos BEGIN
+		INSERT INTO todos_fts(rowid, task) VALUES (new.id, new.task);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS todos_fts_ad AFTER DELETE ON todos BEGIN
+		INSERT INTO todos_fts(todos_fts, rowid, task) VALUES ('delete', old.id, old.task);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS todos_fts_au AFTER UPDATE OF task ON todos BEGIN
+		INSERT INTO todos_fts(todos_fts, rowid, task) VALUES ('delete', old.id, old.task);
+		INSERT INTO todos_fts(rowid, task) VALUES (new.id, new.task);
+	END`,
+}
+
+// SearchSupported reports whether the SQLite library was compiled with FTS5,
+// which requires building with the sqlite_fts5 tag. Other databases have no FTS5.
+func SearchSupported(db *gorm.DB) bool {
+	if db.Dialector.Name() != SQLite {
+		return false
+	}
+	var used int
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&used).Error; err != nil {
+		return false
+	}
+	return used == 1
+}
+
+// SearchAvailable reports whether the full-text search index has been migrated.
+func SearchAvailable(db *gorm.DB) bool {
+	return db.Migrator().HasTable(SearchTable)
+}
+
+// RebuildSearchIndex repopulates the full-text search index from the todos table.
+func RebuildSearchIndex(db *gorm.DB) error {
+	return db.Exec("INSERT INTO todos_fts(todos_fts) VALUES ('rebuild')").Error
+}
+
+func migrateSearch(db *gorm.DB) error {
+	exists := SearchAvailable(db)
+	for _, stmt := range searchSchema {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	if exists {
+		return nil
+	}
+	return RebuildSearchIndex(db)
+}
@@ -10,4 +10,20 @@ const (
 	CodeNotFound = "NOT_FOUND"
 	// CodeBadRequest is a generic error message returned when the request is bad.
 	CodeBadRequest = "BAD_REQUEST"
+	// CodeConflict is a generic error message returned when the request conflicts with the current state of the resource.
+	CodeConflict = "CONFLICT"
+	// CodeUnauthorized is a generic error message returned when the request is not authenticated.
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeForbidden is a generic error message returned when the request is authenticated but not permitted.
+	CodeForbidden = "FORBIDDEN"
+	// CodePreconditionFailed is a generic error message returned when the resource no longer matches the version the request expects.
+	CodePreconditionFailed = "PRECONDITION_FAILED"
+	// CodeUnsupportedMediaType is a generic error message returned when the request body is not of an accepted media type.
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	// CodeMethodNotAllowed is a generic error message returned when the request method does not allow the operation.
+	CodeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+	// CodeFailedDependency is a generic error message returned when an operation was not applied because another one failed.
+	CodeFailedDependency = "FAILED_DEPENDENCY"
+	// CodeServiceUnavailable is a generic error message returned when the server is shutting down.
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
 )
@@ -0,0 +1,267 @@
+// Package event provides the fan-out of todo events to live subscribers.
+package event
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrClosed is the error for subscribing to a closed broker.
+var ErrClosed = fmt.Errorf("event broker closed")
+
+// readBatchSize is the number of events read from the source at once.
+const readBatchSize = 100
+
+// Source is where the broker reads the events from, in the order of their ids.
+type Source interface {
+	Events(afterID int, limit int) ([]*model.TodoEvent, error)
+	LastEventID() (int, error)
+}
+
+// Options is the options for the Broker.
+type Options struct {
+	// PollInterval is how often the source is read while there are subscribers.
+	PollInterval time.Duration
+	// BufferSize is the number of latest events kept to be replayed to resuming subscribers.
+	BufferSize int
+	// QueueSize is the number of events a subscriber can lag behind before it is dropped.
+	QueueSize int
+	// Lookback is the number of ids before the latest event read again on every poll. An id is taken when
+	// the change is saved but the event is only read once the transaction commits, so on Postgres a
+	// concurrent change can show up after a later id was read. Such an event is sent as long as its id is
+	// within Lookback of the latest one when it commits, later than that it is missed.
+	Lookback int
+}
+
+// Broker reads the events saved by any process and sends them to the subscribers of their owner.
+// The source is only read while there are subscribers. Every event is sent once, those committed late
+// are sent after events of higher ids, see Options.Lookback.
+type Broker struct {
+	source Source
+	opts   Options
+
+	mu sync.Mutex
+	// buffer holds the latest events, oldest first.
+	buffer []*model.TodoEvent
+	// last is the id of the latest event read, floor the id before which events are no longer buffered.
+	last, floor int
+	// seen holds the ids of the events read within the lookback of last.
+	seen    map[int]struct{}
+	started bool
+	polling bool
+	closed  bool
+	subs    map[*Subscription]struct{}
+	done    chan struct{}
+}
+
+// NewBroker returns a new instance of the broker reading from source.
+func NewBroker(source Source, opts Options) *Broker {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 500 * time.Millisecond
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1000
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 256
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = readBatchSize
+	}
+	return &Broker{
+		source: source,
+		opts:   opts,
+		seen:   make(map[int]struct{}),
+		subs:   make(map[*Subscription]struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Subscription receives the events of a user.
+type Subscription struct {
+	broker *Broker
+	userID int
+	events chan *model.TodoEvent
+	done   chan struct{}
+	once   sync.Once
+	// Reset tells that events after the requested one are no longer buffered, the subscriber
+	// has to read the current state again instead of resuming.
+	Reset bool
+}
+
+// Events returns the channel the events are sent to.
+func (s *Subscription) Events() <-chan *model.TodoEvent {
+	return s.events
+}
+
+// Done returns a channel closed when the subscription ends, because it was closed, fell too far
+// behind or the broker was closed.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.unsubscribe(s)
+}
+
+// Subscribe returns a subscription to the events of a user. When lastEventID is not nil, the buffered
+// events of the user whose ids follow it are sent first.
+func (b *Broker) Subscribe(userID int, lastEventID *int) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if !b.started {
+		last, err := b.source.LastEventID()
+		if err != nil {
+			return nil, err
+		}
+		// The events already committed within the lookback are not sent when read again.
+		committed, err := b.source.Events(b.lookbackFrom(last), b.opts.Lookback)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range committed {
+			if e.ID <= last {
+				b.seen[e.ID] = struct{}{}
+			}
+		}
+		b.last, b.floor, b.started = last, last, true
+	}
+
+	s := &Subscription{
+		broker: b,
+		userID: userID,
+		events: make(chan *model.TodoEvent, b.opts.QueueSize+b.opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+	if lastEventID != nil {
+		if *lastEventID < b.floor {
+			s.Reset = true
+		} else {
+			for _, e := range b.buffer {
+				if e.ID > *lastEventID && e.UserID == userID {
+					s.events <- e
+				}
+			}
+		}
+	}
+	b.subs[s] = struct{}{}
+	if !b.polling {
+		b.polling = true
+		go b.poll()
+	}
+	return s, nil
+}
+
+// Close ends every subscription and stops reading events.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.done)
+	for s := range b.subs {
+		b.unsubscribe(s)
+	}
+}
+
+// unsubscribe ends a subscription, b.mu being held.
+func (b *Broker) unsubscribe(s *Subscription) {
+	delete(b.subs, s)
+	s.once.Do(func() { close(s.done) })
+}
+
+// poll reads the events on every interval until there are no more subscribers or the broker is closed.
+func (b *Broker) poll() {
+	ticker := time.NewTicker(b.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+		b.mu.Lock()
+		if len(b.subs) == 0 {
+			b.polling = false
+			b.mu.Unlock()
+			return
+		}
+		from := b.lookbackFrom(b.last)
+		b.mu.Unlock()
+
+		for {
+			events, err := b.source.Events(from, readBatchSize)
+			if err != nil {
+				log.Error("failed to read the todo events err: ", err)
+				break
+			}
+			if len(events) > 0 {
+				from = events[len(events)-1].ID
+				b.publish(events)
+			}
+			if len(events) < readBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// lookbackFrom returns the id after which the events are read again to find those committed late.
+func (b *Broker) lookbackFrom(last int) int {
+	if last < b.opts.Lookback {
+		return 0
+	}
+	return last - b.opts.Lookback
+}
+
+// publish buffers the events not sent yet and sends them to the subscribers of their owner.
+// A subscriber whose queue is full is dropped, it can resume from the last event it received.
+func (b *Broker) publish(events []*model.TodoEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range events {
+		if _, ok := b.seen[e.ID]; ok {
+			continue
+		}
+		b.seen[e.ID] = struct{}{}
+		b.buffer = append(b.buffer, e)
+		if len(b.buffer) > b.opts.BufferSize {
+			if b.buffer[0].ID > b.floor {
+				b.floor = b.buffer[0].ID
+			}
+			b.buffer[0] = nil
+			b.buffer = b.buffer[1:]
+		}
+		if e.ID > b.last {
+			b.last = e.ID
+		}
+		for s := range b.subs {
+			if s.userID != e.UserID {
+				continue
+			}
+			select {
+			case s.events <- e:
+			default:
+				log.Warnf("dropping the event subscriber of user %d lagging behind", s.userID)
+				b.unsubscribe(s)
+			}
+		}
+	}
+	for id := range b.seen {
+		if id <= b.lookbackFrom(b.last) {
+			delete(b.seen, id)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package event
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource is a source of events appended by the tests.
+type fakeSource struct {
+	mu     sync.Mutex
+	events []*model.TodoEvent
+}
+
+func (f *fakeSource) add(userID int, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := 0; i < n; i++ {
+		f.events = append(f.events, &model.TodoEvent{ID: len(f.events) + 1, Type: model.TodoCreated, UserID: userID})
+	}
+}
+
+// commit appends an event of the given id, which can be lower than those of events appended before.
+func (f *fakeSource) commit(userID int, id int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, &model.TodoEvent{ID: id, Type: model.TodoCreated, UserID: userID})
+	sort.Slice(f.events, func(i, j int) bool { return f.events[i].ID < f.events[j].ID })
+}
+
+func (f *fakeSource) Events(afterID int, limit int) ([]*model.TodoEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res []*model.TodoEvent
+	for _, e := range f.events {
+		if e.ID > afterID && len(res) < limit {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeSource) LastEventID() (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.events) == 0 {
+		return 0, nil
+	}
+	return f.events[len(f.events)-1].ID, nil
+}
+
+func receive(t *testing.T, s *Subscription, n int) []int {
+	t.Helper()
+	var ids []int
+	for len(ids) < n {
+		select {
+		case e := <-s.Events():
+			ids = append(ids, e.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("received %v, expected %d events", ids, n)
+		}
+	}
+	return ids
+}
+
+func TestBroker(t *testing.T) {
+	source := &fakeSource{}
+	source.add(1, 2)
+	b := NewBroker(source, Options{PollInterval: time.Millisecond, BufferSize: 3, QueueSize: 2})
+
+	// Events saved before the first subscription are not sent.
+	alice, err := b.Subscribe(1, nil)
+	require.NoError(t, err)
+	bob, err := b.Subscribe(2, nil)
+	require.NoError(t, err)
+	source.add(1, 1)
+	source.add(2, 1)
+	assert.Equal(t, []int{3}, receive(t, alice, 1))
+	assert.Equal(t, []int{4}, receive(t, bob, 1))
+
+	// A subscriber resumes with the buffered events of its user.
+	resumed, err := b.Subscribe(1, intPtr(2))
+	require.NoError(t, err)
+	assert.False(t, resumed.Reset)
+	assert.Equal(t, []int{3}, receive(t, resumed, 1))
+	resumed.Close()
+	<-resumed.Done()
+
+	// Resuming from an event no longer buffered resets the subscriber.
+	reset, err := b.Subscribe(1, intPtr(1))
+	require.NoError(t, err)
+	assert.True(t, reset.Reset)
+	reset.Close()
+
+	// A subscriber lagging behind its queue, and the room for a replay, is dropped.
+	source.add(2, 6)
+	select {
+	case <-bob.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lagging subscriber was not dropped")
+	}
+	source.add(1, 1)
+	assert.Equal(t, []int{11}, receive(t, alice, 1))
+
+	// Events up to 8 were evicted from the buffer of three events.
+	reset, err = b.Subscribe(2, intPtr(7))
+	require.NoError(t, err)
+	assert.True(t, reset.Reset)
+	resumed, err = b.Subscribe(2, intPtr(8))
+	require.NoError(t, err)
+	assert.False(t, resumed.Reset)
+	assert.Equal(t, []int{9, 10}, receive(t, resumed, 2))
+
+	b.Close()
+	for _, s := range []*Subscription{alice, reset, resumed} {
+		select {
+		case <-s.Done():
+		case <-time.After(time.Second):
+			t.Fatal("subscription was not ended by closing the broker")
+		}
+	}
+	_, err = b.Subscribe(1, nil)
+	assert.ErrorIs(t, err, ErrClosed)
+	b.Close()
+}
+
+func TestBroker_StopsPollingWithoutSubscribers(t *testing.T) {
+	source := &fakeSource{}
+	b := NewBroker(source, Options{PollInterval: time.Millisecond})
+	s, err := b.Subscribe(1, nil)
+	require.NoError(t, err)
+	s.Close()
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return !b.polling
+	}, time.Second, time.Millisecond)
+
+	// Events saved meanwhile are read once subscribed again.
+	source.add(1, 1)
+	s, err = b.Subscribe(1, intPtr(0))
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, receive(t, s, 1))
+	b.Close()
+}
+
+func TestBroker_LateCommits(t *testing.T) {
+	source := &fakeSource{}
+	source.commit(1, 1)
+	source.commit(1, 3)
+	b := NewBroker(source, Options{PollInterval: time.Millisecond, Lookback: 5})
+	s, err := b.Subscribe(1, nil)
+	require.NoError(t, err)
+
+	// The event committed before subscribing isn't sent when the lookback reads it again.
+	source.commit(1, 4)
+	assert.Equal(t, []int{4}, receive(t, s, 1))
+	source.commit(1, 2)
+	assert.Equal(t, []int{2}, receive(t, s, 1), "an event committed after a later one is still sent")
+
+	// An event committed further behind than the lookback is missed.
+	source.commit(1, 12)
+	assert.Equal(t, []int{12}, receive(t, s, 1))
+	source.commit(1, 5)
+	source.commit(1, 13)
+	assert.Equal(t, []int{13}, receive(t, s, 1))
+	b.Close()
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHandler is the request handler for the API key endpoint.
+type APIKeyHandler interface {
+	Create(c echo.Context) error
+	Revoke(c echo.Context) error
+	FindAll(c echo.Context) error
+}
+
+type apiKeyHandler struct {
+	Handler
+	service service.APIKey
+}
+
+// NewAPIKey returns a new instance of the API key handler.
+func NewAPIKey(s service.APIKey) APIKeyHandler {
+	return &apiKeyHandler{service: s}
+}
+
+// CreateAPIKeyRequest is the request parameter for issuing a new API key
+type CreateAPIKeyRequest struct {
+	Name  string      `json:"name" validate:"required,max=100"`
+	Scope model.Scope `json:"scope" validate:"required,oneof=read write"`
+}
+
+// CreatedAPIKey is a newly issued API key, the only response carrying the key itself
+type CreatedAPIKey struct {
+	*model.APIKey
+	Key string
+}
+
+// @Summary		Issue a new API key
+// @Description	The key is only returned by this request, store it safely. Send it as "Authorization: Bearer <key>"; a read scope only allows GET requests.
+// @Description	API keys are managed with an access token, requests authenticated by an API key are forbidden.
+// @Tags			apikeys
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		CreateAPIKeyRequest	true	"json"
+// @Success		201		{object}	ResponseData{data=CreatedAPIKey}
+// @Failure		400		{object}	ResponseError
+// @Failure		403		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/apikeys [post]
+func (a *apiKeyHandler) Create(c echo.Context) error {
+	var req CreateAPIKeyRequest
+	if err := a.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	apiKey, key, err := a.service.ForOwner(currentUser(c)).Create(req.Name, req.Scope)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: CreatedAPIKey{APIKey: apiKey, Key: key}})
+}
+
+// APIKeyRequest is the request parameter for revoking an API key
+type APIKeyRequest struct {
+	ID int `param:"id" validate:"required"`
+}
+
+// @Summary	Revoke an API key
+// @Tags		apikeys
+// @Security	BearerAuth
+// @Param		path	path	APIKeyRequest	false	"path"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	403	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/apikeys/{id} [delete]
+func (a *apiKeyHandler) Revoke(c echo.Context) error {
+	var req APIKeyRequest
+	if err := a.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := a.service.ForOwner(currentUser(c)).Revoke(req.ID); err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "api key not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Find all API keys
+// @Tags		apikeys
+// @Security	BearerAuth
+// @Success	200	{object}	ResponseData{Data=[]model.APIKey}
+// @Failure	403	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/apikeys [get]
+func (a *apiKeyHandler) FindAll(c echo.Context) error {
+	res, err := a.service.ForOwner(currentUser(c)).FindAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.APIKey{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "apikeys@example.com")
+
+	call := func(credential, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+credential)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	issue := func(scope string) (int, string) {
+		rec := call(token, http.MethodPost, "/api/v1/apikeys", fmt.Sprintf(`{"name":"ci", "scope":%q}`, scope))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var res struct {
+			Data struct {
+				ID    int
+				Key   string
+				Scope model.Scope
+			}
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, model.Scope(scope), res.Data.Scope)
+		require.True(t, model.IsAPIKey(res.Data.Key))
+		return res.Data.ID, res.Data.Key
+	}
+
+	t.Run("invalid_scope", func(t *testing.T) {
+		rec := call(token, http.MethodPost, "/api/v1/apikeys", `{"name":"ci", "scope":"admin"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("read_scope", func(t *testing.T) {
+		_, key := issue("read")
+		assert.Equal(t, http.StatusOK, call(key, http.MethodGet, "/api/v1/todos", "").Code)
+		rec := call(key, http.MethodPost, "/api/v1/todos", `{"task":"From CI", "priority":1}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("write_scope", func(t *testing.T) {
+		_, key := issue("write")
+		rec := call(key, http.MethodPost, "/api/v1/todos", `{"task":"From CI", "priority":1}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		// The todo belongs to the owner of the key.
+		rec = call(token, http.MethodGet, "/api/v1/todos", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "From CI")
+	})
+
+	t.Run("list_records_last_use", func(t *testing.T) {
+		rec := call(token, http.MethodGet, "/api/v1/apikeys", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `"Key"`)
+		assert.NotContains(t, rec.Body.String(), `"Hash"`)
+		var res struct {
+			Data []model.APIKey
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res.Data, 2)
+		for _, k := range res.Data {
+			assert.NotNil(t, k.LastUsedAt)
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		id, key := issue("read")
+		target := fmt.Sprintf("/api/v1/apikeys/%d", id)
+		assert.Equal(t, http.StatusNoContent, call(token, http.MethodDelete, target, "").Code)
+		assert.Equal(t, http.StatusNotFound, call(token, http.MethodDelete, target, "").Code)
+		assert.Equal(t, http.StatusUnauthorized, call(key, http.MethodGet, "/api/v1/todos", "").Code)
+	})
+
+	t.Run("keys_of_other_users", func(t *testing.T) {
+		id, _ := issue("read")
+		other := login(t, e, "apikeys-other@example.com")
+		target := fmt.Sprintf("/api/v1/apikeys/%d", id)
+		assert.Equal(t, http.StatusNotFound, call(other, http.MethodDelete, target, "").Code)
+	})
+
+	t.Run("keys_cannot_manage_keys", func(t *testing.T) {
+		id, writeKey := issue("write")
+		_, readKey := issue("read")
+		for _, key := range []string{writeKey, readKey} {
+			assert.Equal(t, http.StatusForbidden, call(key, http.MethodGet, "/api/v1/apikeys", "").Code)
+			assert.Equal(t, http.StatusForbidden, call(key, http.MethodPost, "/api/v1/apikeys", `{"name":"escalated", "scope":"write"}`).Code)
+			assert.Equal(t, http.StatusForbidden, call(key, http.MethodDelete, fmt.Sprintf("/api/v1/apikeys/%d", id), "").Code)
+		}
+		// The key is still valid.
+		assert.Equal(t, http.StatusOK, call(writeKey, http.MethodGet, "/api/v1/todos", "").Code)
+	})
+
+	t.Run("unknown_key", func(t *testing.T) {
+		rec := call(model.APIKeyPrefix+"unknown", http.MethodGet, "/api/v1/todos", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("basic_auth", func(t *testing.T) {
+		_, key := issue("read")
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/todos.ics", nil)
+		req.SetBasicAuth("calendar", key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "BEGIN:VCALENDAR")
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/todos.ics", nil)
+		req.SetBasicAuth("calendar", model.APIKeyPrefix+"unknown")
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
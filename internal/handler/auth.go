@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// Context keys of the authenticated credential.
+const (
+	// userIDKey is the context key of the id of the authenticated user.
+	userIDKey = "userID"
+	// scopeKey is the context key of the scope of the credential.
+	scopeKey = "scope"
+	// apiKeyKey is the context key telling whether the credential is an API key rather than an access token.
+	apiKeyKey = "apiKey"
+)
+
+// AuthHandler is the request handler for the auth endpoint.
+type AuthHandler interface {
+	Register(c echo.Context) error
+	Login(c echo.Context) error
+}
+
+type authHandler struct {
+	Handler
+	service service.Auth
+}
+
+// NewAuth returns a new instance of the auth handler.
+func NewAuth(s service.Auth) AuthHandler {
+	return &authHandler{service: s}
+}
+
+// CredentialsRequest is the request parameter for registering and logging in
+type CredentialsRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// @Summary	Register a new user
+// @Tags		auth
+// @Accept		json
+// @Produce	json
+// @Param		request	body		CredentialsRequest	true	"json"
+// @Success	201		{object}	ResponseData{data=model.User}
+// @Failure	400		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/auth/register [post]
+func (a *authHandler) Register(c echo.Context) error {
+	var req CredentialsRequest
+	if err := a.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	user, err := a.service.Register(req.Email, req.Password)
+	if err != nil {
+		if err == model.ErrDuplicate {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "email already registered"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: user})
+}
+
+// @Summary		Log in
+// @Description	Returns an access token to send as "Authorization: Bearer <token>".
+// @Tags			auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		CredentialsRequest	true	"json"
+// @Success		200		{object}	ResponseData{data=model.AccessToken}
+// @Failure		400		{object}	ResponseError
+// @Failure		401		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/auth/login [post]
+func (a *authHandler) Login(c echo.Context) error {
+	var req CredentialsRequest
+	if err := a.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	token, err := a.service.Login(req.Email, req.Password)
+	if err != nil {
+		if err == model.ErrInvalidCredentials {
+			return c.JSON(http.StatusUnauthorized,
+				ResponseError{Errors: []Error{{Code: errors.CodeUnauthorized, Message: err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: token})
+}
+
+// Authenticate returns a middleware rejecting requests without a valid bearer access token or API key
+// and storing the id of the authenticated user and the scope of the credential in the context. Read-only API keys
+// are limited to safe methods.
+func Authenticate(credentials service.Credential) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scheme, credential, ok := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			if ok && strings.EqualFold(scheme, "Basic") {
+				// Clients such as calendar apps only support HTTP Basic, the password carries the credential.
+				_, credential, ok = c.Request().BasicAuth()
+			} else if !strings.EqualFold(scheme, "Bearer") {
+				ok = false
+			}
+			if !ok || credential == "" {
+				return unauthorized(c, model.ErrInvalidToken)
+			}
+
+			principal, err := credentials.Verify(credential)
+			if err != nil {
+				if err == model.ErrInvalidToken {
+					return unauthorized(c, err)
+				}
+				return c.JSON(http.StatusInternalServerError,
+					ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+			}
+			if !principal.Scope.Allows(c.Request().Method) {
+				return c.JSON(http.StatusForbidden,
+					ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: model.ErrInsufficientScope.Error()}}})
+			}
+			c.Set(userIDKey, principal.UserID)
+			c.Set(scopeKey, principal.Scope)
+			c.Set(apiKeyKey, principal.APIKey)
+			return next(c)
+		}
+	}
+}
+
+// RequireSession is a middleware running after Authenticate rejecting the requests authenticated by an API key,
+// so that a leaked key cannot be used to issue or revoke keys.
+func RequireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if isAPIKey, _ := c.Get(apiKeyKey).(bool); isAPIKey {
+			return c.JSON(http.StatusForbidden,
+				ResponseError{Errors: []Error{{Code: errors.CodeForbidden, Message: model.ErrSessionRequired.Error()}}})
+		}
+		return next(c)
+	}
+}
+
+func unauthorized(c echo.Context, err error) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return c.JSON(http.StatusUnauthorized,
+		ResponseError{Errors: []Error{{Code: errors.CodeUnauthorized, Message: err.Error()}}})
+}
+
+// currentUser returns the id of the authenticated user, zero outside of Authenticate.
+func currentUser(c echo.Context) int {
+	userID, _ := c.Get(userIDKey).(int)
+	return userID
+}
+
+// currentScope returns the scope of the credential of the request, empty outside of Authenticate.
+func currentScope(c echo.Context) model.Scope {
+	scope, _ := c.Get(scopeKey).(model.Scope)
+	return scope
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAuthConfig = model.Auth{SigningKey: "test-signing-key-of-at-least-32-bytes"}
+
+// login registers a user with the given email through the routes of e, if needed, and returns its access token.
+func login(t *testing.T, e *echo.Echo, email string) string {
+	body := fmt.Sprintf(`{"email":%q, "password":"correct horse"}`, email)
+	for _, target := range []string{"/api/v1/auth/register", "/api/v1/auth/login"} {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if target == "/api/v1/auth/login" {
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var res struct {
+				Data model.AccessToken
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			return res.Data.Token
+		}
+	}
+	return ""
+}
+
+func TestAuthHandler_Register(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.User{})
+	repository := repository.NewUser(dbInstance)
+	service := service.NewAuth(repository, testAuthConfig)
+	handler := NewAuth(service)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{"successful_register", `{"email":"Alice@Example.com", "password":"correct horse"}`, http.StatusCreated},
+		{"duplicate_email", `{"email":"alice@example.com", "password":"another one"}`, http.StatusConflict},
+		{"invalid_email", `{"email":"alice", "password":"correct horse"}`, http.StatusBadRequest},
+		{"short_password", `{"email":"bob@example.com", "password":"short"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler.Register(e.NewContext(req, rec)))
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			if rec.Code == http.StatusCreated {
+				assert.NotContains(t, rec.Body.String(), "Password")
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.User{})
+	repository := repository.NewUser(dbInstance)
+	authService := service.NewAuth(repository, testAuthConfig)
+	handler := NewAuth(authService)
+	user, err := authService.Register("carol@example.com", "correct horse")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{"successful_login", `{"email":"CAROL@example.com", "password":"correct horse"}`, http.StatusOK},
+		{"wrong_password", `{"email":"carol@example.com", "password":"wrong horse"}`, http.StatusUnauthorized},
+		{"unknown_email", `{"email":"dave@example.com", "password":"correct horse"}`, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler.Login(e.NewContext(req, rec)))
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data model.AccessToken
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			userID, err := authService.Verify(res.Data.Token)
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, userID)
+
+			// A token signed with another key is rejected.
+			otherKey := model.Auth{SigningKey: "another-signing-key-of-at-least-32-bytes"}
+			_, err = service.NewAuth(repository, otherKey).Verify(res.Data.Token)
+			assert.Equal(t, model.ErrInvalidToken, err)
+		})
+	}
+}
+
+func TestAuthenticate_ScopesTodosToOwner(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Project{}) })
+	Register(e, dbInstance, testAuthConfig)
+	alice := login(t, e, "owner-alice@example.com")
+	bob := login(t, e, "owner-bob@example.com")
+
+	call := func(token, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(alice, http.MethodPost, "/api/v1/todos", `{"task":"Alice's secret", "priority":1}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created struct {
+		Data model.Todo
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	target := fmt.Sprintf("/api/v1/todos/%d", created.Data.ID)
+
+	assert.Equal(t, http.StatusOK, call(alice, http.MethodGet, target, "").Code)
+	assert.Equal(t, http.StatusNotFound, call(bob, http.MethodGet, target, "").Code)
+	assert.Equal(t, http.StatusNotFound, call(bob, http.MethodPut, target, `{"task":"Stolen", "priority":1, "status":"done"}`).Code)
+	assert.Equal(t, http.StatusNotFound, call(bob, http.MethodDelete, target, "").Code)
+
+	rec = call(bob, http.MethodGet, "/api/v1/todos", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "Alice's secret")
+	rec = call(alice, http.MethodGet, "/api/v1/todos", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Alice's secret")
+}
+
+func TestUser_ClaimUnowned(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Tag{}, model.Project{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}, model.Project{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "claimer@example.com")
+	user, err := repository.NewUser(dbInstance).FindByEmail("claimer@example.com")
+	require.NoError(t, err)
+
+	// The user has a tag of the name of an unowned one.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tags", bytes.NewReader([]byte(`{"name":"work"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NoError(t, dbInstance.Exec("INSERT INTO todos (id, task, status, priority) VALUES (901, 'Before users', 'created', 1)").Error)
+	require.NoError(t, dbInstance.Exec("INSERT INTO tags (id, name) VALUES (901, 'work'), (902, 'home')").Error)
+	require.NoError(t, dbInstance.Exec("INSERT INTO todo_tags (todo_id, tag_id) VALUES (901, 901), (901, 902)").Error)
+	require.NoError(t, dbInstance.Exec("INSERT INTO projects (name, color, archived, position) VALUES ('Before users', '', false, 0)").Error)
+
+	claimed, err := repository.NewUser(dbInstance).ClaimUnowned(user.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, claimed)
+
+	todo, err := repository.NewTodo(dbInstance).ForOwner(user.ID).Find(901)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "work"}, todo.Tags.Names())
+	tags, err := repository.NewTag(dbInstance).ForOwner(user.ID).FindAll()
+	require.NoError(t, err)
+	assert.Len(t, tags, 2, "the unowned tag is merged into the one of the user")
+	projects, err := repository.NewProject(dbInstance).ForOwner(user.ID).FindAll(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Len(t, projects, 1)
+
+	claimed, err = repository.NewUser(dbInstance).ClaimUnowned(user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+}
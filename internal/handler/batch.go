@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// BatchMode selects what happens to a batch when one of its operations fails.
+type BatchMode string
+
+const (
+	// AtomicBatch applies all the operations of a batch or none of them.
+	AtomicBatch = BatchMode("atomic")
+	// BestEffortBatch applies the operations of a batch that succeed and skips the others.
+	BestEffortBatch = BatchMode("best_effort")
+)
+
+// Operations of a batch.
+const (
+	createOperation = "create"
+	updateOperation = "update"
+	patchOperation  = "patch"
+	deleteOperation = "delete"
+)
+
+// errBatchAborted rolls back an atomic batch after one of its operations failed.
+var errBatchAborted = fmt.Errorf("batch aborted")
+
+// BatchRequest is the request body for running several operations on todos at once
+type BatchRequest struct {
+	// Mode is atomic (default) to apply all operations or none, or best_effort to apply those that succeed.
+	Mode       BatchMode        `json:"mode,omitempty" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// BatchOperation is one operation of a batch
+type BatchOperation struct {
+	Op string `json:"op" validate:"required,oneof=create update patch delete"`
+	// ID is the todo an update, patch or delete applies to.
+	ID int `json:"id,omitempty" validate:"required_unless=Op create"`
+	// IfMatch is the ETag the todo must have for an update, patch or delete to apply.
+	IfMatch string `json:"if_match,omitempty"`
+	// DeleteMode selects what happens to the subtasks of a deleted todo, as the mode query parameter of DELETE /todos/{id}.
+	DeleteMode model.DeleteMode `json:"delete_mode,omitempty" validate:"omitempty,oneof=refuse cascade reparent"`
+	// Body is the body of the single request: a CreateRequest, an UpdateRequestBody or a merge patch.
+	Body json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// BatchResponse is the outcome of a batch
+type BatchResponse struct {
+	// Committed tells whether the changes of the batch were saved.
+	Committed bool
+	// Results are the outcomes of the operations, in the order of the request.
+	Results []BatchResult
+}
+
+// BatchResult is the outcome of one operation of a batch
+type BatchResult struct {
+	// Status is the HTTP status of the operation sent as a single request.
+	Status int
+	Data   *model.Todo `json:"Data,omitempty"`
+	Errors []Error     `json:"Errors,omitempty"`
+}
+
+// @Summary		Run several operations on todos at once
+// @Description	The operations run in order in a single transaction. In atomic mode (default) the first failing operation rolls back the whole batch and the response has its status; the other operations report 424.
+// @Description	In best_effort mode every failing operation is rolled back on its own and the others are saved.
+// @Tags			todos
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			body	body		BatchRequest	true	"body"
+// @Success		200		{object}	ResponseData{Data=BatchResponse}
+// @Failure		400		{object}	ResponseData{Data=BatchResponse}
+// @Failure		404		{object}	ResponseData{Data=BatchResponse}
+// @Failure		409		{object}	ResponseData{Data=BatchResponse}
+// @Failure		412		{object}	ResponseData{Data=BatchResponse}
+// @Failure		500		{object}	ResponseError
+// @Router			/todos:batch [post]
+func (t *todoHandler) Batch(c echo.Context) error {
+	var req BatchRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res := BatchResponse{Results: make([]BatchResult, len(req.Operations))}
+	failed := -1
+	err := t.service.ForOwner(currentUser(c)).Transaction(func(s service.Todo) error {
+		for i, op := range req.Operations {
+			res.Results[i] = runOperation(c, s, op)
+			if res.Results[i].Status >= http.StatusBadRequest && failed < 0 {
+				failed = i
+				if req.Mode != BestEffortBatch {
+					return errBatchAborted
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errBatchAborted {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	if err == errBatchAborted {
+		for i := range res.Results {
+			if i == failed {
+				continue
+			}
+			message := "rolled back because operation %d failed"
+			if i > failed {
+				message = "not run because operation %d failed"
+			}
+			res.Results[i] = BatchResult{
+				Status: http.StatusFailedDependency,
+				Errors: []Error{{Code: errors.CodeFailedDependency, Message: fmt.Sprintf(message, failed)}},
+			}
+		}
+		return c.JSON(res.Results[failed].Status, ResponseData{Data: res})
+	}
+	res.Committed = true
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// runOperation runs an operation of a batch with the given service.
+func runOperation(c echo.Context, s service.Todo, op BatchOperation) BatchResult {
+	precondition, err := parsePrecondition(op.IfMatch)
+	if err != nil {
+		return batchError(echo.NewHTTPError(http.StatusBadRequest, err.Error()))
+	}
+
+	switch op.Op {
+	case createOperation:
+		var req CreateRequest
+		if err := decodeOperation(c, op, &req); err != nil {
+			return batchError(err)
+		}
+		todo, err := s.Create(req.Task, req.Priority, req.Details())
+		if err != nil {
+			return batchError(err)
+		}
+		return BatchResult{Status: http.StatusCreated, Data: todo}
+	case updateOperation:
+		var req UpdateRequestBody
+		if err := decodeOperation(c, op, &req); err != nil {
+			return batchError(err)
+		}
+		opts := req.Options()
+		opts.IfMatch = precondition
+		todo, err := s.Update(op.ID, req.Task, req.Priority, req.Status, req.Details(), opts)
+		if err != nil {
+			return batchError(err)
+		}
+		return BatchResult{Status: http.StatusOK, Data: todo}
+	case patchOperation:
+		var patch map[string]interface{}
+		if err := json.Unmarshal(op.Body, &patch); err != nil || patch == nil {
+			return batchError(echo.NewHTTPError(http.StatusBadRequest, "the merge patch must be a JSON object"))
+		}
+		todo, err := ApplyPatch(c.Validate, s, op.ID, patch, precondition)
+		if err != nil {
+			return batchError(err)
+		}
+		return BatchResult{Status: http.StatusOK, Data: todo}
+	default: // deleteOperation
+		if err := s.Delete(op.ID, op.DeleteMode, precondition); err != nil {
+			return batchError(err)
+		}
+		return BatchResult{Status: http.StatusNoContent}
+	}
+}
+
+// decodeOperation decodes and validates the body of an operation.
+func decodeOperation(c echo.Context, op BatchOperation, req interface{}) error {
+	if err := json.Unmarshal(op.Body, req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// batchError returns the result of an operation that failed.
+func batchError(err error) BatchResult {
+	status, e := todoError(err)
+	return BatchResult{Status: status, Errors: []Error{e}}
+}
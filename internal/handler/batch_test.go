@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHandler_Batch(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Tag{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}) })
+	handler := NewTodo(service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance)))
+
+	call := func(t *testing.T, body string) (int, BatchResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/todos:batch", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		require.NoError(t, handler.Batch(c))
+		var res struct {
+			Data BatchResponse
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res.Data
+	}
+	task := func(t *testing.T, id int) (string, int) {
+		req := httptest.NewRequest(http.MethodGet, "/todos/"+strconv.Itoa(id), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/todos/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.Itoa(id))
+		require.NoError(t, handler.Find(c))
+		if rec.Code != http.StatusOK {
+			return "", rec.Code
+		}
+		var res struct {
+			Data model.Todo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Data.Task, rec.Code
+	}
+	statuses := func(res BatchResponse) []int {
+		var s []int
+		for _, r := range res.Results {
+			s = append(s, r.Status)
+		}
+		return s
+	}
+
+	t.Run("atomic", func(t *testing.T) {
+		updated := createTask(t, e, handler, `{"task":"To update", "priority":1}`)
+		patched := createTask(t, e, handler, `{"task":"To patch", "priority":1}`)
+		deleted := createTask(t, e, handler, `{"task":"To delete", "priority":1}`)
+		code, res := call(t, `{"operations":[
+			{"op":"create", "body":{"task":"Created", "priority":2}},
+			{"op":"update", "id":`+strconv.Itoa(updated)+`, "if_match":"\"1\"", "body":{"task":"Updated", "priority":1, "status":"processing"}},
+			{"op":"patch", "id":`+strconv.Itoa(patched)+`, "body":{"task":"Patched"}},
+			{"op":"delete", "id":`+strconv.Itoa(deleted)+`}
+		]}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, res.Committed)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusOK, http.StatusOK, http.StatusNoContent}, statuses(res))
+		require.NotNil(t, res.Results[0].Data)
+		assert.Equal(t, "Created", res.Results[0].Data.Task)
+
+		name, _ := task(t, updated)
+		assert.Equal(t, "Updated", name)
+		name, _ = task(t, patched)
+		assert.Equal(t, "Patched", name)
+		_, status := task(t, deleted)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("atomic_rollback", func(t *testing.T) {
+		id := createTask(t, e, handler, `{"task":"Kept", "priority":1}`)
+		code, res := call(t, `{"operations":[
+			{"op":"patch", "id":`+strconv.Itoa(id)+`, "body":{"task":"Rolled back"}},
+			{"op":"update", "id":`+strconv.Itoa(id)+`, "if_match":"\"1\"", "body":{"task":"Stale", "priority":1, "status":"created"}},
+			{"op":"create", "body":{"task":"Not created", "priority":1}}
+		]}`)
+		require.Equal(t, http.StatusPreconditionFailed, code)
+		assert.False(t, res.Committed)
+		assert.Equal(t, []int{http.StatusFailedDependency, http.StatusPreconditionFailed, http.StatusFailedDependency}, statuses(res))
+		assert.Nil(t, res.Results[0].Data)
+
+		name, _ := task(t, id)
+		assert.Equal(t, "Kept", name)
+		var count int64
+		require.NoError(t, dbInstance.Model(&model.Todo{}).Where("task = ?", "Not created").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("best_effort", func(t *testing.T) {
+		id := createTask(t, e, handler, `{"task":"Best effort", "priority":1}`)
+		code, res := call(t, `{"mode":"best_effort", "operations":[
+			{"op":"patch", "id":`+strconv.Itoa(id)+`, "body":{"task":"Saved"}},
+			{"op":"delete", "id":999999},
+			{"op":"create", "body":{"task":"", "priority":1}},
+			{"op":"create", "body":{"task":"Also saved", "priority":1}}
+		]}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, res.Committed)
+		assert.Equal(t, []int{http.StatusOK, http.StatusNotFound, http.StatusBadRequest, http.StatusCreated}, statuses(res))
+
+		name, _ := task(t, id)
+		assert.Equal(t, "Saved", name)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, body := range []string{
+			`{}`,
+			`{"operations":[]}`,
+			`{"mode":"sometimes", "operations":[{"op":"create", "body":{"task":"Task", "priority":1}}]}`,
+			`{"operations":[{"op":"move", "id":1}]}`,
+			`{"operations":[{"op":"delete"}]}`,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/todos:batch", bytes.NewReader([]byte(body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler.Batch(e.NewContext(req, rec)))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/fardinabir/todo-manager-app/internal/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// calendarPageLimit is the page size used to collect the todos of the calendar feed.
+const calendarPageLimit = 200
+
+// ImportResponse is the outcome of an import
+type ImportResponse struct {
+	// Imported is the number of todos created.
+	Imported int
+	// Rejected are the records that were not imported.
+	Rejected []RejectedRecord
+}
+
+// RejectedRecord is a record of an imported file that was not imported
+type RejectedRecord struct {
+	// Row is the position of the record in the file, starting at 1.
+	Row     int
+	Message string
+}
+
+// NewImportResponse returns the response of an import.
+func NewImportResponse(res *transfer.ImportResult) ImportResponse {
+	rejected := make([]RejectedRecord, 0, len(res.Rejected))
+	for _, r := range res.Rejected {
+		rejected = append(rejected, RejectedRecord{Row: r.Row, Message: r.Err.Error()})
+	}
+	return ImportResponse{Imported: res.Imported, Rejected: rejected}
+}
+
+// @Summary		Subscribe to the todos as a calendar
+// @Description	Renders every todo matching the filters as an RFC 5545 VTODO component, for calendar clients. Calendar clients unable to send a Bearer token can authenticate with HTTP Basic, using an API key as the password.
+// @Tags			todos
+// @Security		BearerAuth
+// @Produce		text/calendar
+// @Param			q			query		string	false	"Full-text search on task text"
+// @Param			task		query		string	false	"Filter by task name"
+// @Param			status		query		string	false	"Filter by task status"
+// @Param			sort		query		string	false	"Comma separated sort fields, prefixed with - for descending order"
+// @Param			due_before	query		string	false	"Only todos due before this RFC 3339 time"
+// @Param			due_after	query		string	false	"Only todos due after this RFC 3339 time"
+// @Param			overdue		query		bool	false	"Only overdue (true) or not overdue (false) todos"
+// @Param			tag			query		string	false	"Only todos carrying all of these comma separated tags"
+// @Param			tag_any		query		string	false	"Only todos carrying at least one of these comma separated tags"
+// @Param			tag_none	query		string	false	"Only todos carrying none of these comma separated tags"
+// @Param			project_id	query		int		false	"Only todos of this project. Without it, todos of archived projects are hidden"
+// @Success		200			{string}	string	"iCalendar file"
+// @Failure		400			{object}	ResponseError
+// @Failure		500			{object}	ResponseError
+// @Router			/todos.ics [get]
+func (t *todoHandler) Calendar(c echo.Context) error {
+	var req FindAllRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	sort, err := model.ParseSort(req.Sort)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	// Calendar clients do not paginate, every page is collected before responding.
+	s := t.service.ForOwner(currentUser(c))
+	var todos []*model.Todo
+	page := model.NewPageRequest(calendarPageLimit, "", false, sort)
+	for {
+		res, err := s.FindAll(c.QueryParams(), page)
+		if err != nil {
+			if err == model.ErrInvalidSearchQuery {
+				return c.JSON(http.StatusBadRequest,
+					ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+			}
+			return c.JSON(http.StatusInternalServerError,
+				ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+		}
+		todos = append(todos, res.Todos...)
+		if res.NextCursor == "" {
+			break
+		}
+		page.Cursor = res.NextCursor
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, transfer.MIMECalendar+"; charset=utf-8")
+	c.Response().WriteHeader(http.StatusOK)
+	w, _ := transfer.NewWriter(transfer.ICS, c.Response())
+	for _, todo := range todos {
+		if err := w.Write(transfer.NewRecord(todo, nil)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// @Summary		Import todos from a calendar
+// @Description	Creates a todo for every VTODO component of an RFC 5545 calendar. Components that cannot be imported are reported and skipped.
+// @Tags			todos
+// @Security		BearerAuth
+// @Accept			text/calendar
+// @Produce		json
+// @Param			body	body		string	true	"iCalendar file"
+// @Success		200		{object}	ResponseData{Data=ImportResponse}
+// @Failure		400		{object}	ResponseError
+// @Failure		415		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/todos.ics [post]
+func (t *todoHandler) ImportCalendar(c echo.Context) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != transfer.MIMECalendar {
+		return c.JSON(http.StatusUnsupportedMediaType,
+			ResponseError{Errors: []Error{{Code: errors.CodeUnsupportedMediaType, Message: "expected " + transfer.MIMECalendar}}})
+	}
+
+	r, err := transfer.NewReader(transfer.ICS, c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	// A file that cannot be read to its end imports nothing, the invalid components alone are skipped.
+	var res *transfer.ImportResult
+	err = t.service.ForOwner(currentUser(c)).Transaction(func(s service.Todo) error {
+		res, err = transfer.Import(s, r, transfer.ImportOptions{})
+		return err
+	})
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "unexpected end of the calendar"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: NewImportResponse(res)})
+}
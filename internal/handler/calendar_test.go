@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHandler_Calendar(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "calendar@example.com")
+
+	call := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := call(http.MethodPost, "/api/v1/todos", echo.MIMEApplicationJSON,
+		`{"task":"Call Bob; then Alice, maybe", "priority":3, "due_at":"2030-05-01T09:30:00Z", "tags":["phone"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	rec = call(http.MethodPost, "/api/v1/todos", echo.MIMEApplicationJSON, `{"task":"Water plants", "priority":1}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var done struct {
+		Data model.Todo
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &done))
+	rec = call(http.MethodPatch, "/api/v1/todos/"+strconv.Itoa(done.Data.ID), mimeMergePatch, `{"status":"done"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	t.Run("feed", func(t *testing.T) {
+		rec := call(http.MethodGet, "/api/v1/todos.ics", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+		body := rec.Body.String()
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+		assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+		assert.Equal(t, 2, strings.Count(body, "BEGIN:VTODO"))
+		assert.Contains(t, body, "SUMMARY:Call Bob\\; then Alice\\, maybe\r\n")
+		assert.Contains(t, body, "STATUS:NEEDS-ACTION\r\n")
+		assert.Contains(t, body, "PRIORITY:1\r\n")
+		assert.Contains(t, body, "DUE:20300501T093000Z\r\n")
+		assert.Contains(t, body, "CATEGORIES:phone\r\n")
+		assert.Contains(t, body, "SUMMARY:Water plants\r\nSTATUS:COMPLETED\r\nPRIORITY:9\r\n")
+	})
+
+	t.Run("feed_filters", func(t *testing.T) {
+		rec := call(http.MethodGet, "/api/v1/todos.ics?status=done", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), "BEGIN:VTODO"))
+		assert.Contains(t, rec.Body.String(), "Water plants")
+
+		rec = call(http.MethodGet, "/api/v1/todos.ics?sort=unknown", "", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		calendar := strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//Example//Calendar//EN",
+			"BEGIN:VEVENT",
+			"UID:event-1",
+			"SUMMARY:Not a todo",
+			"END:VEVENT",
+			"BEGIN:VTODO",
+			"UID:todo-900@todo-manager-app",
+			"SUMMARY:Imported parent with a rather long summary that does not fit on a",
+			"  single content line",
+			"PRIORITY:2",
+			"STATUS:IN-PROCESS",
+			"DUE;TZID=Europe/Paris:20300102T100000",
+			"CATEGORIES:home,Weekend plans",
+			"END:VTODO",
+			"BEGIN:VTODO",
+			"UID:todo-901@todo-manager-app",
+			"SUMMARY:Imported child",
+			"RELATED-TO:todo-900@todo-manager-app",
+			"DUE;VALUE=DATE:20300103",
+			"END:VTODO",
+			"BEGIN:VTODO",
+			"SUMMARY:Cancelled",
+			"STATUS:CANCELLED",
+			"END:VTODO",
+			"END:VCALENDAR",
+		}, "\r\n") + "\r\n"
+		rec := call(http.MethodPost, "/api/v1/todos.ics", "text/calendar; charset=utf-8", calendar)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var res struct {
+			Data ImportResponse
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, 2, res.Data.Imported)
+		require.Len(t, res.Data.Rejected, 1)
+		assert.Equal(t, 3, res.Data.Rejected[0].Row)
+		assert.Contains(t, res.Data.Rejected[0].Message, "CANCELLED")
+
+		var todos []*model.Todo
+		require.NoError(t, dbInstance.Preload("Tags").Where("task LIKE ?", "Imported%").Order("id").Find(&todos).Error)
+		require.Len(t, todos, 2)
+		parent, child := todos[0], todos[1]
+		assert.Equal(t, "Imported parent with a rather long summary that does not fit on a single content line", parent.Task)
+		assert.Equal(t, model.High, parent.Priority)
+		assert.Equal(t, model.Processing, parent.Status)
+		assert.Equal(t, "2030-01-02T09:00:00Z", parent.DueAt.UTC().Format("2006-01-02T15:04:05Z07:00"))
+		assert.ElementsMatch(t, []string{"home", "weekend-plans"}, parent.Tags.Names())
+		assert.Equal(t, model.Medium, child.Priority)
+		require.NotNil(t, child.ParentID)
+		assert.Equal(t, parent.ID, *child.ParentID)
+	})
+
+	t.Run("import_invalid", func(t *testing.T) {
+		rec := call(http.MethodPost, "/api/v1/todos.ics", echo.MIMEApplicationJSON, "{}")
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		rec = call(http.MethodPost, "/api/v1/todos.ics", "text/calendar", "BEGIN:VCARD\r\n")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// A truncated calendar imports nothing.
+		rec = call(http.MethodPost, "/api/v1/todos.ics", "text/calendar",
+			"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Complete\r\nEND:VTODO\r\nBEGIN:VTODO\r\nSUMMARY:Trunc")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var count int64
+		require.NoError(t, dbInstance.Model(&model.Todo{}).Where("task = ?", "Complete").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+// setETag sets the ETag header of the response to the version of the todo.
+func setETag(c echo.Context, todo *model.Todo) {
+	c.Response().Header().Set(headerETag, fmt.Sprintf(`"%d"`, todo.Version))
+}
+
+// ifMatch returns the versions of the todo the If-Match header of the request
+// allows a change to, nil when the header is missing or "*".
+func ifMatch(c echo.Context) (model.Precondition, error) {
+	return parsePrecondition(c.Request().Header.Get(headerIfMatch))
+}
+
+// parsePrecondition returns the versions of the todo an If-Match value allows a change to.
+func parsePrecondition(header string) (model.Precondition, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	versions := model.Precondition{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-Match uses the strong comparison, a weak tag never matches.
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, fmt.Errorf("malformed If-Match header: %s", header)
+		}
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil || weak {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
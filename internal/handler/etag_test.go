@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHandler_IfMatch(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Revision{}, model.Todo{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Revision{}, model.Todo{}) })
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	call := func(t *testing.T, fn func(echo.Context) error, method, id, ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/dummy/target", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/todos/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, fn(c))
+		return rec
+	}
+
+	id := strconv.Itoa(createTask(t, e, handler, `{"task":"Shared", "priority":1}`))
+
+	rec := call(t, handler.Find, http.MethodGet, id, "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	t.Run("update_with_current_etag", func(t *testing.T) {
+		rec := call(t, handler.Update, http.MethodPut, id, etag, `{"task":"First tab", "priority":1, "status":"created"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("update_with_stale_etag", func(t *testing.T) {
+		rec := call(t, handler.Update, http.MethodPut, id, etag, `{"task":"Second tab", "priority":1, "status":"created"}`)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		rec = call(t, handler.Find, http.MethodGet, id, "", "")
+		assert.Contains(t, rec.Body.String(), "First tab")
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("if_match_list_and_wildcard", func(t *testing.T) {
+		rec := call(t, handler.Update, http.MethodPut, id, `"1", "2"`, `{"task":"First tab", "priority":2, "status":"created"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = call(t, handler.Update, http.MethodPut, id, "*", `{"task":"First tab", "priority":3, "status":"created"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("weak_etag_never_matches", func(t *testing.T) {
+		rec := call(t, handler.Update, http.MethodPut, id, `W/"4"`, `{"task":"First tab", "priority":1, "status":"created"}`)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	})
+
+	t.Run("malformed_if_match", func(t *testing.T) {
+		rec := call(t, handler.Update, http.MethodPut, id, `4`, `{"task":"First tab", "priority":1, "status":"created"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("concurrent_update_in_repository", func(t *testing.T) {
+		todoID, _ := strconv.Atoi(id)
+		first, err := repository.Find(todoID)
+		require.NoError(t, err)
+		second, err := repository.Find(todoID)
+		require.NoError(t, err)
+
+		first.Task = "Saved first"
+		require.NoError(t, repository.Update(first))
+		second.Task = "Saved second"
+		assert.Equal(t, model.ErrVersionMismatch, repository.Update(second))
+
+		found, err := repository.Find(todoID)
+		require.NoError(t, err)
+		assert.Equal(t, "Saved first", found.Task)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rec := call(t, handler.Delete, http.MethodDelete, id, etag, "")
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		rec = call(t, handler.Find, http.MethodGet, id, "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = call(t, handler.Delete, http.MethodDelete, id, rec.Header().Get("ETag"), "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
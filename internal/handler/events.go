@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/event"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// mimeEventStream is the media type of a Server-Sent Events stream.
+const mimeEventStream = "text/event-stream"
+
+// heartbeatInterval is how often a comment is sent on an idle stream to keep proxies from closing it.
+var heartbeatInterval = 15 * time.Second
+
+// EventsHandler is the request handler for the todo event stream.
+type EventsHandler interface {
+	Stream(c echo.Context) error
+}
+
+type eventsHandler struct {
+	Handler
+	broker *event.Broker
+}
+
+// NewEvents returns a new instance of the events handler.
+func NewEvents(b *event.Broker) EventsHandler {
+	return &eventsHandler{broker: b}
+}
+
+// @Summary		Stream the changes to todos
+// @Description	Server-Sent Events named after the event type (todo.created, todo.updated, todo.deleted, todo.restored) whose data is a model.TodoEvent. Reconnecting with the Last-Event-ID header, or the last_event_id query parameter, resumes after the given event; a "reset" event tells that the events since then are no longer available and the todos have to be read again.
+// @Tags			todos
+// @Security		BearerAuth
+// @Produce		text/event-stream
+// @Param			Last-Event-ID	header		int		false	"Id of the last event received"
+// @Param			last_event_id	query		int		false	"Id of the last event received, for clients unable to set the header"
+// @Param			access_token	query		string	false	"Access token or API key, for clients unable to set the Authorization header such as EventSource"
+// @Success		200				{object}	model.TodoEvent
+// @Failure		400				{object}	ResponseError
+// @Failure		503				{object}	ResponseError
+// @Router			/todos/events [get]
+func (h *eventsHandler) Stream(c echo.Context) error {
+	var lastEventID *int
+	v := c.Request().Header.Get("Last-Event-ID")
+	if v == "" {
+		v = c.QueryParam("last_event_id")
+	}
+	if v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 0 {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "invalid last event id: " + v}}})
+		}
+		lastEventID = &id
+	}
+
+	sub, err := h.broker.Subscribe(currentUser(c), lastEventID)
+	if err != nil {
+		if err == event.ErrClosed {
+			return c.JSON(http.StatusServiceUnavailable,
+				ResponseError{Errors: []Error{{Code: errors.CodeServiceUnavailable, Message: err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, mimeEventStream)
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	// Keeps nginx from buffering the stream.
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if sub.Reset {
+		if _, err := fmt.Fprint(res, "event: reset\ndata: {}\n\n"); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-sub.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+		case e := <-sub.Events():
+			if err := writeEvent(res, e); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// queryToken lets clients unable to set headers, such as EventSource in browsers, send their
+// credential as the access_token query parameter. It has to run before Authenticate.
+func queryToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if token := c.QueryParam("access_token"); token != "" && req.Header.Get(echo.HeaderAuthorization) == "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		return next(c)
+	}
+}
+
+// writeEvent writes an event in the Server-Sent Events format.
+func writeEvent(res *echo.Response, e *model.TodoEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is an event read from a Server-Sent Events stream.
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvent reads the next event of a stream, skipping comments.
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e != (sseEvent{}):
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEventsHandler_Stream(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "events@example.com")
+	otherToken := login(t, e, "events-other@example.com")
+
+	e.Listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = e.Start("") }()
+	url := "http://" + e.Listener.Addr().String() + "/api/v1"
+
+	call := func(token, method, target, body string) *http.Response {
+		req, err := http.NewRequest(method, url+target, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return res
+	}
+	stream := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, err := http.NewRequest(http.MethodGet, url+"/todos/events", nil)
+		require.NoError(t, err)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, mimeEventStream, res.Header.Get(echo.HeaderContentType))
+		return res, bufio.NewReader(res.Body)
+	}
+
+	// Changes made before the stream starts cannot be resumed from.
+	before := call(token, http.MethodPost, "/todos", `{"task":"Before", "priority":1}`)
+	require.Equal(t, http.StatusCreated, before.StatusCode)
+	before.Body.Close()
+
+	res, events := stream("")
+	created := call(token, http.MethodPost, "/todos", `{"task":"Streamed", "priority":1}`)
+	require.Equal(t, http.StatusCreated, created.StatusCode)
+	var todo struct {
+		Data model.Todo
+	}
+	require.NoError(t, json.NewDecoder(created.Body).Decode(&todo))
+	created.Body.Close()
+	other := call(otherToken, http.MethodPost, "/todos", `{"task":"Not streamed", "priority":1}`)
+	other.Body.Close()
+	deleted := call(token, http.MethodDelete, "/todos/"+strconv.Itoa(todo.Data.ID), "")
+	require.Equal(t, http.StatusNoContent, deleted.StatusCode)
+	deleted.Body.Close()
+
+	first := readEvent(t, events)
+	assert.Equal(t, "todo.created", first.event)
+	var data model.TodoEvent
+	require.NoError(t, json.Unmarshal([]byte(first.data), &data))
+	assert.Equal(t, first.id, strconv.Itoa(data.ID))
+	assert.Equal(t, todo.Data.ID, data.TodoID)
+	require.NotNil(t, data.Todo)
+	assert.Equal(t, "Streamed", data.Todo.Task)
+	second := readEvent(t, events)
+	assert.Equal(t, "todo.deleted", second.event)
+	// Disconnecting the client ends the stream.
+	res.Body.Close()
+
+	t.Run("resume", func(t *testing.T) {
+		res, events := stream(first.id)
+		defer res.Body.Close()
+		assert.Equal(t, second, readEvent(t, events))
+
+		res, events = stream("0")
+		defer res.Body.Close()
+		assert.Equal(t, "reset", readEvent(t, events).event)
+
+		// EventSource cannot set headers, the credential and the last event id are then sent in the query.
+		query, err := http.Get(url + "/todos/events?last_event_id=" + first.id + "&access_token=" + token)
+		require.NoError(t, err)
+		defer query.Body.Close()
+		require.Equal(t, http.StatusOK, query.StatusCode)
+		assert.Equal(t, second, readEvent(t, bufio.NewReader(query.Body)))
+
+		bad := call(token, http.MethodGet, "/todos/events?last_event_id=nope", "")
+		bad.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, bad.StatusCode)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		res, events := stream("")
+		defer res.Body.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		start := time.Now()
+		require.NoError(t, e.Shutdown(ctx))
+		assert.Less(t, time.Since(start), 3*time.Second)
+		_, err := events.ReadString('\n')
+		assert.Error(t, err)
+	})
+}
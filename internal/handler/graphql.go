@@ -0,0 +1,526 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/graphql-go/graphql"
+	"github.com/labstack/echo/v4"
+)
+
+// GraphQLHandler is the request handler for the GraphQL endpoint.
+type GraphQLHandler interface {
+	Query(c echo.Context) error
+}
+
+type graphQLHandler struct {
+	Handler
+	service service.Todo
+	schema  graphql.Schema
+}
+
+// NewGraphQL returns a new instance of the GraphQL handler.
+func NewGraphQL(s service.Todo) GraphQLHandler {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		// The schema is static, it only fails to build after a programming mistake.
+		panic(err)
+	}
+	return &graphQLHandler{service: s, schema: schema}
+}
+
+// GraphQLRequest is the request parameter for running a GraphQL operation
+type GraphQLRequest struct {
+	Query         string `json:"query" query:"query" validate:"required"`
+	OperationName string `json:"operationName,omitempty" query:"operationName"`
+	// Variables are the values of the variables of the operation, a JSON object in the query of a GET request.
+	Variables map[string]interface{} `json:"variables,omitempty" query:"-"`
+}
+
+// @Summary		Run a GraphQL query
+// @Description	Queries can also be sent with GET, mutations only with POST. The response is a GraphQL result whose errors carry the code and HTTP status of the matching REST error in their extensions.
+// @Description	The schema can be explored with GraphiQL at /graphiql on the Swagger server.
+// @Tags			graphql
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		GraphQLRequest	true	"json"
+// @Success		200		{object}	object
+// @Failure		400		{object}	ResponseError
+// @Router			/graphql [post]
+func (h *graphQLHandler) Query(c echo.Context) error {
+	var req GraphQLRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	if variables := c.QueryParam("variables"); variables != "" && c.Request().Method == http.MethodGet {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "the variables must be a JSON object"}}})
+		}
+	}
+
+	s := h.service.ForOwner(currentUser(c))
+	ctx := context.WithValue(c.Request().Context(), graphQLContextKey{}, &graphQLContext{
+		echo:     c,
+		service:  s,
+		children: &childrenLoader{service: s, loaded: map[int][]*model.Todo{}},
+		// GET requests are safe, which read-only API keys rely on.
+		mutable: c.Request().Method == http.MethodPost,
+	})
+	res := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+	return c.JSON(http.StatusOK, res)
+}
+
+// graphQLContextKey is the key of the graphQLContext in the context of the resolvers.
+type graphQLContextKey struct{}
+
+// graphQLContext is the request a GraphQL operation runs for.
+type graphQLContext struct {
+	echo    echo.Context
+	service service.Todo
+	// mutable tells whether mutations are allowed.
+	mutable  bool
+	children *childrenLoader
+}
+
+// childrenLoader reads the subtasks of the todos of a level of the response in one query.
+// The resolvers of a level queue their todo and return a thunk, which graphql-go only calls
+// once every resolver of the level has run.
+type childrenLoader struct {
+	service service.Todo
+	pending []int
+	loaded  map[int][]*model.Todo
+}
+
+// load queues the todo of the given id and returns a thunk resolving its subtasks.
+func (l *childrenLoader) load(id int) func() (interface{}, error) {
+	l.pending = append(l.pending, id)
+	return func() (interface{}, error) {
+		if _, ok := l.loaded[id]; !ok {
+			children, err := l.service.ChildrenOf(l.pending)
+			if err != nil {
+				return nil, resolveError(err)
+			}
+			for _, p := range l.pending {
+				l.loaded[p] = append([]*model.Todo{}, children[p]...)
+			}
+			l.pending = nil
+		}
+		return l.loaded[id], nil
+	}
+}
+
+// requestOf returns the request a resolver runs for.
+func requestOf(p graphql.ResolveParams) *graphQLContext {
+	return p.Context.Value(graphQLContextKey{}).(*graphQLContext)
+}
+
+// graphQLError is an error of a resolver, reported with the code and status of the matching REST error.
+type graphQLError struct {
+	status int
+	err    Error
+}
+
+func (e *graphQLError) Error() string {
+	return e.err.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError.
+func (e *graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.err.Code, "status": e.status}
+}
+
+// resolveError returns the GraphQL error matching an error of the todo service.
+func resolveError(err error) error {
+	if err == model.ErrInvalidCursor || err == model.ErrInvalidSearchQuery {
+		return &graphQLError{http.StatusBadRequest, Error{Code: errors.CodeBadRequest, Message: err.Error()}}
+	}
+	status, e := todoError(err)
+	return &graphQLError{status, e}
+}
+
+// operationResult returns the todo of an operation run for a mutation, or its error.
+func operationResult(res BatchResult) (*model.Todo, error) {
+	if len(res.Errors) > 0 {
+		return nil, &graphQLError{res.Status, res.Errors[0]}
+	}
+	return res.Data, nil
+}
+
+// mutation returns a resolver running fn only when the request allows changes.
+func mutation(fn graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if !requestOf(p).mutable {
+			return nil, &graphQLError{http.StatusMethodNotAllowed,
+				Error{Code: errors.CodeMethodNotAllowed, Message: "mutations must be sent with POST"}}
+		}
+		return fn(p)
+	}
+}
+
+// inputFields maps the fields of the GraphQL inputs to those of the REST request bodies.
+var inputFields = map[string]string{
+	"task":         "task",
+	"status":       "status",
+	"priority":     "priority",
+	"dueAt":        "due_at",
+	"scheduledFor": "scheduled_for",
+	"recurrence":   "recurrence",
+	"scope":        "scope",
+	"parentId":     "parent_id",
+	"force":        "force",
+	"tags":         "tags",
+	"projectId":    "project_id",
+}
+
+// operationBody returns the REST request body matching a GraphQL input, with the cleared fields set to null.
+func operationBody(input map[string]interface{}, cleared []interface{}) (json.RawMessage, error) {
+	body := make(map[string]interface{}, len(input)+len(cleared))
+	for name, value := range input {
+		body[inputFields[name]] = value
+	}
+	for _, name := range cleared {
+		body[name.(string)] = nil
+	}
+	return json.Marshal(body)
+}
+
+// todosQuery returns the filters of FindAll and the listing request matching the arguments of the todos query.
+func todosQuery(args map[string]interface{}) (url.Values, FindAllRequest) {
+	qry := url.Values{}
+	var req FindAllRequest
+	for name, value := range args {
+		switch name {
+		case "q", "task":
+			qry.Set(name, value.(string))
+		case "status":
+			qry.Set(name, string(value.(model.Status)))
+		case "dueBefore":
+			qry.Set("due_before", value.(time.Time).Format(time.RFC3339Nano))
+		case "dueAfter":
+			qry.Set("due_after", value.(time.Time).Format(time.RFC3339Nano))
+		case "overdue":
+			qry.Set(name, strconv.FormatBool(value.(bool)))
+		case "tag", "tagAny", "tagNone":
+			key := map[string]string{"tag": "tag", "tagAny": "tag_any", "tagNone": "tag_none"}[name]
+			for _, tag := range value.([]interface{}) {
+				qry.Add(key, tag.(string))
+			}
+		case "projectId":
+			qry.Set("project_id", strconv.Itoa(value.(int)))
+		case "limit":
+			req.Limit = value.(int)
+		case "cursor":
+			req.Cursor = value.(string)
+		case "withTotal":
+			req.WithTotal = value.(bool)
+		case "sort":
+			req.Sort = value.(string)
+		}
+	}
+	return qry, req
+}
+
+// newGraphQLSchema returns the schema of the GraphQL endpoint.
+func newGraphQLSchema() (graphql.Schema, error) {
+	status := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Status",
+		Description: "The status of a todo.",
+		Values: graphql.EnumValueConfigMap{
+			"CREATED":    &graphql.EnumValueConfig{Value: model.Created},
+			"PROCESSING": &graphql.EnumValueConfig{Value: model.Processing},
+			"DONE":       &graphql.EnumValueConfig{Value: model.Done},
+		},
+	})
+	priority := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Priority",
+		Description: "The priority of a todo.",
+		Values: graphql.EnumValueConfigMap{
+			"LOW":    &graphql.EnumValueConfig{Value: model.Low},
+			"MEDIUM": &graphql.EnumValueConfig{Value: model.Medium},
+			"HIGH":   &graphql.EnumValueConfig{Value: model.High},
+		},
+	})
+	editScope := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "EditScope",
+		Description: "The occurrences of a recurring todo an update applies to.",
+		Values: graphql.EnumValueConfigMap{
+			"THIS":   &graphql.EnumValueConfig{Value: string(model.ThisOccurrence)},
+			"FUTURE": &graphql.EnumValueConfig{Value: string(model.FutureOccurrences)},
+		},
+	})
+	deleteMode := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "DeleteMode",
+		Description: "What happens to the subtasks of a deleted todo.",
+		Values: graphql.EnumValueConfigMap{
+			"REFUSE":   &graphql.EnumValueConfig{Value: model.RefuseDelete},
+			"CASCADE":  &graphql.EnumValueConfig{Value: model.CascadeDelete},
+			"REPARENT": &graphql.EnumValueConfig{Value: model.ReparentDelete},
+		},
+	})
+	clearable := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "ClearableField",
+		Description: "An optional field of a todo that an update can clear.",
+		Values: graphql.EnumValueConfigMap{
+			"DUE_AT":        &graphql.EnumValueConfig{Value: "due_at"},
+			"SCHEDULED_FOR": &graphql.EnumValueConfig{Value: "scheduled_for"},
+			"PARENT_ID":     &graphql.EnumValueConfig{Value: "parent_id"},
+			"TAGS":          &graphql.EnumValueConfig{Value: "tags"},
+			"PROJECT_ID":    &graphql.EnumValueConfig{Value: "project_id"},
+		},
+	})
+
+	var todo *graphql.Object
+	todo = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		// The other fields are resolved from the fields of model.Todo of the same name.
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"task":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"status":       &graphql.Field{Type: graphql.NewNonNull(status)},
+				"priority":     &graphql.Field{Type: graphql.NewNonNull(priority)},
+				"createdAt":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"updatedAt":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+				"dueAt":        &graphql.Field{Type: graphql.DateTime},
+				"scheduledFor": &graphql.Field{Type: graphql.DateTime},
+				"occurrenceAt": &graphql.Field{Type: graphql.DateTime},
+				"parentId":     &graphql.Field{Type: graphql.Int},
+				"seriesId":     &graphql.Field{Type: graphql.Int},
+				"projectId":    &graphql.Field{Type: graphql.Int},
+				"tags": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*model.Todo).Tags.Names(), nil
+					},
+				},
+				"etag": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "The version of the todo, to send back as ifMatch.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return fmt.Sprintf(`"%d"`, p.Source.(*model.Todo).Version), nil
+					},
+				},
+				"overdue": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Boolean),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*model.Todo).IsOverdue(time.Now()), nil
+					},
+				},
+				"snippet": &graphql.Field{
+					Type:        graphql.String,
+					Description: "The highlighted match of a full-text search.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if snippet := p.Source.(*model.Todo).Snippet; snippet != "" {
+							return snippet, nil
+						}
+						return nil, nil
+					},
+				},
+				"children": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todo))),
+					Description: "The direct subtasks of the todo.",
+					// The subtasks of every todo of a level are read together by the loader of the request.
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return requestOf(p).children.load(p.Source.(*model.Todo).ID), nil
+					},
+				},
+			}
+		}),
+	})
+	todoPage := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoPage",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todo)))},
+			"nextCursor": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The cursor of the following page, null on the last page.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if cursor := p.Source.(*model.TodoPage).NextCursor; cursor != "" {
+						return cursor, nil
+					}
+					return nil, nil
+				},
+			},
+			"total": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "The number of todos matching the filters, set when withTotal is requested.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if total := p.Source.(*model.TodoPage).Total; total != nil {
+						return int(*total), nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	tags := graphql.NewList(graphql.NewNonNull(graphql.String))
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todo": &graphql.Field{
+				Type: todo,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo, err := requestOf(p).service.Find(p.Args["id"].(int))
+					if err != nil {
+						return nil, resolveError(err)
+					}
+					return todo, nil
+				},
+			},
+			"todos": &graphql.Field{
+				Type:        graphql.NewNonNull(todoPage),
+				Description: "A page of todos, with the filters and pagination of GET /todos.",
+				Args: graphql.FieldConfigArgument{
+					"q":         &graphql.ArgumentConfig{Type: graphql.String, Description: "Full-text search on task text."},
+					"task":      &graphql.ArgumentConfig{Type: graphql.String},
+					"status":    &graphql.ArgumentConfig{Type: status},
+					"dueBefore": &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"dueAfter":  &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"overdue":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"tag":       &graphql.ArgumentConfig{Type: tags, Description: "Only todos carrying all of these tags."},
+					"tagAny":    &graphql.ArgumentConfig{Type: tags, Description: "Only todos carrying at least one of these tags."},
+					"tagNone":   &graphql.ArgumentConfig{Type: tags, Description: "Only todos carrying none of these tags."},
+					"projectId": &graphql.ArgumentConfig{Type: graphql.Int},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, Description: "Page size, 50 by default and at most 200."},
+					"cursor":    &graphql.ArgumentConfig{Type: graphql.String},
+					"withTotal": &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"sort":      &graphql.ArgumentConfig{Type: graphql.String, Description: "Comma separated sort fields, as the sort parameter of GET /todos."},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r := requestOf(p)
+					qry, req := todosQuery(p.Args)
+					if err := r.echo.Validate(&req); err != nil {
+						return nil, resolveError(echo.NewHTTPError(http.StatusBadRequest, err.Error()))
+					}
+					sort, err := model.ParseSort(req.Sort)
+					if err != nil {
+						return nil, resolveError(echo.NewHTTPError(http.StatusBadRequest, err.Error()))
+					}
+					page, err := r.service.FindAll(qry, model.NewPageRequest(req.Limit, req.Cursor, req.WithTotal, sort))
+					if err != nil {
+						return nil, resolveError(err)
+					}
+					return page, nil
+				},
+			},
+		},
+	})
+
+	optional := func(t graphql.Input) *graphql.InputObjectFieldConfig {
+		return &graphql.InputObjectFieldConfig{Type: t}
+	}
+	createInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateTodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"task":         optional(graphql.NewNonNull(graphql.String)),
+			"priority":     optional(graphql.NewNonNull(priority)),
+			"dueAt":        optional(graphql.DateTime),
+			"scheduledFor": optional(graphql.DateTime),
+			"recurrence":   optional(graphql.String),
+			"parentId":     optional(graphql.Int),
+			"tags":         optional(tags),
+			"projectId":    optional(graphql.Int),
+		},
+	})
+	updateInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateTodoInput",
+		Description: "The fields to change, those left out are untouched.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"task":         optional(graphql.String),
+			"status":       optional(status),
+			"priority":     optional(priority),
+			"dueAt":        optional(graphql.DateTime),
+			"scheduledFor": optional(graphql.DateTime),
+			"recurrence":   optional(graphql.String),
+			"scope":        optional(editScope),
+			"parentId":     optional(graphql.Int),
+			"force":        optional(graphql.Boolean),
+			"tags":         optional(tags),
+			"projectId":    optional(graphql.Int),
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todo),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createInput)},
+				},
+				Resolve: mutation(func(p graphql.ResolveParams) (interface{}, error) {
+					r := requestOf(p)
+					body, err := operationBody(p.Args["input"].(map[string]interface{}), nil)
+					if err != nil {
+						return nil, resolveError(err)
+					}
+					return operationResult(runOperation(r.echo, r.service, BatchOperation{Op: createOperation, Body: body}))
+				}),
+			},
+			"updateTodo": &graphql.Field{
+				Type:        graphql.NewNonNull(todo),
+				Description: "Changes some fields of a todo, as PATCH /todos/{id}.",
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateInput)},
+					"clear":   &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(clearable))},
+					"ifMatch": &graphql.ArgumentConfig{Type: graphql.String, Description: "The etag the todo must have for the update to apply."},
+				},
+				Resolve: mutation(func(p graphql.ResolveParams) (interface{}, error) {
+					r := requestOf(p)
+					cleared, _ := p.Args["clear"].([]interface{})
+					body, err := operationBody(p.Args["input"].(map[string]interface{}), cleared)
+					if err != nil {
+						return nil, resolveError(err)
+					}
+					ifMatch, _ := p.Args["ifMatch"].(string)
+					op := BatchOperation{Op: patchOperation, ID: p.Args["id"].(int), IfMatch: ifMatch, Body: body}
+					return operationResult(runOperation(r.echo, r.service, op))
+				}),
+			},
+			"deleteTodo": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Moves a todo to the trash and returns its id.",
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"mode":    &graphql.ArgumentConfig{Type: deleteMode},
+					"ifMatch": &graphql.ArgumentConfig{Type: graphql.String, Description: "The etag the todo must have for the delete to apply."},
+				},
+				Resolve: mutation(func(p graphql.ResolveParams) (interface{}, error) {
+					r := requestOf(p)
+					id := p.Args["id"].(int)
+					mode, _ := p.Args["mode"].(model.DeleteMode)
+					ifMatch, _ := p.Args["ifMatch"].(string)
+					op := BatchOperation{Op: deleteOperation, ID: id, IfMatch: ifMatch, DeleteMode: mode}
+					if _, err := operationResult(runOperation(r.echo, r.service, op)); err != nil {
+						return nil, err
+					}
+					return id, nil
+				}),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutationType})
+}
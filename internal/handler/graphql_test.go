@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphQLResult is the response of the GraphQL endpoint.
+type graphQLResult struct {
+	Data   map[string]json.RawMessage
+	Errors []struct {
+		Message    string
+		Extensions map[string]interface{}
+	}
+}
+
+// graphQLTodo is a todo as selected by the tests.
+type graphQLTodo struct {
+	ID        int
+	Task      string
+	Status    string
+	Priority  string
+	DueAt     *string
+	ProjectID *int
+	Tags      []string
+	Etag      string
+	Children  []graphQLTodo
+}
+
+const graphQLTodoFields = `id task status priority dueAt projectId tags etag`
+
+func TestGraphQLHandler(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "graphql@example.com")
+	otherToken := login(t, e, "graphql-other@example.com")
+
+	run := func(t *testing.T, token string, query string, variables map[string]interface{}) (int, graphQLResult) {
+		body, err := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/graphql", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		var res graphQLResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res
+	}
+	decode := func(t *testing.T, res graphQLResult, field string, v interface{}) {
+		require.Empty(t, res.Errors)
+		require.NoError(t, json.Unmarshal(res.Data[field], v))
+	}
+	errorCode := func(t *testing.T, res graphQLResult) string {
+		require.Len(t, res.Errors, 1)
+		return res.Errors[0].Extensions["code"].(string)
+	}
+
+	create := func(t *testing.T, input string) graphQLTodo {
+		_, res := run(t, token, `mutation { createTodo(input: `+input+`) { `+graphQLTodoFields+` } }`, nil)
+		var todo graphQLTodo
+		decode(t, res, "createTodo", &todo)
+		return todo
+	}
+
+	t.Run("create", func(t *testing.T) {
+		todo := create(t, `{task: "Write report", priority: HIGH, dueAt: "2030-01-02T03:04:05Z", tags: ["Work"]}`)
+		assert.Equal(t, "Write report", todo.Task)
+		assert.Equal(t, "CREATED", todo.Status)
+		assert.Equal(t, "HIGH", todo.Priority)
+		require.NotNil(t, todo.DueAt)
+		assert.Equal(t, "2030-01-02T03:04:05Z", *todo.DueAt)
+		assert.Equal(t, []string{"work"}, todo.Tags)
+		assert.Equal(t, `"1"`, todo.Etag)
+
+		// The input is validated as the body of POST /todos.
+		_, res := run(t, token, `mutation { createTodo(input: {task: "Repeat", priority: LOW, recurrence: "FREQ=DAILY"}) { id } }`, nil)
+		assert.Equal(t, "BAD_REQUEST", errorCode(t, res))
+		_, res = run(t, token, `mutation { createTodo(input: {task: "Orphan", priority: LOW, parentId: -1}) { id } }`, nil)
+		assert.Equal(t, "BAD_REQUEST", errorCode(t, res))
+		// Required fields are checked by the schema.
+		_, res = run(t, token, `mutation { createTodo(input: {task: "No priority"}) { id } }`, nil)
+		assert.NotEmpty(t, res.Errors)
+	})
+
+	t.Run("find", func(t *testing.T) {
+		parent := create(t, `{task: "Parent", priority: MEDIUM}`)
+		child := create(t, `{task: "Child", priority: LOW, parentId: `+strconv.Itoa(parent.ID)+`}`)
+
+		code, res := run(t, token, `query($id: Int!) { todo(id: $id) { id task children { id task } } }`,
+			map[string]interface{}{"id": parent.ID})
+		assert.Equal(t, http.StatusOK, code)
+		var todo graphQLTodo
+		decode(t, res, "todo", &todo)
+		assert.Equal(t, "Parent", todo.Task)
+		assert.Equal(t, []graphQLTodo{{ID: child.ID, Task: "Child"}}, todo.Children)
+
+		// Todos of other users are not found.
+		_, res = run(t, otherToken, `{ todo(id: `+strconv.Itoa(parent.ID)+`) { id } }`, nil)
+		assert.Equal(t, "null", string(res.Data["todo"]))
+		assert.Equal(t, "NOT_FOUND", errorCode(t, res))
+		assert.Equal(t, float64(http.StatusNotFound), res.Errors[0].Extensions["status"])
+	})
+
+	t.Run("children", func(t *testing.T) {
+		first := create(t, `{task: "Family first", priority: HIGH}`)
+		second := create(t, `{task: "Family second", priority: LOW}`)
+		child := create(t, `{task: "Family child", priority: MEDIUM, parentId: `+strconv.Itoa(first.ID)+`}`)
+		grandchild := create(t, `{task: "Family grandchild", priority: LOW, parentId: `+strconv.Itoa(child.ID)+`}`)
+
+		// The subtasks of each level are read together and still returned under their own parent.
+		var page struct{ Todos []graphQLTodo }
+		_, res := run(t, token, `{ todos(task: "Family") { todos { id children { id children { id } } } } }`, nil)
+		decode(t, res, "todos", &page)
+		assert.ElementsMatch(t, []graphQLTodo{
+			{ID: first.ID, Children: []graphQLTodo{{ID: child.ID, Children: []graphQLTodo{{ID: grandchild.ID}}}}},
+			{ID: second.ID, Children: []graphQLTodo{}},
+			{ID: child.ID, Children: []graphQLTodo{{ID: grandchild.ID, Children: []graphQLTodo{}}}},
+			{ID: grandchild.ID, Children: []graphQLTodo{}},
+		}, page.Todos)
+	})
+
+	t.Run("find_all", func(t *testing.T) {
+		clearDB(dbInstance, model.Todo{}, model.Tag{})
+		create(t, `{task: "Buy milk", priority: LOW, tags: ["home"]}`)
+		create(t, `{task: "Buy bread", priority: HIGH, tags: ["home", "urgent"]}`)
+		create(t, `{task: "Call bank", priority: MEDIUM}`)
+
+		var page struct {
+			Todos      []graphQLTodo
+			NextCursor *string
+			Total      *int
+		}
+		_, res := run(t, token, `{ todos(task: "Buy", tag: ["home"], sort: "priority", limit: 1, withTotal: true) {
+			todos { task } nextCursor total } }`, nil)
+		decode(t, res, "todos", &page)
+		assert.Equal(t, []graphQLTodo{{Task: "Buy milk"}}, page.Todos)
+		require.NotNil(t, page.Total)
+		assert.Equal(t, 2, *page.Total)
+		require.NotNil(t, page.NextCursor)
+
+		_, res = run(t, token, `query($cursor: String) { todos(task: "Buy", tag: ["home"], sort: "priority", limit: 1, cursor: $cursor) {
+			todos { task } nextCursor total } }`, map[string]interface{}{"cursor": *page.NextCursor})
+		decode(t, res, "todos", &page)
+		assert.Equal(t, []graphQLTodo{{Task: "Buy bread"}}, page.Todos)
+		assert.Nil(t, page.NextCursor)
+		assert.Nil(t, page.Total)
+
+		_, res = run(t, token, `{ todos(tagNone: ["home"], status: CREATED) { todos { task } } }`, nil)
+		decode(t, res, "todos", &page)
+		assert.Equal(t, []graphQLTodo{{Task: "Call bank"}}, page.Todos)
+
+		_, res = run(t, token, `{ todos(limit: 500) { todos { id } } }`, nil)
+		assert.Equal(t, "BAD_REQUEST", errorCode(t, res))
+		_, res = run(t, token, `{ todos(sort: "nope") { todos { id } } }`, nil)
+		assert.Equal(t, "BAD_REQUEST", errorCode(t, res))
+		_, res = run(t, token, `{ todos(cursor: "nope") { todos { id } } }`, nil)
+		assert.Equal(t, "BAD_REQUEST", errorCode(t, res))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		todo := create(t, `{task: "Draft", priority: LOW, dueAt: "2030-01-02T03:04:05Z", tags: ["work"]}`)
+		_, res := run(t, token, `mutation($id: Int!) { updateTodo(id: $id, input: {status: PROCESSING, task: "Final"}, clear: [DUE_AT]) { `+
+			graphQLTodoFields+` } }`, map[string]interface{}{"id": todo.ID})
+		var updated graphQLTodo
+		decode(t, res, "updateTodo", &updated)
+		assert.Equal(t, "Final", updated.Task)
+		assert.Equal(t, "PROCESSING", updated.Status)
+		assert.Equal(t, "LOW", updated.Priority)
+		assert.Nil(t, updated.DueAt)
+		assert.Equal(t, []string{"work"}, updated.Tags)
+		assert.Equal(t, `"2"`, updated.Etag)
+
+		// The todo changed since the given etag was read.
+		_, res = run(t, token, `mutation($id: Int!, $etag: String) { updateTodo(id: $id, input: {task: "Stale"}, ifMatch: $etag) { id } }`,
+			map[string]interface{}{"id": todo.ID, "etag": todo.Etag})
+		assert.Equal(t, "PRECONDITION_FAILED", errorCode(t, res))
+		_, res = run(t, otherToken, `mutation { updateTodo(id: `+strconv.Itoa(todo.ID)+`, input: {task: "Stolen"}) { id } }`, nil)
+		assert.Equal(t, "NOT_FOUND", errorCode(t, res))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		parent := create(t, `{task: "Parent", priority: LOW}`)
+		create(t, `{task: "Child", priority: LOW, parentId: `+strconv.Itoa(parent.ID)+`}`)
+		_, res := run(t, token, `mutation { deleteTodo(id: `+strconv.Itoa(parent.ID)+`) }`, nil)
+		assert.Equal(t, "CONFLICT", errorCode(t, res))
+
+		_, res = run(t, token, `mutation { deleteTodo(id: `+strconv.Itoa(parent.ID)+`, mode: CASCADE) }`, nil)
+		var id int
+		decode(t, res, "deleteTodo", &id)
+		assert.Equal(t, parent.ID, id)
+		_, res = run(t, token, `{ todo(id: `+strconv.Itoa(parent.ID)+`) { id } }`, nil)
+		assert.Equal(t, "NOT_FOUND", errorCode(t, res))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		todo := create(t, `{task: "Read over GET", priority: LOW}`)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/apikeys", bytes.NewReader([]byte(`{"name":"dashboard", "scope":"read"}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var key struct {
+			Data CreatedAPIKey
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &key))
+
+		get := func(query string, variables string) (int, graphQLResult) {
+			params := url.Values{"query": {query}}
+			if variables != "" {
+				params.Set("variables", variables)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/graphql?"+params.Encode(), nil)
+			// Read-only API keys are limited to GET requests.
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+key.Data.Key)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			var res graphQLResult
+			if rec.Code == http.StatusOK {
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			}
+			return rec.Code, res
+		}
+
+		code, res := get(`query($id: Int!) { todo(id: $id) { task } }`, `{"id":`+strconv.Itoa(todo.ID)+`}`)
+		require.Equal(t, http.StatusOK, code)
+		var found graphQLTodo
+		decode(t, res, "todo", &found)
+		assert.Equal(t, "Read over GET", found.Task)
+
+		// Mutations are not safe, they need a POST.
+		_, res = get(`mutation { deleteTodo(id: `+strconv.Itoa(todo.ID)+`) }`, "")
+		assert.Equal(t, "METHOD_NOT_ALLOWED", errorCode(t, res))
+		_, res = get(`{ todo(id: `+strconv.Itoa(todo.ID)+`) { id } }`, "")
+		assert.Empty(t, res.Errors)
+
+		code, _ = run(t, key.Data.Key, `{ todo(id: 1) { id } }`, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = get(`{ todo(id: 1) { id } }`, `[`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = get("", "")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("invalid_query", func(t *testing.T) {
+		code, res := run(t, token, `{ todos { nope } }`, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.NotEmpty(t, res.Errors)
+		assert.Nil(t, res.Data)
+	})
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// mimeMergePatch is the media type of a JSON Merge Patch (RFC 7396).
+const mimeMergePatch = "application/merge-patch+json"
+
+// patchAttempts is how many times a patch is applied again when the todo
+// changes between reading and saving it.
+const patchAttempts = 3
+
+// PatchRequest is the request parameter for patching a todo
+type PatchRequest struct {
+	ID int `param:"id" validate:"required"`
+}
+
+// @Summary		Patch a todo
+// @Description	The body is a JSON Merge Patch (RFC 7396) of the fields of PUT /todos/{id}: fields left out are untouched and fields set to null are cleared.
+// @Description	Recurrence, scope and force apply to this request only, as with PUT.
+// @Tags			todos
+// @Security		BearerAuth
+// @Accept			application/merge-patch+json
+// @Produce		json
+// @Param			body		body		UpdateRequestBody	true	"merge patch"
+// @Param			path		path		PatchRequest		false	"path"
+// @Param			If-Match	header		string				false	"ETag of the todo as last read, the patch fails with 412 if the todo changed since"
+// @Success		200			{object}	ResponseData{Data=model.Todo}
+// @Header			200			{string}	ETag	"Version of the patched todo"
+// @Failure		400			{object}	ResponseError
+// @Failure		404			{object}	ResponseError
+// @Failure		409			{object}	ResponseError
+// @Failure		412			{object}	ResponseError
+// @Failure		415			{object}	ResponseError
+// @Failure		500			{object}	ResponseError
+// @Router			/todos/{id} [patch]
+func (t *todoHandler) Patch(c echo.Context) error {
+	var req PatchRequest
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	ctype := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(ctype, mimeMergePatch) && !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+		return c.JSON(http.StatusUnsupportedMediaType,
+			ResponseError{Errors: []Error{{Code: errors.CodeUnsupportedMediaType, Message: "the body must be a " + mimeMergePatch + " document"}}})
+	}
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: "the merge patch must be a JSON object"}}})
+	}
+	precondition, err := ifMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	todo, err := ApplyPatch(c.Validate, t.service.ForOwner(currentUser(c)), req.ID, patch, precondition)
+	if err != nil {
+		return updateError(c, err)
+	}
+
+	setETag(c, todo)
+	return c.JSON(http.StatusOK, ResponseData{Data: todo})
+}
+
+// ApplyPatch applies a merge patch to the current state of the todo and saves the result,
+// starting over when the todo changed in between unless the client expected a version.
+// The patched todo is checked with validate before being saved.
+func ApplyPatch(validate func(i interface{}) error, s service.Todo, id int, patch map[string]interface{}, precondition model.Precondition) (*model.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := s.Find(id)
+		if err != nil {
+			return nil, err
+		}
+		if !precondition.Allows(current.Version) {
+			return nil, model.ErrVersionMismatch
+		}
+		req, err := patchTodo(current, patch)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err := validate(&req); err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		opts := req.Options()
+		opts.IfMatch = model.Precondition{current.Version}
+		todo, err := s.Update(id, req.Task, req.Priority, req.Status, req.Details(), opts)
+		if err == model.ErrVersionMismatch && precondition == nil && attempt < patchAttempts {
+			continue
+		}
+		return todo, err
+	}
+}
+
+// patchTodo returns the full replacement of the todo with the merge patch applied.
+func patchTodo(todo *model.Todo, patch map[string]interface{}) (UpdateRequestBody, error) {
+	current := UpdateRequestBody{
+		Task:         todo.Task,
+		Status:       todo.Status,
+		Priority:     todo.Priority,
+		DueAt:        todo.DueAt,
+		ScheduledFor: todo.ScheduledFor,
+		ParentID:     todo.ParentID,
+		Tags:         todo.Tags.Names(),
+		ProjectID:    todo.ProjectID,
+	}
+	var doc interface{}
+	if err := remarshal(current, &doc); err != nil {
+		return UpdateRequestBody{}, err
+	}
+	// Field names are matched case-insensitively, as when decoding the body of a PUT.
+	fields := make(map[string]interface{}, len(patch))
+	for name, value := range patch {
+		fields[strings.ToLower(name)] = value
+	}
+	var req UpdateRequestBody
+	if err := remarshal(mergePatch(doc, fields), &req); err != nil {
+		return UpdateRequestBody{}, err
+	}
+	return req, nil
+}
+
+// mergePatch applies a JSON Merge Patch to a decoded JSON document as described in RFC 7396.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+		} else {
+			t[name] = mergePatch(t[name], value)
+		}
+	}
+	return t
+}
+
+// remarshal converts a value into another through its JSON encoding.
+func remarshal(from interface{}, to interface{}) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
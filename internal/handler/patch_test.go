@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHandler_Patch(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}) })
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	const createBody = `{"task":"Patched Task", "priority":1, "due_at":"2030-01-02T00:00:00Z", "scheduled_for":"2030-01-01T09:00:00Z", "tags":["work"]}`
+
+	tests := []struct {
+		name        string
+		patchBody   string
+		patchID     string
+		contentType string
+		ifMatch     string
+		want        want
+	}{
+		{
+			name:      "absent_fields_are_untouched",
+			patchBody: `{"priority":3, "status":"processing"}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response: []byte(`{"data":{"Task":"Patched Task","Status":"processing","Priority":3,
+					"DueAt":"2030-01-02T00:00:00Z","ScheduledFor":"2030-01-01T09:00:00Z","Tags":["work"]}}`),
+			},
+		},
+		{
+			name:      "null_clears_fields",
+			patchBody: `{"scheduled_for":null, "tags":null}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response:   []byte(`{"data":{"Task":"Patched Task","Status":"created","Priority":1,"DueAt":"2030-01-02T00:00:00Z"}}`),
+			},
+		},
+		{
+			name:        "application_json_is_a_merge_patch",
+			patchBody:   `{"task":"Renamed"}`,
+			contentType: echo.MIMEApplicationJSON,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response: []byte(`{"data":{"Task":"Renamed","Status":"created","Priority":1,
+					"DueAt":"2030-01-02T00:00:00Z","ScheduledFor":"2030-01-01T09:00:00Z","Tags":["work"]}}`),
+			},
+		},
+		{
+			name:      "field_names_ignore_case",
+			patchBody: `{"Status":"done"}`,
+			want: want{
+				StatusCode: http.StatusOK,
+				Response: []byte(`{"data":{"Task":"Patched Task","Status":"done","Priority":1,
+					"DueAt":"2030-01-02T00:00:00Z","ScheduledFor":"2030-01-01T09:00:00Z","Tags":["work"]}}`),
+			},
+		},
+		{
+			name:      "clearing_a_required_field",
+			patchBody: `{"task":null}`,
+			want:      want{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:      "invalid_value",
+			patchBody: `{"priority":22}`,
+			want:      want{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:      "invalid_type",
+			patchBody: `{"task":1}`,
+			want:      want{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:      "not_an_object",
+			patchBody: `["task"]`,
+			want:      want{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name:        "unsupported_media_type",
+			patchBody:   `task=Renamed`,
+			contentType: echo.MIMEApplicationForm,
+			want:        want{StatusCode: http.StatusUnsupportedMediaType},
+		},
+		{
+			name:      "stale_if_match",
+			patchBody: `{"task":"Renamed"}`,
+			ifMatch:   `"0"`,
+			want:      want{StatusCode: http.StatusPreconditionFailed},
+		},
+		{
+			name:      "not_found_record",
+			patchID:   "-1",
+			patchBody: `{"task":"Renamed"}`,
+			want:      want{StatusCode: http.StatusNotFound},
+		},
+		{
+			name:      "invalid_request_parameter",
+			patchID:   "invalid",
+			patchBody: `{"task":"Renamed"}`,
+			want:      want{StatusCode: http.StatusBadRequest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare
+			id := tt.patchID
+			if id == "" {
+				id = strconv.Itoa(createTask(t, e, handler, createBody))
+			}
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = mimeMergePatch
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/dummy/target", bytes.NewReader([]byte(tt.patchBody)))
+			req.Header.Set(echo.HeaderContentType, contentType)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+
+			// Execute
+			require.NoError(t, handler.Patch(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"CreatedAt": 1, "UpdatedAt": 1, "ID": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7396 appendix A.
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch interface{}
+		require.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+		require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+		got, err := json.Marshal(mergePatch(target, patch))
+		require.NoError(t, err)
+		assert.JSONEq(t, tt.want, string(got), "%s patched with %s", tt.target, tt.patch)
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"sort"
+	"sync"
+)
+
+// PresenceState is what a client is doing.
+type PresenceState string
+
+const (
+	// OnlinePresence is a connected client not looking at any todo.
+	OnlinePresence = PresenceState("online")
+	// ViewingPresence is a client showing a todo.
+	ViewingPresence = PresenceState("viewing")
+	// EditingPresence is a client editing a todo.
+	EditingPresence = PresenceState("editing")
+)
+
+// Presence is what a connected client is doing
+type Presence struct {
+	// Connection identifies the connection of the client.
+	Connection string `json:"connection"`
+	// Client is the name the client connected with.
+	Client string `json:"client,omitempty"`
+	// TodoID is the todo viewed or edited.
+	TodoID int           `json:"todo_id,omitempty"`
+	State  PresenceState `json:"state"`
+}
+
+// presenceHub keeps the presence of the connected clients, telling every client of a user when
+// the presence of the others changes.
+type presenceHub struct {
+	mu    sync.Mutex
+	conns map[int]map[*wsConn]Presence
+}
+
+func newPresenceHub() *presenceHub {
+	return &presenceHub{conns: make(map[int]map[*wsConn]Presence)}
+}
+
+// join adds a client as online.
+func (h *presenceHub) join(c *wsConn) {
+	h.set(c, Presence{Connection: c.id, Client: c.client, State: OnlinePresence})
+}
+
+// set changes the presence of a client.
+func (h *presenceHub) set(c *wsConn, p Presence) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conns[c.userID] == nil {
+		h.conns[c.userID] = make(map[*wsConn]Presence)
+	}
+	h.conns[c.userID][c] = p
+	h.broadcast(c.userID)
+}
+
+// leave removes a client.
+func (h *presenceHub) leave(c *wsConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns[c.userID], c)
+	if len(h.conns[c.userID]) == 0 {
+		delete(h.conns, c.userID)
+		return
+	}
+	h.broadcast(c.userID)
+}
+
+// broadcast sends the presence of every client of a user to each of them, h.mu being held. The messages
+// are queued without blocking, so that a lagging client doesn't hold up the presence of the others.
+func (h *presenceHub) broadcast(userID int) {
+	presence := make([]Presence, 0, len(h.conns[userID]))
+	for _, p := range h.conns[userID] {
+		presence = append(presence, p)
+	}
+	sort.Slice(presence, func(i, j int) bool { return presence[i].Connection < presence[j].Connection })
+	for c := range h.conns[userID] {
+		c.enqueue(WSServerMessage{Type: presenceMessage, Presence: presence})
+	}
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// ProjectHandler is the request handler for the project endpoint.
+type ProjectHandler interface {
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	Find(c echo.Context) error
+	FindAll(c echo.Context) error
+	Todos(c echo.Context) error
+}
+
+type projectHandler struct {
+	Handler
+	service service.Project
+}
+
+// NewProject returns a new instance of the project handler.
+func NewProject(s service.Project) ProjectHandler {
+	return &projectHandler{service: s}
+}
+
+// CreateProjectRequest is the request parameter for creating a new project
+type CreateProjectRequest struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Color string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	// Position orders the project among the others, defaults to last.
+	Position *int `json:"position,omitempty" validate:"omitempty,min=0"`
+}
+
+// @Summary	Create a new project
+// @Tags		projects
+// @Security	BearerAuth
+// @Accept		json
+// @Produce	json
+// @Param		request	body		CreateProjectRequest	true	"json"
+// @Success	201		{object}	ResponseData{data=model.Project}
+// @Failure	400		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/projects [post]
+func (p *projectHandler) Create(c echo.Context) error {
+	var req CreateProjectRequest
+	if err := p.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	project, err := p.service.ForOwner(currentUser(c)).Create(req.Name, req.Color, req.Position)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: project})
+}
+
+// UpdateProjectRequest is the request parameter for updating a project
+type UpdateProjectRequest struct {
+	ID    int    `param:"id" validate:"required" swaggerignore:"true"`
+	Name  string `json:"name,omitempty" validate:"max=100"`
+	Color string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	// Archived hides the todos of the project from the default todo list.
+	Archived *bool `json:"archived,omitempty"`
+	Position *int  `json:"position,omitempty" validate:"omitempty,min=0"`
+}
+
+// @Summary		Update a project
+// @Description	Archiving a project hides its todos from the default todo list; they are still listed under the project.
+// @Tags			projects
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			id		path		int						true	"Project ID"
+// @Param			body	body		UpdateProjectRequest	true	"body"
+// @Success		200		{object}	ResponseData{Data=model.Project}
+// @Failure		400		{object}	ResponseError
+// @Failure		404		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/projects/{id} [put]
+func (p *projectHandler) Update(c echo.Context) error {
+	var req UpdateProjectRequest
+	if err := p.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	project, err := p.service.ForOwner(currentUser(c)).Update(req.ID, req.Name, req.Color, req.Archived, req.Position)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "project not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: project})
+}
+
+// ProjectRequest is the request parameter for finding or deleting a project
+type ProjectRequest struct {
+	ID int `param:"id" validate:"required"`
+}
+
+// @Summary		Delete a project
+// @Description	The todos of a deleted project are kept outside of any project.
+// @Tags			projects
+// @Security		BearerAuth
+// @Param			path	path	ProjectRequest	false	"path"
+// @Success		204
+// @Failure		400	{object}	ResponseError
+// @Failure		404	{object}	ResponseError
+// @Failure		500	{object}	ResponseError
+// @Router			/projects/{id} [delete]
+func (p *projectHandler) Delete(c echo.Context) error {
+	var req ProjectRequest
+	if err := p.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := p.service.ForOwner(currentUser(c)).Delete(req.ID); err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "project not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Find a project
+// @Tags		projects
+// @Security	BearerAuth
+// @Param		path	path		ProjectRequest	false	"path"
+// @Success	200		{object}	ResponseData{Data=model.Project}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/projects/{id} [get]
+func (p *projectHandler) Find(c echo.Context) error {
+	var req ProjectRequest
+	if err := p.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := p.service.ForOwner(currentUser(c)).Find(req.ID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "project not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// FindAllProjectsRequest is the request parameter for listing projects
+type FindAllProjectsRequest struct {
+	Archived *bool `query:"archived"`
+}
+
+// @Summary	Find all projects
+// @Tags		projects
+// @Security	BearerAuth
+// @Param		archived	query		bool	false	"Only archived (true) or active (false) projects"
+// @Success	200			{object}	ResponseData{Data=[]model.Project}
+// @Failure	400			{object}	ResponseError
+// @Failure	500			{object}	ResponseError
+// @Router		/projects [get]
+func (p *projectHandler) FindAll(c echo.Context) error {
+	var req FindAllProjectsRequest
+	if err := p.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := p.service.ForOwner(currentUser(c)).FindAll(c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// ProjectTodosRequest is the request parameter for listing the todos of a project
+type ProjectTodosRequest struct {
+	ID int `param:"id" validate:"required" swaggerignore:"true"`
+	FindAllRequest
+}
+
+// @Summary		Find the todos of a project
+// @Description	Lists the todos of the project, even when it is archived. Accepts the filters, sorting and pagination of the todo list.
+// @Tags			projects
+// @Security		BearerAuth
+// @Param			id			path		int		true	"Project ID"
+// @Param			q			query		string	false	"Full-text search on task text"
+// @Param			status		query		string	false	"Filter by task status"
+// @Param			tag			query		string	false	"Only todos carrying all of these comma separated tags"
+// @Param			limit		query		int		false	"Page size (default 50, max 200)"
+// @Param			cursor		query		string	false	"Opaque cursor taken from meta.next_cursor of the previous page"
+// @Param			with_total	query		bool	false	"Include the total number of matching todos in meta.total"
+// @Param			sort		query		string	false	"Comma separated sort fields, prefixed with - for descending order"
+// @Success		200			{object}	ResponseData{Data=[]model.Todo}
+// @Failure		400			{object}	ResponseError
+// @Failure		404			{object}	ResponseError
+// @Failure		500			{object}	ResponseError
+// @Router			/projects/{id}/todos [get]
+func (p *projectHandler) Todos(c echo.Context) error {
+	var req ProjectTodosRequest
+	if err := p.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if _, err := p.service.ForOwner(currentUser(c)).Find(req.ID); err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "project not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return listTodos(c, req.FindAllRequest, func(page model.PageRequest) (*model.TodoPage, error) {
+		return p.service.ForOwner(currentUser(c)).Todos(req.ID, c.QueryParams(), page)
+	})
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectHandler_Create(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Project{})
+	todoService := service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance))
+	service := service.NewProject(repository.NewProject(dbInstance), todoService)
+	handler := NewProject(service)
+
+	tests := []struct {
+		name       string
+		createBody string
+		want       want
+	}{
+		{
+			name:       "successful_create",
+			createBody: `{"name":"Work", "color":"#ff8800"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"Name":"Work", "Color":"#ff8800", "Archived":false, "Position":0}}`),
+			},
+		},
+		{
+			name:       "successful_create_goes_last",
+			createBody: `{"name":"Home"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"Name":"Home", "Color":"", "Archived":false, "Position":1}}`),
+			},
+		},
+		{
+			name:       "successful_create_with_position",
+			createBody: `{"name":"Inbox", "position":0}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"Name":"Inbox", "Color":"", "Archived":false, "Position":0}}`),
+			},
+		},
+		{
+			name:       "create_with_invalid_color",
+			createBody: `{"name":"Work", "color":"orange"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:       "create_without_name",
+			createBody: `{"color":"#fff"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/dummy/target", bytes.NewReader([]byte(tt.createBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/projects")
+
+			// Execute
+			require.NoError(t, handler.Create(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"CreatedAt": 1, "UpdatedAt": 1, "ID": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestProjectHandler_Archive(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Project{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Project{}) })
+	todoService := service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance))
+	projectService := service.NewProject(repository.NewProject(dbInstance), todoService)
+	todoHandler := NewTodo(todoService)
+	handler := NewProject(projectService)
+
+	call := func(t *testing.T, fn func(echo.Context) error, method, target, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, fn(c))
+		return rec
+	}
+	tasks := func(t *testing.T, rec *httptest.ResponseRecorder) []string {
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data []model.Todo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		got := []string{}
+		for _, todo := range res.Data {
+			got = append(got, todo.Task)
+		}
+		return got
+	}
+
+	project, err := projectService.Create("Garden", "", nil)
+	require.NoError(t, err)
+	id := strconv.Itoa(project.ID)
+	createTask(t, e, todoHandler, fmt.Sprintf(`{"task":"Mow lawn", "priority":2, "project_id":%d}`, project.ID))
+	createTask(t, e, todoHandler, `{"task":"Pay rent", "priority":1}`)
+
+	t.Run("create_todo_in_unknown_project", func(t *testing.T) {
+		rec := call(t, todoHandler.Create, http.MethodPost, "/todos", "", `{"task":"Lost", "priority":1, "project_id":-1}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("active_project", func(t *testing.T) {
+		assert.Equal(t, []string{"Mow lawn", "Pay rent"}, tasks(t, call(t, todoHandler.FindAll, http.MethodGet, "/todos", "", "")))
+		assert.Equal(t, []string{"Mow lawn"}, tasks(t, call(t, handler.Todos, http.MethodGet, "/projects/1/todos", id, "")))
+	})
+
+	t.Run("archived_project", func(t *testing.T) {
+		rec := call(t, handler.Update, http.MethodPut, "/projects/1", id, `{"archived":true}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data model.Project
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.True(t, res.Data.Archived)
+		assert.Equal(t, "Garden", res.Data.Name)
+
+		assert.Equal(t, []string{"Pay rent"}, tasks(t, call(t, todoHandler.FindAll, http.MethodGet, "/todos", "", "")))
+		assert.Equal(t, []string{"Mow lawn"}, tasks(t, call(t, todoHandler.FindAll, http.MethodGet, "/todos?project_id="+id, "", "")))
+		assert.Equal(t, []string{"Mow lawn"}, tasks(t, call(t, handler.Todos, http.MethodGet, "/projects/1/todos", id, "")))
+	})
+
+	t.Run("unknown_project", func(t *testing.T) {
+		rec := call(t, handler.Todos, http.MethodGet, "/projects/-1/todos", "-1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = call(t, handler.Update, http.MethodPut, "/projects/-1", "-1", `{"name":"Other"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("delete_keeps_todos", func(t *testing.T) {
+		rec := call(t, handler.Delete, http.MethodDelete, "/projects/1", id, "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		rec = call(t, handler.Find, http.MethodGet, "/projects/1", id, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, []string{"Mow lawn", "Pay rent"}, tasks(t, call(t, todoHandler.FindAll, http.MethodGet, "/todos", "", "")))
+	})
+}
@@ -4,6 +4,26 @@ package handler
 type ResponseData struct {
 	// Data is the response data.
 	Data interface{} `json:"data,omitempty"`
+	// Meta is the metadata of a list response.
+	Meta *Meta `json:"meta,omitempty"`
+	// Links are the related links of a list response.
+	Links *Links `json:"links,omitempty"`
+}
+
+// Meta is the pagination metadata of a list response.
+type Meta struct {
+	// Limit is the page size that was applied.
+	Limit int `json:"limit"`
+	// NextCursor is the cursor of the following page, omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total is the number of items matching the filters, set when with_total is requested.
+	Total *int64 `json:"total,omitempty"`
+}
+
+// Links is the links structure of a list response.
+type Links struct {
+	// Next is the URL of the following page, omitted on the last page.
+	Next string `json:"next,omitempty"`
 }
 
 // ResponseError is the response structure for the application.
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary		List the revisions of a todo
+// @Description	Every change made to the todo, oldest first. The history of a todo in the trash stays available until it is purged.
+// @Tags			todos
+// @Security		BearerAuth
+// @Param			path	path		FindRequest	false	"path"
+// @Success		200		{object}	ResponseData{Data=[]model.Revision}
+// @Failure		400		{object}	ResponseError
+// @Failure		404		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/todos/{id}/history [get]
+func (t *todoHandler) History(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := t.service.ForOwner(currentUser(c)).History(req.ID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// RevertRequest is the request for reverting a todo to one of its revisions
+type RevertRequest struct {
+	RevertRequestBody
+	FindRequest
+}
+
+// RevertRequestBody is the request body for reverting a todo to one of its revisions
+type RevertRequestBody struct {
+	// Revision is the revision whose state the todo goes back to.
+	Revision int `json:"revision" validate:"required"`
+}
+
+// @Summary		Revert a todo to a revision
+// @Description	Sets the fields of the todo back to their values right after the given revision. The revert is recorded as a new revision.
+// @Tags			todos
+// @Security		BearerAuth
+// @Param			body	body		RevertRequestBody	true	"body"
+// @Param			path	path		FindRequest			false	"path"
+// @Success		200		{object}	ResponseData{Data=model.Todo}
+// @Failure		400		{object}	ResponseError
+// @Failure		404		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/todos/{id}/revert [post]
+func (t *todoHandler) Revert(c echo.Context) error {
+	var req RevertRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := t.service.ForOwner(currentUser(c)).Revert(req.ID, req.Revision)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found"}}})
+		}
+		if err == model.ErrRevisionNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: err.Error()}}})
+		}
+		if err == model.ErrParentNotFound || err == model.ErrParentCycle || err == model.ErrProjectNotFound {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
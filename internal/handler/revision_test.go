@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHandler_History(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Revision{}, model.Todo{}, model.Tag{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Revision{}, model.Todo{}, model.Tag{}) })
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	call := func(t *testing.T, fn func(echo.Context) error, method, path, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/dummy/target", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, fn(c))
+		return rec
+	}
+	history := func(t *testing.T, id string) []model.Revision {
+		rec := call(t, handler.History, http.MethodGet, "/todos/:id/history", id, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data []model.Revision
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Data
+	}
+	todo := func(t *testing.T, rec *httptest.ResponseRecorder) model.Todo {
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data model.Todo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Data
+	}
+
+	id := strconv.Itoa(createTask(t, e, handler, `{"task":"Draft", "priority":1, "tags":["work"]}`))
+	call(t, handler.Update, http.MethodPut, "/todos/:id", id, `{"task":"Final", "status":"processing", "priority":1}`)
+	call(t, handler.Patch, http.MethodPatch, "/todos/:id", id, `{"task":"Final"}`)
+
+	t.Run("records_changes", func(t *testing.T) {
+		revisions := history(t, id)
+		require.Len(t, revisions, 2, "an update changing nothing is not recorded")
+
+		created := revisions[0]
+		assert.Equal(t, model.CreateAction, created.Action)
+		assert.False(t, created.CreatedAt.IsZero())
+		assert.JSONEq(t, `"Draft"`, string(created.Changes["Task"].After))
+		assert.JSONEq(t, `["work"]`, string(created.Changes["Tags"].After))
+
+		updated := revisions[1]
+		assert.Equal(t, model.UpdateAction, updated.Action)
+		assert.Len(t, updated.Changes, 3)
+		assert.JSONEq(t, `"Draft"`, string(updated.Changes["Task"].Before))
+		assert.JSONEq(t, `"Final"`, string(updated.Changes["Task"].After))
+		assert.JSONEq(t, `"created"`, string(updated.Changes["Status"].Before))
+		assert.JSONEq(t, `"processing"`, string(updated.Changes["Status"].After))
+		assert.JSONEq(t, `null`, string(updated.Changes["Tags"].After))
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		first := history(t, id)[0].ID
+		reverted := todo(t, call(t, handler.Revert, http.MethodPost, "/todos/:id/revert", id, fmt.Sprintf(`{"revision":%d}`, first)))
+		assert.Equal(t, "Draft", reverted.Task)
+		assert.Equal(t, model.Created, reverted.Status)
+		assert.Equal(t, []string{"work"}, reverted.Tags.Names())
+
+		found := todo(t, call(t, handler.Find, http.MethodGet, "/todos/:id", id, ""))
+		assert.Equal(t, "Draft", found.Task)
+		assert.Equal(t, []string{"work"}, found.Tags.Names())
+
+		revisions := history(t, id)
+		require.Len(t, revisions, 3)
+		last := revisions[2]
+		assert.Equal(t, model.RevertAction, last.Action)
+		require.NotNil(t, last.RevertedTo)
+		assert.Equal(t, first, *last.RevertedTo)
+		assert.JSONEq(t, `"Draft"`, string(last.Changes["Task"].After))
+	})
+
+	t.Run("revert_to_unknown_revision", func(t *testing.T) {
+		rec := call(t, handler.Revert, http.MethodPost, "/todos/:id/revert", id, `{"revision":999999}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = call(t, handler.Revert, http.MethodPost, "/todos/:id/revert", id, `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("delete_and_restore", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, call(t, handler.Delete, http.MethodDelete, "/todos/:id", id, "").Code)
+		revisions := history(t, id)
+		assert.Equal(t, model.DeleteAction, revisions[len(revisions)-1].Action)
+
+		rec := call(t, handler.Revert, http.MethodPost, "/todos/:id/revert", id, fmt.Sprintf(`{"revision":%d}`, revisions[0].ID))
+		assert.Equal(t, http.StatusNotFound, rec.Code, "a todo in the trash cannot be reverted")
+
+		require.Equal(t, http.StatusOK, call(t, handler.Restore, http.MethodPost, "/todos/:id/restore", id, "").Code)
+		revisions = history(t, id)
+		assert.Equal(t, model.RestoreAction, revisions[len(revisions)-1].Action)
+	})
+
+	t.Run("history_of_purged_todo", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, call(t, handler.Delete, http.MethodDelete, "/todos/:id", id, "").Code)
+		require.Equal(t, http.StatusNoContent, call(t, handler.Purge, http.MethodDelete, "/trash/:id", id, "").Code)
+		assert.Equal(t, http.StatusNotFound, call(t, handler.History, http.MethodGet, "/todos/:id/history", id, "").Code)
+
+		var n int64
+		require.NoError(t, dbInstance.Model(&model.Revision{}).Where("todo_id = ?", id).Count(&n).Error)
+		assert.Zero(t, n)
+	})
+}
@@ -1,14 +1,16 @@
 package handler
 
 import (
+	"github.com/fardinabir/todo-manager-app/internal/event"
+	"github.com/fardinabir/todo-manager-app/internal/model"
 	"github.com/fardinabir/todo-manager-app/internal/repository"
 	"github.com/fardinabir/todo-manager-app/internal/service"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
-// Register registers the routes for the application.
-func Register(e *echo.Echo, db *gorm.DB) {
+// Register registers the routes for the application, signing access tokens with the auth configuration.
+func Register(e *echo.Echo, db *gorm.DB, authConfig model.Auth) {
 	e.Validator = NewCustomValidator()
 
 	api := e.Group("/api/v1")
@@ -17,16 +19,105 @@ func Register(e *echo.Echo, db *gorm.DB) {
 	healthHandler := NewHealth()
 	api.GET("/healthz", healthHandler.Healthz)
 
+	// Auth
+	userRepository := repository.NewUser(db)
+	authService := service.NewAuth(userRepository, authConfig)
+	authHandler := NewAuth(authService)
+	auth := api.Group("/auth")
+	{
+		auth.POST("/register", authHandler.Register)
+		auth.POST("/login", authHandler.Login)
+	}
+	apiKeyRepository := repository.NewAPIKey(db)
+	apiKeyService := service.NewAPIKey(apiKeyRepository)
+	authenticate := Authenticate(service.NewCredential(authService, apiKeyService))
+
+	// API key
+	apiKeyHandler := NewAPIKey(apiKeyService)
+	apiKey := api.Group("/apikeys", authenticate, RequireSession)
+	{
+		apiKey.POST("", apiKeyHandler.Create)
+		apiKey.GET("", apiKeyHandler.FindAll)
+		apiKey.DELETE("/:id", apiKeyHandler.Revoke)
+	}
+
 	// Todo
-	repository := repository.NewTodo(db)
-	service := service.NewTodo(repository)
-	todoHandler := NewTodo(service)
-	todo := api.Group("/todos")
+	todoRepository := repository.NewTodo(db)
+	projectRepository := repository.NewProject(db)
+	webhookRepository := repository.NewWebhook(db)
+	todoService := service.NewTodo(todoRepository, projectRepository, webhookRepository)
+	todoHandler := NewTodo(todoService)
+	// The event streams are ended when the server shuts down, which would otherwise wait for them.
+	broker := event.NewBroker(todoService, event.Options{})
+	e.Server.RegisterOnShutdown(broker.Close)
+	eventsHandler := NewEvents(broker)
+	todo := api.Group("/todos", authenticate)
 	{
 		todo.POST("", todoHandler.Create)
 		todo.GET("", todoHandler.FindAll)
 		todo.GET("/:id", todoHandler.Find)
+		todo.GET("/:id/children", todoHandler.Children)
+		todo.GET("/:id/subtree", todoHandler.Subtree)
 		todo.PUT("/:id", todoHandler.Update)
+		todo.PATCH("/:id", todoHandler.Patch)
 		todo.DELETE("/:id", todoHandler.Delete)
+		todo.POST("/:id/restore", todoHandler.Restore)
+		todo.GET("/:id/history", todoHandler.History)
+		todo.POST("/:id/revert", todoHandler.Revert)
+	}
+	// The colon is escaped so that echo matches it literally instead of as a path parameter.
+	api.POST("/todos\\:batch", todoHandler.Batch, authenticate)
+	api.GET("/todos/events", eventsHandler.Stream, queryToken, authenticate)
+	wsHandler := NewWS(todoService, broker)
+	api.GET("/ws", wsHandler.Connect, queryToken, authenticate)
+	graphQLHandler := NewGraphQL(todoService)
+	api.POST("/graphql", graphQLHandler.Query, authenticate)
+	api.GET("/graphql", graphQLHandler.Query, authenticate)
+	api.GET("/todos.ics", todoHandler.Calendar, authenticate)
+	api.POST("/todos.ics", todoHandler.ImportCalendar, authenticate)
+	trash := api.Group("/trash", authenticate)
+	{
+		trash.GET("", todoHandler.Trash)
+		trash.DELETE("", todoHandler.EmptyTrash)
+		trash.DELETE("/:id", todoHandler.Purge)
+	}
+
+	// Tag
+	tagRepository := repository.NewTag(db)
+	tagService := service.NewTag(tagRepository)
+	tagHandler := NewTag(tagService)
+	tag := api.Group("/tags", authenticate)
+	{
+		tag.POST("", tagHandler.Create)
+		tag.GET("", tagHandler.FindAll)
+		tag.GET("/:id", tagHandler.Find)
+		tag.PUT("/:id", tagHandler.Update)
+		tag.DELETE("/:id", tagHandler.Delete)
+	}
+
+	// Project
+	projectService := service.NewProject(projectRepository, todoService)
+	projectHandler := NewProject(projectService)
+	project := api.Group("/projects", authenticate)
+	{
+		project.POST("", projectHandler.Create)
+		project.GET("", projectHandler.FindAll)
+		project.GET("/:id", projectHandler.Find)
+		project.GET("/:id/todos", projectHandler.Todos)
+		project.PUT("/:id", projectHandler.Update)
+		project.DELETE("/:id", projectHandler.Delete)
+	}
+
+	// Webhook
+	webhookService := service.NewWebhook(webhookRepository)
+	webhookHandler := NewWebhook(webhookService)
+	webhook := api.Group("/webhooks", authenticate)
+	{
+		webhook.POST("", webhookHandler.Create)
+		webhook.GET("", webhookHandler.FindAll)
+		webhook.GET("/:id", webhookHandler.Find)
+		webhook.DELETE("/:id", webhookHandler.Delete)
+		webhook.GET("/:id/deliveries", webhookHandler.Deliveries)
+		webhook.POST("/:id/deliveries/:delivery_id/replay", webhookHandler.Replay)
 	}
 }
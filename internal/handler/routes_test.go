@@ -18,7 +18,8 @@ func TestRegister(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
-	Register(e, dbInstance)
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "routes@example.com")
 
 	// Test cases
 	tests := []struct {
@@ -30,14 +31,77 @@ func TestRegister(t *testing.T) {
 		{"Health_Check", http.MethodGet, "/api/v1/healthz", http.StatusOK},
 		{"Create_Todo_without_body", http.MethodPost, "/api/v1/todos", http.StatusBadRequest}, // Assuming no body is sent, should return BadRequest
 		{"Get_all_Todos", http.MethodGet, "/api/v1/todos", http.StatusOK},
-		{"Get_non-existent_Todo", http.MethodGet, "/api/v1/todos/1", http.StatusNotFound},       // Assuming no todo with id 1 exists
-		{"Update_Todo_without_body", http.MethodPut, "/api/v1/todos/1", http.StatusNotFound},    // Assuming no body is sent, should return BadRequest
+		{"Get_non-existent_Todo", http.MethodGet, "/api/v1/todos/1", http.StatusNotFound},      // Assuming no todo with id 1 exists
+		{"Update_Todo_without_body", http.MethodPut, "/api/v1/todos/1", http.StatusBadRequest}, // A replacement without body misses the required fields
+		{"Patch_Todo_without_merge_patch", http.MethodPatch, "/api/v1/todos/1", http.StatusUnsupportedMediaType},
+		{"Get_Todos_calendar", http.MethodGet, "/api/v1/todos.ics", http.StatusOK},
+		{"Import_Todos_calendar_without_body", http.MethodPost, "/api/v1/todos.ics", http.StatusUnsupportedMediaType},
+		{"Batch_Todos_without_body", http.MethodPost, "/api/v1/todos:batch", http.StatusBadRequest},
 		{"Delete_non-existent_Todo", http.MethodDelete, "/api/v1/todos/1", http.StatusNotFound}, // Assuming no todo with id 1 exists
+		{"Get_children_of_non-existent_Todo", http.MethodGet, "/api/v1/todos/1/children", http.StatusNotFound},
+		{"Get_subtree_of_non-existent_Todo", http.MethodGet, "/api/v1/todos/1/subtree", http.StatusNotFound},
+		{"Create_Tag_without_body", http.MethodPost, "/api/v1/tags", http.StatusBadRequest},
+		{"Get_all_Tags", http.MethodGet, "/api/v1/tags", http.StatusOK},
+		{"Get_non-existent_Tag", http.MethodGet, "/api/v1/tags/-1", http.StatusNotFound},
+		{"Create_Project_without_body", http.MethodPost, "/api/v1/projects", http.StatusBadRequest},
+		{"Get_all_Projects", http.MethodGet, "/api/v1/projects", http.StatusOK},
+		{"Get_todos_of_non-existent_Project", http.MethodGet, "/api/v1/projects/-1/todos", http.StatusNotFound},
+		{"Get_Trash", http.MethodGet, "/api/v1/trash", http.StatusOK},
+		{"Restore_non-existent_Todo", http.MethodPost, "/api/v1/todos/-1/restore", http.StatusNotFound},
+		{"Get_history_of_non-existent_Todo", http.MethodGet, "/api/v1/todos/-1/history", http.StatusNotFound},
+		{"Get_all_APIKeys", http.MethodGet, "/api/v1/apikeys", http.StatusOK},
+		{"Revoke_non-existent_APIKey", http.MethodDelete, "/api/v1/apikeys/-1", http.StatusNotFound},
+		{"Create_Webhook_without_body", http.MethodPost, "/api/v1/webhooks", http.StatusBadRequest},
+		{"Get_all_Webhooks", http.MethodGet, "/api/v1/webhooks", http.StatusOK},
+		{"GraphQL_without_query", http.MethodPost, "/api/v1/graphql", http.StatusBadRequest},
+		{"Get_deliveries_of_non-existent_Webhook", http.MethodGet, "/api/v1/webhooks/-1/deliveries", http.StatusNotFound},
+		{"Replay_non-existent_Delivery", http.MethodPost, "/api/v1/webhooks/-1/deliveries/-1/replay", http.StatusNotFound},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestRegister_Authentication(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	Register(e, dbInstance, testAuthConfig)
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		authorization string
+		expectedCode  int
+	}{
+		{"Health_Check_is_public", http.MethodGet, "/api/v1/healthz", "", http.StatusOK},
+		{"Todos_without_token", http.MethodGet, "/api/v1/todos", "", http.StatusUnauthorized},
+		{"Todos_with_malformed_token", http.MethodGet, "/api/v1/todos", "Bearer nope", http.StatusUnauthorized},
+		{"Todos_with_basic_auth", http.MethodGet, "/api/v1/todos", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"Projects_without_token", http.MethodGet, "/api/v1/projects", "", http.StatusUnauthorized},
+		{"Tags_without_token", http.MethodGet, "/api/v1/tags", "", http.StatusUnauthorized},
+		{"Webhooks_without_token", http.MethodGet, "/api/v1/webhooks", "", http.StatusUnauthorized},
+		{"WebSocket_without_token", http.MethodGet, "/api/v1/ws", "", http.StatusUnauthorized},
+		{"GraphQL_without_token", http.MethodPost, "/api/v1/graphql", "", http.StatusUnauthorized},
+		{"Login_without_body", http.MethodPost, "/api/v1/auth/login", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
 			rec := httptest.NewRecorder()
 			e.ServeHTTP(rec, req)
 			assert.Equal(t, tt.expectedCode, rec.Code)
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// TagHandler is the request handler for the tag endpoint.
+type TagHandler interface {
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	Find(c echo.Context) error
+	FindAll(c echo.Context) error
+}
+
+type tagHandler struct {
+	Handler
+	service service.Tag
+}
+
+// NewTag returns a new instance of the tag handler.
+func NewTag(s service.Tag) TagHandler {
+	return &tagHandler{service: s}
+}
+
+// CreateTagRequest is the request parameter for creating a new tag
+type CreateTagRequest struct {
+	Name string `json:"name" validate:"required,validTagName"`
+}
+
+// @Summary	Create a new tag
+// @Tags		tags
+// @Security	BearerAuth
+// @Accept		json
+// @Produce	json
+// @Param		request	body		CreateTagRequest	true	"json"
+// @Success	201		{object}	ResponseData{data=model.Tag}
+// @Failure	400		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/tags [post]
+func (t *tagHandler) Create(c echo.Context) error {
+	var req CreateTagRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	tag, err := t.service.ForOwner(currentUser(c)).Create(req.Name)
+	if err != nil {
+		if err == model.ErrDuplicate {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "tag already exists"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: tag})
+}
+
+// UpdateTagRequest is the request parameter for renaming a tag
+type UpdateTagRequest struct {
+	ID   int    `param:"id" validate:"required" swaggerignore:"true"`
+	Name string `json:"name" validate:"required,validTagName"`
+}
+
+// @Summary	Rename a tag
+// @Tags		tags
+// @Security	BearerAuth
+// @Accept		json
+// @Produce	json
+// @Param		id		path		int					true	"Tag ID"
+// @Param		body	body		UpdateTagRequest	true	"body"
+// @Success	200		{object}	ResponseData{Data=model.Tag}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	409		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/tags/{id} [put]
+func (t *tagHandler) Update(c echo.Context) error {
+	var req UpdateTagRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	tag, err := t.service.ForOwner(currentUser(c)).Update(req.ID, req.Name)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "tag not found"}}})
+		}
+		if err == model.ErrDuplicate {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: "tag already exists"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusOK, ResponseData{Data: tag})
+}
+
+// TagRequest is the request parameter for finding or deleting a tag
+type TagRequest struct {
+	ID int `param:"id" validate:"required"`
+}
+
+// @Summary		Delete a tag
+// @Description	Deleting a tag removes it from every todo.
+// @Tags			tags
+// @Security		BearerAuth
+// @Param			path	path	TagRequest	false	"path"
+// @Success		204
+// @Failure		400	{object}	ResponseError
+// @Failure		404	{object}	ResponseError
+// @Failure		500	{object}	ResponseError
+// @Router			/tags/{id} [delete]
+func (t *tagHandler) Delete(c echo.Context) error {
+	var req TagRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := t.service.ForOwner(currentUser(c)).Delete(req.ID); err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "tag not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Find a tag
+// @Tags		tags
+// @Security	BearerAuth
+// @Param		path	path		TagRequest	false	"path"
+// @Success	200		{object}	ResponseData{Data=model.Tag}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/tags/{id} [get]
+func (t *tagHandler) Find(c echo.Context) error {
+	var req TagRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := t.service.ForOwner(currentUser(c)).Find(req.ID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "tag not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// @Summary	Find all tags
+// @Tags		tags
+// @Security	BearerAuth
+// @Success	200	{object}	ResponseData{Data=[]model.Tag}
+// @Failure	500	{object}	ResponseError
+// @Router		/tags [get]
+func (t *tagHandler) FindAll(c echo.Context) error {
+	res, err := t.service.ForOwner(currentUser(c)).FindAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagHandler_Create(t *testing.T) {
+	type want struct {
+		StatusCode int
+		Response   []byte
+	}
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Tag{})
+	repository := repository.NewTag(dbInstance)
+	service := service.NewTag(repository)
+	handler := NewTag(service)
+
+	tests := []struct {
+		name       string
+		createBody string
+		want       want
+	}{
+		{
+			name:       "successful_create",
+			createBody: `{"name":"Backend"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"Name":"backend"}}`),
+			},
+		},
+		{
+			name:       "duplicate_name",
+			createBody: `{"name":" backend "}`,
+			want: want{
+				StatusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:       "invalid_name",
+			createBody: `{"name":"two words"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:       "missing_name",
+			createBody: `{}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Prepare
+			req := httptest.NewRequest(http.MethodPost, "/dummy/target", bytes.NewReader([]byte(tt.createBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/tags")
+
+			// Execute
+			require.NoError(t, handler.Create(c))
+
+			// Assert
+			assert.Equal(t, tt.want.StatusCode, rec.Code)
+
+			if tt.want.Response == nil {
+				return
+			}
+			got := rec.Body.Bytes()
+
+			opts := []cmp.Option{
+				cmpTransformJSON(t),
+				ignoreMapEntires(map[string]any{"CreatedAt": 1, "UpdatedAt": 1, "ID": 1}),
+			}
+			if diff := cmp.Diff(got, tt.want.Response, opts...); diff != "" {
+				t.Errorf("return value mismatch (-got +want):\n%s", diff)
+				t.Logf("got:\n%s", string(got))
+			}
+		})
+	}
+}
+
+func TestTagHandler_UpdateDelete(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Tag{})
+	repository := repository.NewTag(dbInstance)
+	service := service.NewTag(repository)
+	handler := NewTag(service)
+
+	call := func(t *testing.T, fn func(echo.Context) error, method, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/dummy/target", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/tags/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, fn(c))
+		return rec
+	}
+
+	tag, err := service.Create("work")
+	require.NoError(t, err)
+	_, err = service.Create("home")
+	require.NoError(t, err)
+	id := strconv.Itoa(tag.ID)
+
+	rec := call(t, handler.Update, http.MethodPut, id, `{"name":"Office"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var res struct {
+		Data model.Tag
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "office", res.Data.Name)
+	assert.False(t, res.Data.CreatedAt.IsZero())
+
+	rec = call(t, handler.Update, http.MethodPut, id, `{"name":"home"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = call(t, handler.Update, http.MethodPut, "-1", `{"name":"other"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = call(t, handler.Find, http.MethodGet, id, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = call(t, handler.Delete, http.MethodDelete, id, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = call(t, handler.Find, http.MethodGet, id, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = call(t, handler.Delete, http.MethodDelete, "invalid", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTagHandler_Owners(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Tag{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}) })
+	Register(e, dbInstance, testAuthConfig)
+	alice := login(t, e, "alice.tags@example.com")
+	bob := login(t, e, "bob.tags@example.com")
+
+	call := func(token, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	tags := func(token string) []model.Tag {
+		rec := call(token, http.MethodGet, "/api/v1/tags", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data []model.Tag
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Data
+	}
+
+	// Both users tag a todo "work", each getting a tag of their own.
+	for _, token := range []string{alice, bob} {
+		rec := call(token, http.MethodPost, "/api/v1/todos", `{"task":"Report", "priority":1, "tags":["work"]}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	aliceTags, bobTags := tags(alice), tags(bob)
+	require.Len(t, aliceTags, 1)
+	require.Len(t, bobTags, 1)
+	assert.NotEqual(t, aliceTags[0].ID, bobTags[0].ID)
+	assert.Equal(t, http.StatusCreated, call(bob, http.MethodPost, "/api/v1/tags", `{"name":"home"}`).Code)
+	assert.Equal(t, http.StatusCreated, call(alice, http.MethodPost, "/api/v1/tags", `{"name":"home"}`).Code)
+
+	aliceID := strconv.Itoa(aliceTags[0].ID)
+	assert.Equal(t, http.StatusNotFound, call(bob, http.MethodGet, "/api/v1/tags/"+aliceID, "").Code)
+	assert.Equal(t, http.StatusNotFound, call(bob, http.MethodPut, "/api/v1/tags/"+aliceID, `{"name":"stolen"}`).Code)
+	assert.Equal(t, http.StatusNotFound, call(bob, http.MethodDelete, "/api/v1/tags/"+aliceID, "").Code)
+
+	// Deleting a tag leaves the todos of other users tagged.
+	require.Equal(t, http.StatusNoContent, call(bob, http.MethodDelete, "/api/v1/tags/"+strconv.Itoa(bobTags[0].ID), "").Code)
+	rec := call(alice, http.MethodGet, "/api/v1/todos?tag=work", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var todos struct {
+		Data []model.Todo
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &todos))
+	require.Len(t, todos.Data, 1)
+	assert.Equal(t, []string{"work"}, todos.Data[0].Tags.Names())
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/fardinabir/todo-manager-app/internal/errors"
 	"github.com/fardinabir/todo-manager-app/internal/model"
@@ -16,6 +19,18 @@ type TodoHandler interface {
 	Delete(c echo.Context) error
 	Find(c echo.Context) error
 	FindAll(c echo.Context) error
+	Children(c echo.Context) error
+	Subtree(c echo.Context) error
+	Trash(c echo.Context) error
+	Restore(c echo.Context) error
+	Purge(c echo.Context) error
+	EmptyTrash(c echo.Context) error
+	History(c echo.Context) error
+	Revert(c echo.Context) error
+	Patch(c echo.Context) error
+	Batch(c echo.Context) error
+	Calendar(c echo.Context) error
+	ImportCalendar(c echo.Context) error
 }
 
 type todoHandler struct {
@@ -30,19 +45,36 @@ func NewTodo(s service.Todo) TodoHandler {
 
 // CreateRequest is the request parameter for creating a new todo
 type CreateRequest struct {
-	Task     string         `json:"task" validate:"required"`
-	Priority model.Priority `json:"priority" validate:"required,validPriority"`
+	Task         string         `json:"task" validate:"required"`
+	Priority     model.Priority `json:"priority" validate:"required,validPriority"`
+	DueAt        *time.Time     `json:"due_at,omitempty" validate:"required_with=Recurrence"`
+	ScheduledFor *time.Time     `json:"scheduled_for,omitempty"`
+	// Recurrence is an RFC 5545 RRULE value repeating the todo from its due date, e.g. "FREQ=WEEKLY;BYDAY=MO".
+	Recurrence string `json:"recurrence,omitempty" validate:"validRecurrence"`
+	// ParentID makes the todo a subtask of another todo.
+	ParentID *int `json:"parent_id,omitempty"`
+	// Tags are the names of the labels of the todo, created when missing.
+	Tags []string `json:"tags,omitempty" validate:"omitempty,dive,validTagName"`
+	// ProjectID puts the todo into a project.
+	ProjectID *int `json:"project_id,omitempty"`
 }
 
-// @Summary	Create a new todo
-// @Tags		todos
-// @Accept		json
-// @Produce	json
-// @Param		request	body		CreateRequest	true	"json"
-// @Success	201		{object}	ResponseError{data=model.Todo}
-// @Failure	400		{object}	ResponseError
-// @Failure	500		{object}	ResponseError
-// @Router		/todos [post]
+// Details returns the optional attributes of the todo to create.
+func (r CreateRequest) Details() model.TodoDetails {
+	return model.TodoDetails{DueAt: r.DueAt, ScheduledFor: r.ScheduledFor, Recurrence: r.Recurrence, ParentID: r.ParentID, Tags: r.Tags, ProjectID: r.ProjectID}
+}
+
+// @Summary		Create a new todo
+// @Description	A todo with a recurrence is the first occurrence of a series. Marking an occurrence done creates the next one, due at the following slot of the rule.
+// @Tags			todos
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			request	body	CreateRequest	true	"json"
+// @Success		201	{object}	ResponseError{data=model.Todo}
+// @Failure		400	{object}	ResponseError
+// @Failure		500	{object}	ResponseError
+// @Router			/todos [post]
 func (t *todoHandler) Create(c echo.Context) error {
 	var req CreateRequest
 	if err := t.MustBind(c, &req); err != nil {
@@ -50,8 +82,12 @@ func (t *todoHandler) Create(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	todo, err := t.service.Create(req.Task, req.Priority)
+	todo, err := t.service.ForOwner(currentUser(c)).Create(req.Task, req.Priority, req.Details())
 	if err != nil {
+		if err == model.ErrRecurrenceWithoutDueDate || err == model.ErrParentNotFound || err == model.ErrProjectNotFound {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -65,11 +101,36 @@ type UpdateRequest struct {
 	UpdateRequestPath
 }
 
-// UpdateRequestBody is the request body for updating a todo
+// UpdateRequestBody is the request body for replacing a todo
 type UpdateRequestBody struct {
-	Task     string         `json:"task,omitempty"`
-	Status   model.Status   `json:"status,omitempty" validate:"validStatus"`
-	Priority model.Priority `json:"priority,omitempty" validate:"validPriority"`
+	Task         string         `json:"task,omitempty" validate:"required"`
+	Status       model.Status   `json:"status,omitempty" validate:"required,validStatus"`
+	Priority     model.Priority `json:"priority,omitempty" validate:"required,validPriority"`
+	DueAt        *time.Time     `json:"due_at,omitempty"`
+	ScheduledFor *time.Time     `json:"scheduled_for,omitempty"`
+	// Recurrence is an RFC 5545 RRULE value, making the todo repeat or changing the rule of its series.
+	// Left out, the series of a recurring todo is kept.
+	Recurrence string `json:"recurrence,omitempty" validate:"validRecurrence"`
+	// Scope selects whether a recurring todo is edited as this occurrence only or for all future occurrences.
+	Scope model.EditScope `json:"scope,omitempty" validate:"omitempty,oneof=this future"`
+	// ParentID moves the todo under another todo.
+	ParentID *int `json:"parent_id,omitempty"`
+	// Force marks the todo done even though some of its subtasks are still open.
+	Force bool `json:"force,omitempty"`
+	// Tags are the names of the labels of the todo, created when missing.
+	Tags []string `json:"tags,omitempty" validate:"omitempty,dive,validTagName"`
+	// ProjectID moves the todo into another project.
+	ProjectID *int `json:"project_id,omitempty"`
+}
+
+// Options returns how the update is applied.
+func (r UpdateRequestBody) Options() model.UpdateOptions {
+	return model.UpdateOptions{Scope: r.Scope, Force: r.Force}
+}
+
+// Details returns the optional attributes of the todo to update.
+func (r UpdateRequestBody) Details() model.TodoDetails {
+	return model.TodoDetails{DueAt: r.DueAt, ScheduledFor: r.ScheduledFor, Recurrence: r.Recurrence, ParentID: r.ParentID, Tags: r.Tags, ProjectID: r.ProjectID}
 }
 
 // UpdateRequestPath is the request parameter for updating a todo
@@ -77,16 +138,25 @@ type UpdateRequestPath struct {
 	ID int `param:"id" validate:"required"`
 }
 
-// @Summary	Update a todo
-// @Tags		todos
-// @Accept		json
-// @Produce	json
-// @Param		body	body		UpdateRequestBody	true	"body"
-// @Param		path	path		UpdateRequestPath	false	"path"
-// @Success	201		{object}	ResponseData{Data=model.Todo}
-// @Failure	400		{object}	ResponseError
-// @Failure	500		{object}	ResponseError
-// @Router		/todos/{id} [put]
+// @Summary		Replace a todo
+// @Description	The todo is replaced as a whole: due_at, scheduled_for, parent_id, project_id and tags left out of the body are cleared. Use PATCH to change some fields only.
+// @Description	For a recurring todo, scope "this" (default) edits the occurrence only while "future" also applies task, priority and recurrence to the occurrences generated after it.
+// @Description	A todo cannot be marked done while some of its subtasks are open unless force is set.
+// @Tags			todos
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			body		body		UpdateRequestBody	true	"body"
+// @Param			path		path		UpdateRequestPath	false	"path"
+// @Param			If-Match	header		string				false	"ETag of the todo as last read, the update fails with 412 if the todo changed since"
+// @Success		201			{object}	ResponseData{Data=model.Todo}
+// @Header			201			{string}	ETag	"Version of the updated todo"
+// @Failure		400			{object}	ResponseError
+// @Failure		404			{object}	ResponseError
+// @Failure		409			{object}	ResponseError
+// @Failure		412			{object}	ResponseError
+// @Failure		500			{object}	ResponseError
+// @Router			/todos/{id} [put]
 func (t *todoHandler) Update(c echo.Context) error {
 	var req UpdateRequest
 	if err := t.MustBind(c, &req); err != nil {
@@ -94,32 +164,70 @@ func (t *todoHandler) Update(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	todo, err := t.service.Update(req.ID, req.Task, req.Priority, req.Status)
+	precondition, err := ifMatch(c)
 	if err != nil {
-		if err == model.ErrNotFound {
-			return c.JSON(http.StatusNotFound,
-				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found"}}})
-		}
-		return c.JSON(http.StatusInternalServerError,
-			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
+	opts := req.Options()
+	opts.IfMatch = precondition
 
+	todo, err := t.service.ForOwner(currentUser(c)).Update(req.ID, req.Task, req.Priority, req.Status, req.Details(), opts)
+	if err != nil {
+		return updateError(c, err)
+	}
+
+	setETag(c, todo)
 	return c.JSON(http.StatusOK, ResponseData{Data: todo})
 }
 
+// updateError responds with the status matching an error of a todo update.
+func updateError(c echo.Context, err error) error {
+	status, e := todoError(err)
+	return c.JSON(status, ResponseError{Errors: []Error{e}})
+}
+
+// todoError returns the status and error matching an error of a change to a todo.
+func todoError(err error) (int, Error) {
+	if err == model.ErrNotFound {
+		return http.StatusNotFound, Error{Code: errors.CodeNotFound, Message: "todo not found"}
+	}
+	if err == model.ErrVersionMismatch {
+		return http.StatusPreconditionFailed, Error{Code: errors.CodePreconditionFailed, Message: err.Error()}
+	}
+	if err == model.ErrRecurrenceWithoutDueDate || err == model.ErrRecurrenceScope ||
+		err == model.ErrParentNotFound || err == model.ErrParentCycle || err == model.ErrProjectNotFound {
+		return http.StatusBadRequest, Error{Code: errors.CodeBadRequest, Message: err.Error()}
+	}
+	if err == model.ErrOpenChildren || err == model.ErrHasChildren {
+		return http.StatusConflict, Error{Code: errors.CodeConflict, Message: err.Error()}
+	}
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code, Error{Code: errors.CodeBadRequest, Message: fmt.Sprint(he.Message)}
+	}
+	return http.StatusInternalServerError, Error{Code: errors.CodeInternalServerError, Message: err.Error()}
+}
+
 // DeleteRequest is the request parameter for deleting a todo
 type DeleteRequest struct {
-	ID int `param:"id" validate:"required"`
+	ID   int              `param:"id" validate:"required"`
+	Mode model.DeleteMode `query:"mode" validate:"omitempty,oneof=refuse cascade reparent" swaggerignore:"true"`
 }
 
-// @Summary	Delete a todo
-// @Tags		todos
-// @Param		path	path	DeleteRequest	false	"path"
-// @Success	204
-// @Failure	400	{object}	ResponseError
-// @Failure	404	{object}	ResponseError
-// @Failure	500	{object}	ResponseError
-// @Router		/todos/{id} [delete]
+// @Summary		Delete a todo
+// @Description	The todo moves to the trash, from which it can be restored until it is purged.
+// @Tags			todos
+// @Security		BearerAuth
+// @Param			path	path	DeleteRequest	false	"path"
+// @Param			mode	query	string			false	"What happens to subtasks: refuse (default) fails with 409, cascade deletes them, reparent moves them to the deleted todo's parent"	Enums(refuse, cascade, reparent)
+// @Param			If-Match	header	string		false	"ETag of the todo as last read, the delete fails with 412 if the todo changed since"
+// @Success		204
+// @Failure		400	{object}	ResponseError
+// @Failure		404	{object}	ResponseError
+// @Failure		409	{object}	ResponseError
+// @Failure		412	{object}	ResponseError
+// @Failure		500	{object}	ResponseError
+// @Router			/todos/{id} [delete]
 func (t *todoHandler) Delete(c echo.Context) error {
 	var req DeleteRequest
 	if err := t.MustBind(c, &req); err != nil {
@@ -127,11 +235,25 @@ func (t *todoHandler) Delete(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	if err := t.service.Delete(req.ID); err != nil {
+	precondition, err := ifMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := t.service.ForOwner(currentUser(c)).Delete(req.ID, req.Mode, precondition); err != nil {
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
 				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found"}}})
 		}
+		if err == model.ErrVersionMismatch {
+			return c.JSON(http.StatusPreconditionFailed,
+				ResponseError{Errors: []Error{{Code: errors.CodePreconditionFailed, Message: err.Error()}}})
+		}
+		if err == model.ErrHasChildren {
+			return c.JSON(http.StatusConflict,
+				ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: err.Error()}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
@@ -145,8 +267,10 @@ type FindRequest struct {
 
 // @Summary	Find a todo
 // @Tags		todos
+// @Security	BearerAuth
 // @Param		path	path		FindRequest	false	"path"
 // @Success	200		{object}	ResponseData{Data=model.Todo}
+// @Header		200		{string}	ETag	"Version of the todo, to send back as If-Match"
 // @Failure	400		{object}	ResponseError
 // @Failure	404		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
@@ -158,7 +282,7 @@ func (t *todoHandler) Find(c echo.Context) error {
 			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
 	}
 
-	res, err := t.service.Find(req.ID)
+	res, err := t.service.ForOwner(currentUser(c)).Find(req.ID)
 	if err != nil {
 		if err == model.ErrNotFound {
 			return c.JSON(http.StatusNotFound,
@@ -167,22 +291,157 @@ func (t *todoHandler) Find(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
+	setETag(c, res)
 	return c.JSON(http.StatusOK, ResponseData{Data: res})
 }
 
-// @Summary	Find all todos
+// @Summary	List the direct subtasks of a todo
 // @Tags		todos
-// @Param		task	query		string	false	"Filter by task name"
-// @Param		status	query		string	false	"Filter by task status"
+// @Security	BearerAuth
+// @Param		path	path		FindRequest	false	"path"
 // @Success	200		{object}	ResponseData{Data=[]model.Todo}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
 // @Failure	500		{object}	ResponseError
-// @Router		/todos [get]
-func (t *todoHandler) FindAll(c echo.Context) error {
-	params := c.QueryParams()
-	res, err := t.service.FindAll(params)
+// @Router		/todos/{id}/children [get]
+func (t *todoHandler) Children(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := t.service.ForOwner(currentUser(c)).Children(req.ID)
 	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// @Summary	Find a todo with all of its nested subtasks
+// @Tags		todos
+// @Security	BearerAuth
+// @Param		path	path		FindRequest	false	"path"
+// @Success	200		{object}	ResponseData{Data=model.TodoNode}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/todos/{id}/subtree [get]
+func (t *todoHandler) Subtree(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := t.service.ForOwner(currentUser(c)).Subtree(req.ID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found"}}})
+		}
 		return c.JSON(http.StatusInternalServerError,
 			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
 	}
 	return c.JSON(http.StatusOK, ResponseData{Data: res})
 }
+
+// FindAllRequest is the request parameter for listing todos
+type FindAllRequest struct {
+	Limit     int        `query:"limit" validate:"omitempty,min=1,max=200"`
+	Cursor    string     `query:"cursor"`
+	WithTotal bool       `query:"with_total"`
+	Sort      string     `query:"sort"`
+	DueBefore *time.Time `query:"due_before"`
+	DueAfter  *time.Time `query:"due_after"`
+	Overdue   *bool      `query:"overdue"`
+	Tag       []string   `query:"tag"`
+	TagAny    []string   `query:"tag_any"`
+	TagNone   []string   `query:"tag_none"`
+	ProjectID *int       `query:"project_id"`
+}
+
+// @Summary		Find all todos
+// @Description	Responds with the todos as the lines of a todo.txt file when text/plain is preferred by the Accept header, the next page is then linked from the Link header.
+// @Tags			todos
+// @Security		BearerAuth
+// @Produce		json,plain
+// @Param			q			query		string	false	"Full-text search on task text (FTS5 syntax: phrases, prefix*, AND/OR/NOT), ranked by relevance with a highlighted Snippet"
+// @Param			task		query		string	false	"Filter by task name"
+// @Param			status		query		string	false	"Filter by task status"
+// @Param			limit		query		int		false	"Page size (default 50, max 200)"
+// @Param			cursor		query		string	false	"Opaque cursor taken from meta.next_cursor of the previous page"
+// @Param			with_total	query		bool	false	"Include the total number of matching todos in meta.total"
+// @Param			sort		query		string	false	"Comma separated sort fields, prefixed with - for descending order (id, task, status, priority, created_at, updated_at, due_at, scheduled_for). Defaults to overdue todos first, then -priority,-created_at"
+// @Param			due_before	query		string	false	"Only todos due before this RFC 3339 time"
+// @Param			due_after	query		string	false	"Only todos due after this RFC 3339 time"
+// @Param			overdue		query		bool	false	"Only overdue (true) or not overdue (false) todos"
+// @Param			tag			query		string	false	"Only todos carrying all of these comma separated tags"
+// @Param			tag_any		query		string	false	"Only todos carrying at least one of these comma separated tags"
+// @Param			tag_none	query		string	false	"Only todos carrying none of these comma separated tags"
+// @Param			project_id	query		int		false	"Only todos of this project. Without it, todos of archived projects are hidden"
+// @Success		200			{object}	ResponseData{Data=[]model.Todo}
+// @Failure		400			{object}	ResponseError
+// @Failure		500			{object}	ResponseError
+// @Router			/todos [get]
+func (t *todoHandler) FindAll(c echo.Context) error {
+	var req FindAllRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	s := t.service.ForOwner(currentUser(c))
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	if preferredMediaType(c, echo.MIMEApplicationJSON, echo.MIMETextPlain) == echo.MIMETextPlain {
+		return listTodoTxt(c, req, s)
+	}
+	return listTodos(c, req, func(page model.PageRequest) (*model.TodoPage, error) {
+		return s.FindAll(c.QueryParams(), page)
+	})
+}
+
+// listTodos responds with the page of todos returned by find for the pagination of req.
+func listTodos(c echo.Context, req FindAllRequest, find func(page model.PageRequest) (*model.TodoPage, error)) error {
+	sort, err := model.ParseSort(req.Sort)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	page := model.NewPageRequest(req.Limit, req.Cursor, req.WithTotal, sort)
+	res, err := find(page)
+	if err != nil {
+		if err == model.ErrInvalidCursor || err == model.ErrInvalidSearchQuery {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{
+		Data:  res.Todos,
+		Meta:  &Meta{Limit: page.Limit, NextCursor: res.NextCursor, Total: res.Total},
+		Links: nextLinks(c, res.NextCursor),
+	})
+}
+
+// nextLinks returns the links pointing to the page after the current one.
+func nextLinks(c echo.Context, nextCursor string) *Links {
+	if nextCursor == "" {
+		return nil
+	}
+	next := url.URL{Path: c.Request().URL.Path}
+	params := url.Values{}
+	for k, v := range c.QueryParams() {
+		params[k] = v
+	}
+	params.Set("cursor", nextCursor)
+	next.RawQuery = params.Encode()
+	return &Links{Next: next.String()}
+}
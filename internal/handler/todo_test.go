@@ -3,10 +3,13 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/fardinabir/todo-manager-app/internal/db"
 	"github.com/fardinabir/todo-manager-app/internal/model"
@@ -31,8 +34,10 @@ func TestTodoHandler_Create(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
 	repository := repository.NewTodo(dbInstance)
-	service := service.NewTodo(repository)
+	service := service.NewTodo(repository, projects, webhooks)
 	handler := NewTodo(service)
 
 	tests := []struct {
@@ -71,6 +76,21 @@ func TestTodoHandler_Create(t *testing.T) {
 				Response:   []byte(`{"data":{"Task":"Created Task", "Priority":2, "Status":"created"}}`),
 			},
 		},
+		{
+			name:       "successful_create_with_dates",
+			createBody: `{"task":"Created Task", "priority":2, "due_at":"2030-01-02T15:04:05+09:00", "scheduled_for":"2030-01-01T09:00:00Z"}`,
+			want: want{
+				StatusCode: http.StatusCreated,
+				Response:   []byte(`{"data":{"Task":"Created Task", "Priority":2, "Status":"created", "DueAt":"2030-01-02T06:04:05Z", "ScheduledFor":"2030-01-01T09:00:00Z"}}`),
+			},
+		},
+		{
+			name:       "create_with_invalid_due_at",
+			createBody: `{"task":"Created Task", "priority":2, "due_at":"tomorrow"}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name:       "invalid_request_body",
 			createBody: `{"task":1}`,
@@ -124,8 +144,10 @@ func TestTodoHandler_Update(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
 	repository := repository.NewTodo(dbInstance)
-	service := service.NewTodo(repository)
+	service := service.NewTodo(repository, projects, webhooks)
 	handler := NewTodo(service)
 
 	tests := []struct {
@@ -139,7 +161,7 @@ func TestTodoHandler_Update(t *testing.T) {
 		{
 			name:       "successful_update",
 			createBody: `{"task":"Updated Task", "priority":1}`,
-			updateBody: `{"task":"Updated Task","status":"done"}`,
+			updateBody: `{"task":"Updated Task","status":"done","priority":1}`,
 			want: want{
 				StatusCode: http.StatusOK,
 				Response:   []byte(`{"data":{"Task":"Updated Task","Status":"done", "Priority":1}}`),
@@ -155,12 +177,21 @@ func TestTodoHandler_Update(t *testing.T) {
 			},
 		},
 		{
-			name:       "successful_update_with_only_priority",
+			name:       "update_with_only_priority",
 			createBody: `{"task":"Updated Task", "priority":1}`,
 			updateBody: `{"priority":2}`,
+			want: want{
+				StatusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:       "successful_update_with_due_at",
+			createBody: `{"task":"Updated Task", "priority":1, "scheduled_for":"2030-01-01T09:00:00Z"}`,
+			updateBody: `{"task":"Updated Task","status":"created","priority":1,"due_at":"2030-01-02T00:00:00Z"}`,
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":{"Task":"Updated Task","Status":"created", "Priority":2}}`),
+				// scheduled_for is left out of the replacement, so it is cleared.
+				Response: []byte(`{"data":{"Task":"Updated Task","Status":"created", "Priority":1, "DueAt":"2030-01-02T00:00:00Z"}}`),
 			},
 		},
 		{
@@ -182,7 +213,7 @@ func TestTodoHandler_Update(t *testing.T) {
 		{
 			name:       "not_found_record",
 			updateID:   "-1",
-			updateBody: `{"task":"Updated Task","status":"done"}`,
+			updateBody: `{"task":"Updated Task","status":"done","priority":1}`,
 			want: want{
 				StatusCode: http.StatusNotFound,
 			},
@@ -197,7 +228,7 @@ func TestTodoHandler_Update(t *testing.T) {
 		{
 			name:       "invalid_request_parameter",
 			updateID:   "invalid",
-			updateBody: `{"task":"Updated Task","status":"done"}`,
+			updateBody: `{"task":"Updated Task","status":"done","priority":1}`,
 			want: want{
 				StatusCode: http.StatusBadRequest,
 			},
@@ -256,8 +287,10 @@ func TestTodoHandler_Delete(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
 	repository := repository.NewTodo(dbInstance)
-	service := service.NewTodo(repository)
+	service := service.NewTodo(repository, projects, webhooks)
 	handler := NewTodo(service)
 
 	tests := []struct {
@@ -329,8 +362,10 @@ func TestTodoHandler_Find(t *testing.T) {
 	require.NoError(t, err)
 	err = db.Migrate(dbInstance)
 	require.NoError(t, err)
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
 	repository := repository.NewTodo(dbInstance)
-	service := service.NewTodo(repository)
+	service := service.NewTodo(repository, projects, webhooks)
 	handler := NewTodo(service)
 
 	tests := []struct {
@@ -418,8 +453,10 @@ func TestTodoHandler_FindAll(t *testing.T) {
 	err = db.Migrate(dbInstance)
 	clearDB(dbInstance, model.Todo{})
 	require.NoError(t, err)
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
 	repository := repository.NewTodo(dbInstance)
-	service := service.NewTodo(repository)
+	service := service.NewTodo(repository, projects, webhooks)
 	handler := NewTodo(service)
 
 	tests := []struct {
@@ -431,7 +468,7 @@ func TestTodoHandler_FindAll(t *testing.T) {
 			name: "no_todos_found",
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":[]}`), // Expecting an empty array
+				Response:   []byte(`{"data":[],"meta":{"limit":50}}`), // Expecting an empty array
 			},
 		},
 		{
@@ -439,7 +476,7 @@ func TestTodoHandler_FindAll(t *testing.T) {
 			createBody: []string{`{"task":"Task 1", "priority":1}`},
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":[{"Task":"Task 1","Priority":1,"Status":"created"}]}`),
+				Response:   []byte(`{"data":[{"Task":"Task 1","Priority":1,"Status":"created"}],"meta":{"limit":50}}`),
 			},
 		},
 		{
@@ -447,7 +484,7 @@ func TestTodoHandler_FindAll(t *testing.T) {
 			createBody: []string{`{"task":"Task 2", "priority":2}`},
 			want: want{
 				StatusCode: http.StatusOK,
-				Response:   []byte(`{"data":[{"Task":"Task 2","Priority":2,"Status":"created"},{"Task":"Task 1","Priority":1,"Status":"created"}]}`),
+				Response:   []byte(`{"data":[{"Task":"Task 2","Priority":2,"Status":"created"},{"Task":"Task 1","Priority":1,"Status":"created"}],"meta":{"limit":50}}`),
 			},
 		},
 	}
@@ -488,9 +525,877 @@ func TestTodoHandler_FindAll(t *testing.T) {
 	}
 }
 
+func TestTodoHandler_FindAll_Pagination(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	for _, body := range []string{
+		`{"task":"Task 1", "priority":1}`,
+		`{"task":"Task 2", "priority":3}`,
+		`{"task":"Task 3", "priority":2}`,
+		`{"task":"Task 4", "priority":3}`,
+		`{"task":"Task 5", "priority":1}`,
+	} {
+		createTask(t, e, handler, body)
+	}
+
+	type response struct {
+		Data []model.Todo
+		Meta Meta
+	}
+	findAll := func(t *testing.T, query string) (int, response) {
+		req := httptest.NewRequest(http.MethodGet, "/todos?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/todos")
+		require.NoError(t, handler.FindAll(c))
+
+		var res response
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		}
+		return rec.Code, res
+	}
+
+	t.Run("walk_all_pages", func(t *testing.T) {
+		var (
+			tasks  []string
+			cursor string
+		)
+		for {
+			code, res := findAll(t, "limit=2&with_total=true&cursor="+cursor)
+			require.Equal(t, http.StatusOK, code)
+			require.NotNil(t, res.Meta.Total)
+			assert.Equal(t, int64(5), *res.Meta.Total)
+			assert.LessOrEqual(t, len(res.Data), 2)
+			for _, td := range res.Data {
+				tasks = append(tasks, td.Task)
+			}
+			if res.Meta.NextCursor == "" {
+				break
+			}
+			cursor = res.Meta.NextCursor
+		}
+		assert.Equal(t, []string{"Task 4", "Task 2", "Task 3", "Task 5", "Task 1"}, tasks)
+	})
+
+	t.Run("filter_is_kept_across_pages", func(t *testing.T) {
+		code, res := findAll(t, "limit=1&task=Task+2")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Data, 1)
+		assert.Equal(t, "Task 2", res.Data[0].Task)
+		assert.Empty(t, res.Meta.NextCursor)
+		assert.Nil(t, res.Meta.Total)
+	})
+
+	t.Run("invalid_cursor", func(t *testing.T) {
+		code, _ := findAll(t, "cursor=not-a-cursor")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("invalid_limit", func(t *testing.T) {
+		code, _ := findAll(t, "limit=1000")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestTodoHandler_FindAll_Sort(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	for _, body := range []string{
+		`{"task":"b", "priority":1}`,
+		`{"task":"c", "priority":3}`,
+		`{"task":"a", "priority":2}`,
+	} {
+		createTask(t, e, handler, body)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		tasks      []string
+	}{
+		{
+			name:       "default_order",
+			statusCode: http.StatusOK,
+			tasks:      []string{"c", "a", "b"},
+		},
+		{
+			name:       "sort_by_task",
+			query:      "sort=task",
+			statusCode: http.StatusOK,
+			tasks:      []string{"a", "b", "c"},
+		},
+		{
+			name:       "sort_by_task_desc",
+			query:      "sort=-task",
+			statusCode: http.StatusOK,
+			tasks:      []string{"c", "b", "a"},
+		},
+		{
+			name:       "sort_by_multiple_fields",
+			query:      "sort=status,priority",
+			statusCode: http.StatusOK,
+			tasks:      []string{"b", "a", "c"},
+		},
+		{
+			name:       "sort_by_task_paginated",
+			query:      "sort=task&limit=2",
+			statusCode: http.StatusOK,
+			tasks:      []string{"a", "b"},
+		},
+		{
+			name:       "unknown_field",
+			query:      "sort=-password",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "duplicate_field",
+			query:      "sort=task,-task",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos")
+
+			require.NoError(t, handler.FindAll(c))
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.tasks == nil {
+				return
+			}
+
+			var res struct {
+				Data []model.Todo
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			var tasks []string
+			for _, td := range res.Data {
+				tasks = append(tasks, td.Task)
+			}
+			assert.Equal(t, tt.tasks, tasks)
+		})
+	}
+}
+
+func TestTodoHandler_FindAll_Search(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+	fts := db.SearchAvailable(dbInstance)
+
+	for _, body := range []string{
+		`{"task":"buy groceries for the weekend", "priority":1}`,
+		`{"task":"call the plumber", "priority":3}`,
+		`{"task":"groceries groceries groceries", "priority":2}`,
+	} {
+		createTask(t, e, handler, body)
+	}
+	// Renamed tasks must be reindexed.
+	id := createTask(t, e, handler, `{"task":"walk the dog", "priority":1}`)
+	_, err = service.Update(id, "walk the cat", 1, model.Created, model.TodoDetails{}, model.UpdateOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		query      string
+		ftsOnly    bool
+		statusCode int
+		tasks      []string
+	}{
+		{
+			name:       "single_term",
+			query:      "q=plumber",
+			statusCode: http.StatusOK,
+			tasks:      []string{"call the plumber"},
+		},
+		{
+			name:       "updated_task",
+			query:      "q=cat",
+			statusCode: http.StatusOK,
+			tasks:      []string{"walk the cat"},
+		},
+		{
+			name:       "no_match",
+			query:      "q=dog",
+			statusCode: http.StatusOK,
+			tasks:      []string{},
+		},
+		{
+			name:       "ranked_by_relevance",
+			query:      "q=groceries",
+			ftsOnly:    true,
+			statusCode: http.StatusOK,
+			tasks:      []string{"groceries groceries groceries", "buy groceries for the weekend"},
+		},
+		{
+			name:       "prefix",
+			query:      "q=plumb*",
+			ftsOnly:    true,
+			statusCode: http.StatusOK,
+			tasks:      []string{"call the plumber"},
+		},
+		{
+			name:       "phrase",
+			query:      `q="the+weekend"`,
+			ftsOnly:    true,
+			statusCode: http.StatusOK,
+			tasks:      []string{"buy groceries for the weekend"},
+		},
+		{
+			name:       "paginated",
+			query:      "q=groceries&limit=1",
+			ftsOnly:    true,
+			statusCode: http.StatusOK,
+			tasks:      []string{"groceries groceries groceries"},
+		},
+		{
+			name:       "syntax_error",
+			query:      `q="unterminated`,
+			ftsOnly:    true,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.ftsOnly && !fts {
+				t.Skip("SQLite built without FTS5, run with -tags sqlite_fts5")
+			}
+			req := httptest.NewRequest(http.MethodGet, "/todos?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos")
+
+			require.NoError(t, handler.FindAll(c))
+			assert.Equal(t, tt.statusCode, rec.Code)
+			if tt.tasks == nil {
+				return
+			}
+
+			var res struct {
+				Data []model.Todo
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			tasks := []string{}
+			for _, td := range res.Data {
+				tasks = append(tasks, td.Task)
+				if fts {
+					assert.Contains(t, td.Snippet, "<mark>")
+				}
+			}
+			assert.Equal(t, tt.tasks, tasks)
+		})
+	}
+}
+
+func TestTodoHandler_FindAll_DueDates(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	past := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	future := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	for _, body := range []string{
+		`{"task":"no due date", "priority":3}`,
+		`{"task":"due later", "priority":3, "due_at":"` + future + `"}`,
+		`{"task":"overdue", "priority":1, "due_at":"` + past + `"}`,
+	} {
+		createTask(t, e, handler, body)
+	}
+	id := createTask(t, e, handler, `{"task":"done late", "priority":2, "due_at":"`+past+`"}`)
+	done, err := service.Find(id)
+	require.NoError(t, err)
+	_, err = service.Update(id, done.Task, done.Priority, model.Done, model.TodoDetails{DueAt: done.DueAt}, model.UpdateOptions{})
+	require.NoError(t, err)
+
+	now := url.QueryEscape(time.Now().UTC().Format(time.RFC3339))
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		tasks      []string
+	}{
+		{
+			name:       "overdue_first_by_default",
+			statusCode: http.StatusOK,
+			tasks:      []string{"overdue", "due later", "no due date", "done late"},
+		},
+		{
+			name:       "overdue",
+			query:      "overdue=true",
+			statusCode: http.StatusOK,
+			tasks:      []string{"overdue"},
+		},
+		{
+			name:       "not_overdue",
+			query:      "overdue=false",
+			statusCode: http.StatusOK,
+			tasks:      []string{"due later", "no due date", "done late"},
+		},
+		{
+			name:       "due_before",
+			query:      "due_before=" + now,
+			statusCode: http.StatusOK,
+			tasks:      []string{"overdue", "done late"},
+		},
+		{
+			name:       "due_after",
+			query:      "due_after=" + now,
+			statusCode: http.StatusOK,
+			tasks:      []string{"due later"},
+		},
+		{
+			name:       "sort_by_due_at_without_date_last",
+			query:      "sort=due_at,task",
+			statusCode: http.StatusOK,
+			tasks:      []string{"done late", "overdue", "due later", "no due date"},
+		},
+		{
+			name:       "invalid_due_before",
+			query:      "due_before=yesterday",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				tasks  []string
+				cursor string
+			)
+			// Walk the pages one todo at a time to exercise the keyset cursor.
+			for {
+				req := httptest.NewRequest(http.MethodGet, "/todos?limit=1&cursor="+cursor+"&"+tt.query, nil)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetPath("/todos")
+
+				require.NoError(t, handler.FindAll(c))
+				require.Equal(t, tt.statusCode, rec.Code)
+				if tt.tasks == nil {
+					return
+				}
+
+				var res struct {
+					Data []model.Todo
+					Meta Meta
+				}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+				for _, td := range res.Data {
+					tasks = append(tasks, td.Task)
+				}
+				if res.Meta.NextCursor == "" {
+					break
+				}
+				cursor = res.Meta.NextCursor
+			}
+			assert.Equal(t, tt.tasks, tasks)
+		})
+	}
+}
+
+func TestTodoHandler_FindAll_DueDatesOffset(t *testing.T) {
+	// Dates of other zones compare by instant, whatever the zones of the server and the query.
+	local := time.Local
+	time.Local = time.FixedZone("UTC-7", -7*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	past := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	soon := time.Now().Add(2 * time.Hour).In(tokyo).Format(time.RFC3339)
+	createTask(t, e, handler, `{"task":"overdue", "priority":1, "due_at":"`+past+`"}`)
+	createTask(t, e, handler, `{"task":"due soon", "priority":1, "due_at":"`+soon+`"}`)
+
+	inAnHour := url.QueryEscape(time.Now().Add(time.Hour).In(tokyo).Format(time.RFC3339))
+	tests := []struct {
+		name  string
+		query string
+		tasks []string
+	}{
+		{name: "overdue", query: "overdue=true", tasks: []string{"overdue"}},
+		{name: "not_overdue", query: "overdue=false", tasks: []string{"due soon"}},
+		{name: "overdue_first_by_default", tasks: []string{"overdue", "due soon"}},
+		{name: "due_before_offset", query: "due_before=" + inAnHour, tasks: []string{"overdue"}},
+		{name: "due_after_offset", query: "due_after=" + inAnHour, tasks: []string{"due soon"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/todos")
+
+			require.NoError(t, handler.FindAll(c))
+			require.Equal(t, http.StatusOK, rec.Code)
+			var res struct {
+				Data []model.Todo
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			var tasks []string
+			for _, td := range res.Data {
+				tasks = append(tasks, td.Task)
+			}
+			assert.Equal(t, tt.tasks, tasks)
+		})
+	}
+}
+
+func TestTodoHandler_FindAll_CursorBecomingOverdue(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	due := time.Now().Add(time.Second)
+	createTask(t, e, handler, `{"task":"urgent", "priority":3}`)
+	createTask(t, e, handler, `{"task":"due in a second", "priority":1, "due_at":"`+due.UTC().Format(time.RFC3339Nano)+`"}`)
+
+	findAll := func(cursor string) ([]string, string) {
+		req := httptest.NewRequest(http.MethodGet, "/todos?limit=1&cursor="+cursor, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/todos")
+		require.NoError(t, handler.FindAll(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data []model.Todo
+			Meta Meta
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		var tasks []string
+		for _, td := range res.Data {
+			tasks = append(tasks, td.Task)
+		}
+		return tasks, res.Meta.NextCursor
+	}
+
+	tasks, cursor := findAll("")
+	assert.Equal(t, []string{"urgent"}, tasks)
+	require.NotEmpty(t, cursor)
+
+	// The todo turning overdue between two pages would move before the cursor, the pages keep the time of the first.
+	time.Sleep(time.Until(due) + 10*time.Millisecond)
+	tasks, cursor = findAll(cursor)
+	assert.Equal(t, []string{"due in a second"}, tasks)
+	assert.Empty(t, cursor)
+}
+
+func TestTodoHandler_Recurrence(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Series{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	update := func(t *testing.T, id int, body string) (int, model.Todo) {
+		req := httptest.NewRequest(http.MethodPatch, "/dummy/target", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, mimeMergePatch)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/todos/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.Itoa(id))
+		require.NoError(t, handler.Patch(c))
+
+		var res struct {
+			Data model.Todo
+		}
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		}
+		return rec.Code, res.Data
+	}
+	occurrences := func(t *testing.T) []*model.Todo {
+		page, err := service.FindAll(url.Values{}, model.NewPageRequest(0, "", false, []model.SortField{{Column: "due_at"}}))
+		require.NoError(t, err)
+		return page.Todos
+	}
+
+	t.Run("invalid_requests", func(t *testing.T) {
+		for _, body := range []string{
+			`{"task":"Chore", "priority":1, "recurrence":"FREQ=WEEKLY"}`,
+			`{"task":"Chore", "priority":1, "due_at":"2030-01-07T09:00:00Z", "recurrence":"FREQ=SOMETIMES"}`,
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/todos", bytes.NewReader([]byte(body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler.Create(e.NewContext(req, rec)))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+
+	first := createTask(t, e, handler,
+		`{"task":"Take out trash", "priority":2, "due_at":"2030-01-07T09:00:00Z", "recurrence":"RRULE:FREQ=WEEKLY;COUNT=3"}`)
+
+	t.Run("complete_first_occurrence", func(t *testing.T) {
+		code, todo := update(t, first, `{"status":"done"}`)
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, todo.Series)
+		assert.Equal(t, "FREQ=WEEKLY;COUNT=3", todo.Series.Recurrence)
+
+		// Reopening and completing again must not duplicate the next occurrence.
+		code, _ = update(t, first, `{"status":"created"}`)
+		require.Equal(t, http.StatusOK, code)
+		code, _ = update(t, first, `{"status":"done"}`)
+		require.Equal(t, http.StatusOK, code)
+
+		todos := occurrences(t)
+		require.Len(t, todos, 2)
+		assert.Equal(t, todos[0].SeriesID, todos[1].SeriesID)
+		assert.Equal(t, "Take out trash", todos[1].Task)
+		assert.Equal(t, model.Created, todos[1].Status)
+		assert.Equal(t, time.Date(2030, 1, 14, 9, 0, 0, 0, time.UTC), todos[1].DueAt.UTC())
+	})
+
+	t.Run("edit_recurrence_of_this_occurrence", func(t *testing.T) {
+		second := occurrences(t)[1].ID
+		code, _ := update(t, second, `{"recurrence":"FREQ=DAILY"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("edit_this_occurrence", func(t *testing.T) {
+		second := occurrences(t)[1].ID
+		code, todo := update(t, second, `{"task":"Take out recycling", "scope":"this"}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "Take out trash", todo.Series.Task)
+	})
+
+	t.Run("edit_future_occurrences", func(t *testing.T) {
+		second := occurrences(t)[1].ID
+		code, todo := update(t, second, `{"task":"Take out bins", "status":"done", "scope":"future"}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "Take out bins", todo.Series.Task)
+
+		todos := occurrences(t)
+		require.Len(t, todos, 3)
+		assert.Equal(t, "Take out bins", todos[2].Task)
+		assert.Equal(t, time.Date(2030, 1, 21, 9, 0, 0, 0, time.UTC), todos[2].DueAt.UTC())
+	})
+
+	t.Run("series_ends_after_count", func(t *testing.T) {
+		third := occurrences(t)[2].ID
+		code, _ := update(t, third, `{"status":"done"}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Len(t, occurrences(t), 3)
+	})
+
+	t.Run("make_existing_todo_recurring", func(t *testing.T) {
+		id := createTask(t, e, handler, `{"task":"Pay rent", "priority":3, "due_at":"2030-02-01T00:00:00Z"}`)
+		code, todo := update(t, id, `{"recurrence":"FREQ=MONTHLY", "status":"done"}`)
+		require.Equal(t, http.StatusOK, code)
+		require.NotNil(t, todo.SeriesID)
+
+		todos := occurrences(t)
+		require.Len(t, todos, 5)
+		assert.Equal(t, "Pay rent", todos[4].Task)
+		assert.Equal(t, time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC), todos[4].DueAt.UTC())
+	})
+}
+
+func TestTodoHandler_Subtasks(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{})
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	call := func(t *testing.T, fn func(echo.Context) error, method, target, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, fn(c))
+		return rec
+	}
+
+	root := createTask(t, e, handler, `{"task":"Release", "priority":3}`)
+	child := createTask(t, e, handler, fmt.Sprintf(`{"task":"Write changelog", "priority":2, "parent_id":%d}`, root))
+	grandchild := createTask(t, e, handler, fmt.Sprintf(`{"task":"Collect PRs", "priority":1, "parent_id":%d}`, child))
+	sibling := createTask(t, e, handler, fmt.Sprintf(`{"task":"Tag version", "priority":1, "parent_id":%d}`, root))
+
+	t.Run("create_with_unknown_parent", func(t *testing.T) {
+		rec := call(t, handler.Create, http.MethodPost, "/todos", "", `{"task":"Orphan", "priority":1, "parent_id":-1}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("children", func(t *testing.T) {
+		rec := call(t, handler.Children, http.MethodGet, "/todos/1/children", strconv.Itoa(root), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		want := []byte(fmt.Sprintf(`{"data":[
+			{"Task":"Write changelog","Priority":2,"Status":"created","ParentID":%d},
+			{"Task":"Tag version","Priority":1,"Status":"created","ParentID":%d}
+		]}`, root, root))
+		opts := []cmp.Option{
+			cmpTransformJSON(t),
+			ignoreMapEntires(map[string]any{"CreatedAt": 1, "UpdatedAt": 1, "ID": 1}),
+		}
+		if diff := cmp.Diff(rec.Body.Bytes(), want, opts...); diff != "" {
+			t.Errorf("return value mismatch (-got +want):\n%s", diff)
+		}
+
+		rec = call(t, handler.Children, http.MethodGet, "/todos/-1/children", "-1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		rec := call(t, handler.Subtree, http.MethodGet, "/todos/1/subtree", strconv.Itoa(root), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		want := []byte(fmt.Sprintf(`{"data":{"Task":"Release","Priority":3,"Status":"created","Children":[
+			{"Task":"Write changelog","Priority":2,"Status":"created","ParentID":%d,"Children":[
+				{"Task":"Collect PRs","Priority":1,"Status":"created","ParentID":%d,"Children":[]}
+			]},
+			{"Task":"Tag version","Priority":1,"Status":"created","ParentID":%d,"Children":[]}
+		]}}`, root, child, root))
+		opts := []cmp.Option{
+			cmpTransformJSON(t),
+			ignoreMapEntires(map[string]any{"CreatedAt": 1, "UpdatedAt": 1, "ID": 1}),
+		}
+		if diff := cmp.Diff(rec.Body.Bytes(), want, opts...); diff != "" {
+			t.Errorf("return value mismatch (-got +want):\n%s", diff)
+		}
+	})
+
+	t.Run("move_under_own_subtask", func(t *testing.T) {
+		body := fmt.Sprintf(`{"parent_id":%d}`, grandchild)
+		rec := call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(root), body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		body = fmt.Sprintf(`{"parent_id":%d}`, root)
+		rec = call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(root), body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("done_with_open_subtasks", func(t *testing.T) {
+		rec := call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(child), `{"status":"done"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		rec = call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(grandchild), `{"status":"done"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(child), `{"status":"done"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(root), `{"status":"done"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		rec = call(t, handler.Patch, http.MethodPatch, "/todos/1", strconv.Itoa(root), `{"status":"done", "force":true}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("delete_with_subtasks", func(t *testing.T) {
+		rec := call(t, handler.Delete, http.MethodDelete, "/todos/1", strconv.Itoa(child), "")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		rec = call(t, handler.Delete, http.MethodDelete, "/todos/1?mode=whatever", strconv.Itoa(child), "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// The grandchild moves up to the root.
+		rec = call(t, handler.Delete, http.MethodDelete, "/todos/1?mode=reparent", strconv.Itoa(child), "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		moved, err := service.Find(grandchild)
+		require.NoError(t, err)
+		require.NotNil(t, moved.ParentID)
+		assert.Equal(t, root, *moved.ParentID)
+
+		rec = call(t, handler.Delete, http.MethodDelete, "/todos/1?mode=cascade", strconv.Itoa(root), "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		for _, id := range []int{root, grandchild, sibling} {
+			_, err := service.Find(id)
+			assert.Equal(t, model.ErrNotFound, err)
+		}
+	})
+}
+
+func TestTodoHandler_Tags(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Tag{})
+	dbInstance.Exec("DELETE FROM todo_tags")
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	groceries := createTask(t, e, handler, `{"task":"Buy milk", "priority":1, "tags":["Home","errand"]}`)
+	createTask(t, e, handler, `{"task":"Fix bug", "priority":2, "tags":["work","urgent"]}`)
+	createTask(t, e, handler, `{"task":"Call mom", "priority":3, "tags":["home"]}`)
+	createTask(t, e, handler, `{"task":"Read book", "priority":3}`)
+
+	t.Run("create_with_invalid_tag", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/todos", bytes.NewReader([]byte(`{"task":"Bad", "priority":1, "tags":["two words"]}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.Create(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []string
+	}{
+		{
+			name:  "tag_all_of",
+			query: url.Values{"tag": {"home,errand"}},
+			want:  []string{"Buy milk"},
+		},
+		{
+			name:  "tag_single",
+			query: url.Values{"tag": {"home"}},
+			want:  []string{"Call mom", "Buy milk"},
+		},
+		{
+			name:  "tag_any",
+			query: url.Values{"tag_any": {"errand", "WORK"}},
+			want:  []string{"Fix bug", "Buy milk"},
+		},
+		{
+			name:  "tag_none",
+			query: url.Values{"tag_none": {"home"}},
+			want:  []string{"Read book", "Fix bug"},
+		},
+		{
+			name:  "unknown_tag",
+			query: url.Values{"tag": {"nope"}},
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos?"+tt.query.Encode(), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.FindAll(c))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var res struct {
+				Data []model.Todo
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			got := []string{}
+			for _, todo := range res.Data {
+				got = append(got, todo.Task)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("replace_tags", func(t *testing.T) {
+		update := func(body string) model.Todo {
+			req := httptest.NewRequest(http.MethodPatch, "/todos/1", bytes.NewReader([]byte(body)))
+			req.Header.Set(echo.HeaderContentType, mimeMergePatch)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(strconv.Itoa(groceries))
+			require.NoError(t, handler.Patch(c))
+			require.Equal(t, http.StatusOK, rec.Code)
+			var res struct {
+				Data model.Todo
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			return res.Data
+		}
+
+		assert.Equal(t, []string{"errand", "home"}, update(`{"task":"Buy oat milk"}`).Tags.Names())
+		assert.Equal(t, []string{"shopping"}, update(`{"tags":["shopping"]}`).Tags.Names())
+		assert.Empty(t, update(`{"tags":[]}`).Tags.Names())
+		assert.Equal(t, []string{"home"}, update(`{"tags":["home"]}`).Tags.Names())
+		assert.Empty(t, update(`{"tags":null}`).Tags.Names())
+	})
+}
+
 func clearDB(db *gorm.DB, models ...interface{}) {
 	for _, model := range models {
-		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model)
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(model)
 	}
 }
 
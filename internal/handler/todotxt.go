@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/fardinabir/todo-manager-app/internal/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// preferredMediaType returns the offer the Accept header of the request prefers. The first offer
+// wins ties and is returned when the header is missing or accepts none of the offers.
+func preferredMediaType(c echo.Context, offers ...string) string {
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		// The quality of an offer is the one of the most specific media range matching it.
+		q, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			s := mediaRangeSpecificity(mediaRange, offer)
+			if s <= specificity {
+				continue
+			}
+			specificity, q = s, 1
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRangeSpecificity returns how specifically a media range such as text/* matches
+// a media type, -1 when it does not match it.
+func mediaRangeSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
+
+// listTodoTxt responds with the page of todos of req as the lines of a todo.txt file.
+// The next page is linked from the Link header.
+func listTodoTxt(c echo.Context, req FindAllRequest, s service.Todo) error {
+	sort, err := model.ParseSort(req.Sort)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	page := model.NewPageRequest(req.Limit, req.Cursor, false, sort)
+	res, err := s.FindAll(c.QueryParams(), page)
+	if err != nil {
+		if err == model.ErrInvalidCursor || err == model.ErrInvalidSearchQuery {
+			return c.JSON(http.StatusBadRequest,
+				ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	projects, err := transfer.ProjectNames(s)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	if links := nextLinks(c, res.NextCursor); links != nil {
+		c.Response().Header().Set("Link", "<"+links.Next+`>; rel="next"`)
+	}
+	c.Response().WriteHeader(http.StatusOK)
+	w, _ := transfer.NewWriter(transfer.TodoTxt, c.Response())
+	for _, todo := range res.Todos {
+		if err := w.Write(transfer.NewRecord(todo, projects)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredMediaType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", echo.MIMEApplicationJSON},
+		{"*/*", echo.MIMEApplicationJSON},
+		{"text/plain", echo.MIMETextPlain},
+		{"text/*", echo.MIMETextPlain},
+		{"text/plain, application/json", echo.MIMEApplicationJSON},
+		{"application/json;q=0.5, text/plain", echo.MIMETextPlain},
+		{"text/*;q=0.9, */*;q=1", echo.MIMEApplicationJSON},
+		{"text/*, text/plain;q=0", echo.MIMEApplicationJSON},
+		{"image/png", echo.MIMEApplicationJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAccept, tt.accept)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+			assert.Equal(t, tt.want, preferredMediaType(c, echo.MIMEApplicationJSON, echo.MIMETextPlain))
+		})
+	}
+}
+
+func TestTodoHandler_FindAll_TodoTxt(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}, model.Project{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "todotxt@example.com")
+
+	call := func(method, target, accept, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAccept, accept)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := call(http.MethodPost, "/api/v1/projects", "", `{"name":"Home Chores"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var project struct {
+		Data model.Project
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &project))
+	rec = call(http.MethodPost, "/api/v1/todos", "", `{"task":"Water plants", "priority":3, "tags":["garden"], "due_at":"2030-05-01T00:00:00Z", "project_id":`+
+		strconv.Itoa(project.Data.ID)+`}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = call(http.MethodPost, "/api/v1/todos", "", `{"task":"Read book", "priority":1}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	t.Run("plain", func(t *testing.T) {
+		rec := call(http.MethodGet, "/api/v1/todos?sort=priority&limit=1", "text/plain", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+		today := strings.Fields(rec.Body.String())[1]
+		assert.Equal(t, "(C) "+today+" Read book\n", rec.Body.String())
+		link := rec.Header().Get("Link")
+		require.True(t, strings.HasPrefix(link, "</api/v1/todos?"), link)
+		assert.True(t, strings.HasSuffix(link, `>; rel="next"`), link)
+
+		next := strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+		rec = call(http.MethodGet, next, "text/plain", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "(A) "+today+" Water plants +Home-Chores @garden due:2030-05-01\n", rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Link"))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json, text/plain;q=0.5"} {
+			rec := call(http.MethodGet, "/api/v1/todos", accept, "")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType), accept)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+)
+
+// PurgeResult is the number of todos permanently deleted
+type PurgeResult struct {
+	Purged int64
+}
+
+// @Summary		List the trash
+// @Description	Deleted todos, most recently deleted first. They are purged automatically once the retention period has passed.
+// @Tags			trash
+// @Security		BearerAuth
+// @Success		200	{object}	ResponseData{Data=[]model.TrashedTodo}
+// @Failure		500	{object}	ResponseError
+// @Router			/trash [get]
+func (t *todoHandler) Trash(c echo.Context) error {
+	res, err := t.service.ForOwner(currentUser(c)).Trash()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// @Summary		Restore a todo from the trash
+// @Description	A restored subtask whose parent is no longer available moves to the top level.
+// @Tags			trash
+// @Security		BearerAuth
+// @Param			path	path		FindRequest	false	"path"
+// @Success		200		{object}	ResponseData{Data=model.Todo}
+// @Failure		400		{object}	ResponseError
+// @Failure		404		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/todos/{id}/restore [post]
+func (t *todoHandler) Restore(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := t.service.ForOwner(currentUser(c)).Restore(req.ID)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found in trash"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// @Summary	Permanently delete a todo from the trash
+// @Tags		trash
+// @Security	BearerAuth
+// @Param		path	path	FindRequest	false	"path"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/trash/{id} [delete]
+func (t *todoHandler) Purge(c echo.Context) error {
+	var req FindRequest
+	if err := t.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := t.service.ForOwner(currentUser(c)).Purge(req.ID); err != nil {
+		if err == model.ErrNotFound {
+			return c.JSON(http.StatusNotFound,
+				ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "todo not found in trash"}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Empty the trash
+// @Tags		trash
+// @Security	BearerAuth
+// @Success	200	{object}	ResponseData{Data=PurgeResult}
+// @Failure	500	{object}	ResponseError
+// @Router		/trash [delete]
+func (t *todoHandler) EmptyTrash(c echo.Context) error {
+	n, err := t.service.ForOwner(currentUser(c)).EmptyTrash()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: PurgeResult{Purged: n}})
+}
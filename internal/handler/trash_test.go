@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHandler_Trash(t *testing.T) {
+	e := echo.New()
+	e.Validator = NewCustomValidator()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	clearDB(dbInstance, model.Todo{}, model.Tag{})
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}) })
+	projects := repository.NewProject(dbInstance)
+	webhooks := repository.NewWebhook(dbInstance)
+	repository := repository.NewTodo(dbInstance)
+	service := service.NewTodo(repository, projects, webhooks)
+	handler := NewTodo(service)
+
+	call := func(t *testing.T, fn func(echo.Context) error, method, path, id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/dummy/target?"+query, bytes.NewReader(nil))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		if id != "" {
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+		}
+		require.NoError(t, fn(c))
+		return rec
+	}
+	trash := func(t *testing.T) []model.TrashedTodo {
+		rec := call(t, handler.Trash, http.MethodGet, "/trash", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data []model.TrashedTodo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Data
+	}
+
+	t.Run("delete_moves_to_trash", func(t *testing.T) {
+		id := strconv.Itoa(createTask(t, e, handler, `{"task":"Trashed", "priority":1, "tags":["work"]}`))
+		assert.Equal(t, http.StatusNoContent, call(t, handler.Delete, http.MethodDelete, "/todos/:id", id, "").Code)
+		assert.Equal(t, http.StatusNotFound, call(t, handler.Find, http.MethodGet, "/todos/:id", id, "").Code)
+
+		trashed := trash(t)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, "Trashed", trashed[0].Task)
+		assert.False(t, trashed[0].DeletedAt.IsZero())
+		require.Len(t, trashed[0].Tags, 1)
+
+		rec := call(t, handler.Restore, http.MethodPost, "/todos/:id/restore", id, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data model.Todo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, "Trashed", res.Data.Task)
+		require.Len(t, res.Data.Tags, 1)
+		assert.Equal(t, "work", res.Data.Tags[0].Name)
+
+		assert.Empty(t, trash(t))
+		assert.Equal(t, http.StatusOK, call(t, handler.Find, http.MethodGet, "/todos/:id", id, "").Code)
+	})
+
+	t.Run("restore_not_in_trash", func(t *testing.T) {
+		id := strconv.Itoa(createTask(t, e, handler, `{"task":"Alive", "priority":1}`))
+		assert.Equal(t, http.StatusNotFound, call(t, handler.Restore, http.MethodPost, "/todos/:id/restore", id, "").Code)
+		assert.Equal(t, http.StatusNotFound, call(t, handler.Purge, http.MethodDelete, "/trash/:id", id, "").Code)
+		assert.Equal(t, http.StatusBadRequest, call(t, handler.Restore, http.MethodPost, "/todos/:id/restore", "invalid", "").Code)
+	})
+
+	t.Run("restore_subtask_of_trashed_parent", func(t *testing.T) {
+		parentID := createTask(t, e, handler, `{"task":"Parent", "priority":1}`)
+		childID := strconv.Itoa(createTask(t, e, handler, `{"task":"Child", "priority":1, "parent_id":`+strconv.Itoa(parentID)+`}`))
+		rec := call(t, handler.Delete, http.MethodDelete, "/todos/:id", strconv.Itoa(parentID), "mode=cascade")
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = call(t, handler.Restore, http.MethodPost, "/todos/:id/restore", childID, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data model.Todo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Nil(t, res.Data.ParentID)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		id := strconv.Itoa(createTask(t, e, handler, `{"task":"Purged", "priority":1}`))
+		require.Equal(t, http.StatusNoContent, call(t, handler.Delete, http.MethodDelete, "/todos/:id", id, "").Code)
+		assert.Equal(t, http.StatusNoContent, call(t, handler.Purge, http.MethodDelete, "/trash/:id", id, "").Code)
+		assert.Equal(t, http.StatusNotFound, call(t, handler.Purge, http.MethodDelete, "/trash/:id", id, "").Code)
+		assert.Equal(t, http.StatusNotFound, call(t, handler.Restore, http.MethodPost, "/todos/:id/restore", id, "").Code)
+	})
+
+	t.Run("empty_trash", func(t *testing.T) {
+		require.NotEmpty(t, trash(t))
+		rec := call(t, handler.EmptyTrash, http.MethodDelete, "/trash", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res struct {
+			Data PurgeResult
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Positive(t, res.Data.Purged)
+		assert.Empty(t, trash(t))
+	})
+
+	t.Run("purge_expired", func(t *testing.T) {
+		kept := strconv.Itoa(createTask(t, e, handler, `{"task":"Recently deleted", "priority":1}`))
+		require.Equal(t, http.StatusNoContent, call(t, handler.Delete, http.MethodDelete, "/todos/:id", kept, "").Code)
+		expired := createTask(t, e, handler, `{"task":"Long deleted", "priority":1}`)
+		require.Equal(t, http.StatusNoContent, call(t, handler.Delete, http.MethodDelete, "/todos/:id", strconv.Itoa(expired), "").Code)
+		err := dbInstance.Unscoped().Model(&model.Todo{}).Where("id = ?", expired).
+			Update("deleted_at", time.Now().Add(-48*time.Hour)).Error
+		require.NoError(t, err)
+
+		n, err := service.PurgeExpired(24 * time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		trashed := trash(t)
+		require.Len(t, trashed, 1)
+		assert.Equal(t, "Recently deleted", trashed[0].Task)
+	})
+}
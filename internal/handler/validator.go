@@ -25,6 +25,10 @@ func NewCustomValidator() *CustomValidator {
 	// Register the custom validation for Priority
 	_ = v.RegisterValidation("validPriority", model.IsValidPriority)
 	_ = v.RegisterValidation("validStatus", model.IsValidStatus)
+	_ = v.RegisterValidation("validRecurrence", model.IsValidRecurrence)
+	_ = v.RegisterValidation("validTagName", model.IsValidTagName)
+	_ = v.RegisterValidation("validEventType", model.IsValidEventType)
+	_ = v.RegisterValidation("validWebhookURL", model.IsValidWebhookURL)
 
 	return &CustomValidator{validator: v}
 }
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDeliveryLimit is the number of deliveries listed when no limit is given.
+const defaultDeliveryLimit = 20
+
+// WebhookHandler is the request handler for the webhook endpoint.
+type WebhookHandler interface {
+	Create(c echo.Context) error
+	Delete(c echo.Context) error
+	Find(c echo.Context) error
+	FindAll(c echo.Context) error
+	Deliveries(c echo.Context) error
+	Replay(c echo.Context) error
+}
+
+type webhookHandler struct {
+	Handler
+	service service.Webhook
+}
+
+// NewWebhook returns a new instance of the webhook handler.
+func NewWebhook(s service.Webhook) WebhookHandler {
+	return &webhookHandler{service: s}
+}
+
+// CreateWebhookRequest is the request parameter for subscribing a URL to todo events
+type CreateWebhookRequest struct {
+	URL    string            `json:"url" validate:"required,validWebhookURL,max=2048"`
+	Events []model.EventType `json:"events" validate:"required,min=1,dive,validEventType"`
+	// Secret is the HMAC key signing the deliveries, generated when omitted.
+	Secret string `json:"secret" validate:"omitempty,min=16,max=256"`
+}
+
+// CreatedWebhook is a new webhook, the only response carrying its secret
+type CreatedWebhook struct {
+	*model.Webhook
+	Secret string
+}
+
+// @Summary		Subscribe a URL to todo events
+// @Description	Every event is POSTed as JSON to the URL with the X-Webhook-Event, X-Webhook-Delivery and X-Webhook-Signature headers, the signature being "sha256=" followed by the hex encoded HMAC-SHA256 of the body keyed with the secret. The secret is only returned by this request. The URL must be http or https, and deliveries to private, loopback or link-local addresses fail unless the server allows them. Events: todo.created, todo.updated, todo.status_changed, todo.deleted, todo.restored.
+// @Tags			webhooks
+// @Security		BearerAuth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		CreateWebhookRequest	true	"json"
+// @Success		201		{object}	ResponseData{data=CreatedWebhook}
+// @Failure		400		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/webhooks [post]
+func (w *webhookHandler) Create(c echo.Context) error {
+	var req CreateWebhookRequest
+	if err := w.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	webhook, err := w.service.ForOwner(currentUser(c)).Create(req.URL, req.Events, req.Secret)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseData{Data: CreatedWebhook{Webhook: webhook, Secret: webhook.Secret}})
+}
+
+// WebhookRequest is the request parameter for a webhook
+type WebhookRequest struct {
+	ID int `param:"id" validate:"required"`
+}
+
+// @Summary	Delete a webhook
+// @Tags		webhooks
+// @Security	BearerAuth
+// @Param		path	path	WebhookRequest	false	"path"
+// @Success	204
+// @Failure	400	{object}	ResponseError
+// @Failure	404	{object}	ResponseError
+// @Failure	500	{object}	ResponseError
+// @Router		/webhooks/{id} [delete]
+func (w *webhookHandler) Delete(c echo.Context) error {
+	var req WebhookRequest
+	if err := w.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	if err := w.service.ForOwner(currentUser(c)).Delete(req.ID); err != nil {
+		return webhookError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary	Find a webhook
+// @Tags		webhooks
+// @Security	BearerAuth
+// @Param		path	path		WebhookRequest	false	"path"
+// @Success	200		{object}	ResponseData{Data=model.Webhook}
+// @Failure	400		{object}	ResponseError
+// @Failure	404		{object}	ResponseError
+// @Failure	500		{object}	ResponseError
+// @Router		/webhooks/{id} [get]
+func (w *webhookHandler) Find(c echo.Context) error {
+	var req WebhookRequest
+	if err := w.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := w.service.ForOwner(currentUser(c)).Find(req.ID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// @Summary	Find all webhooks
+// @Tags		webhooks
+// @Security	BearerAuth
+// @Success	200	{object}	ResponseData{Data=[]model.Webhook}
+// @Failure	500	{object}	ResponseError
+// @Router		/webhooks [get]
+func (w *webhookHandler) FindAll(c echo.Context) error {
+	res, err := w.service.ForOwner(currentUser(c)).FindAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// DeliveriesRequest is the request parameter for listing the deliveries of a webhook
+type DeliveriesRequest struct {
+	ID    int `param:"id" validate:"required"`
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// @Summary		Find the recent deliveries of a webhook
+// @Description	Newest first, with the outcome of their last attempt. Pending deliveries are retried with an exponential backoff until they succeed or fail.
+// @Tags			webhooks
+// @Security		BearerAuth
+// @Param			path	path		DeliveriesRequest	false	"path"
+// @Param			limit	query		int					false	"Maximum number of deliveries (1-100, default 20)"
+// @Success		200		{object}	ResponseData{Data=[]model.Delivery}
+// @Failure		400		{object}	ResponseError
+// @Failure		404		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/webhooks/{id}/deliveries [get]
+func (w *webhookHandler) Deliveries(c echo.Context) error {
+	var req DeliveriesRequest
+	if err := w.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultDeliveryLimit
+	}
+
+	res, err := w.service.ForOwner(currentUser(c)).Deliveries(req.ID, req.Limit)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, ResponseData{Data: res})
+}
+
+// ReplayRequest is the request parameter for replaying a delivery
+type ReplayRequest struct {
+	ID         int `param:"id" validate:"required"`
+	DeliveryID int `param:"delivery_id" validate:"required"`
+}
+
+// @Summary		Replay a failed delivery
+// @Description	Queues the payload of a failed delivery again as a new delivery, sent right away.
+// @Tags			webhooks
+// @Security		BearerAuth
+// @Param			path	path		ReplayRequest	false	"path"
+// @Success		202		{object}	ResponseData{Data=model.Delivery}
+// @Failure		400		{object}	ResponseError
+// @Failure		404		{object}	ResponseError
+// @Failure		409		{object}	ResponseError
+// @Failure		500		{object}	ResponseError
+// @Router			/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (w *webhookHandler) Replay(c echo.Context) error {
+	var req ReplayRequest
+	if err := w.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	res, err := w.service.ForOwner(currentUser(c)).Replay(req.ID, req.DeliveryID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, ResponseData{Data: res})
+}
+
+// webhookError responds with the status matching an error of the webhook service.
+func webhookError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return c.JSON(http.StatusNotFound,
+			ResponseError{Errors: []Error{{Code: errors.CodeNotFound, Message: "webhook or delivery not found"}}})
+	case model.ErrDeliveryNotFailed:
+		return c.JSON(http.StatusConflict,
+			ResponseError{Errors: []Error{{Code: errors.CodeConflict, Message: err.Error()}}})
+	}
+	return c.JSON(http.StatusInternalServerError,
+		ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Webhook{}, model.Delivery{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "webhooks@example.com")
+	otherToken := login(t, e, "webhooks-other@example.com")
+
+	call := func(token, method, target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var created struct {
+		Data struct {
+			ID     int
+			URL    string
+			Events []model.EventType
+			Secret string
+		}
+	}
+	rec := call(token, http.MethodPost, "/api/v1/webhooks", echo.MIMEApplicationJSON,
+		`{"url":"http://localhost:9999/hook", "events":["todo.created","todo.status_changed","todo.deleted"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Len(t, created.Data.Secret, 64)
+	hook := "/api/v1/webhooks/" + strconv.Itoa(created.Data.ID)
+
+	deliveries := func() []*model.Delivery {
+		rec := call(token, http.MethodGet, hook+"/deliveries", "", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var res struct {
+			Data []*model.Delivery
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return res.Data
+	}
+
+	t.Run("create_invalid", func(t *testing.T) {
+		for _, body := range []string{
+			`{"url":"not a url", "events":["todo.created"]}`,
+			`{"url":"ftp://localhost/hook", "events":["todo.created"]}`,
+			`{"url":"file:///etc/passwd", "events":["todo.created"]}`,
+			`{"url":"/relative/hook", "events":["todo.created"]}`,
+			`{"url":"http://localhost/hook", "events":[]}`,
+			`{"url":"http://localhost/hook", "events":["todo.archived"]}`,
+			`{"url":"http://localhost/hook", "events":["todo.created"], "secret":"short"}`,
+		} {
+			rec := call(token, http.MethodPost, "/api/v1/webhooks", echo.MIMEApplicationJSON, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		}
+	})
+
+	t.Run("find", func(t *testing.T) {
+		rec := call(token, http.MethodGet, hook, "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), created.Data.Secret)
+		assert.Contains(t, rec.Body.String(), `"URL":"http://localhost:9999/hook"`)
+
+		rec = call(otherToken, http.MethodGet, hook, "", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = call(otherToken, http.MethodGet, "/api/v1/webhooks", "", "")
+		assert.Equal(t, `{"data":[]}`, strings.TrimSpace(rec.Body.String()))
+	})
+
+	t.Run("events", func(t *testing.T) {
+		rec := call(token, http.MethodPost, "/api/v1/todos", echo.MIMEApplicationJSON, `{"task":"Hooked", "priority":1}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var todo struct {
+			Data model.Todo
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &todo))
+		target := "/api/v1/todos/" + strconv.Itoa(todo.Data.ID)
+		rec = call(token, http.MethodPatch, target, mimeMergePatch, `{"task":"Renamed"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = call(token, http.MethodPatch, target, mimeMergePatch, `{"status":"processing"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = call(token, http.MethodDelete, target, "", "")
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		// The todos of other users are not notified.
+		rec = call(otherToken, http.MethodPost, "/api/v1/todos", echo.MIMEApplicationJSON, `{"task":"Not hooked", "priority":1}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		res := deliveries()
+		require.Len(t, res, 3)
+		assert.Equal(t, model.TodoDeleted, res[0].Event)
+		assert.Equal(t, model.TodoStatusChanged, res[1].Event)
+		assert.Equal(t, model.TodoCreated, res[2].Event)
+		for _, d := range res {
+			assert.Equal(t, model.PendingDelivery, d.Status)
+		}
+		var event model.Event
+		require.NoError(t, json.Unmarshal(res[1].Payload, &event))
+		assert.Equal(t, todo.Data.ID, event.TodoID)
+		assert.Equal(t, model.Processing, event.Todo.Status)
+		assert.Equal(t, json.RawMessage(`"processing"`), event.Changes["Status"].After)
+
+		rec = call(token, http.MethodGet, hook+"/deliveries?limit=1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), `"Event"`))
+		rec = call(token, http.MethodGet, hook+"/deliveries?limit=1000", "", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("rolled_back_changes_are_not_delivered", func(t *testing.T) {
+		before := len(deliveries())
+		rec := call(token, http.MethodPost, "/api/v1/todos:batch", echo.MIMEApplicationJSON,
+			`{"operations":[{"op":"create","body":{"task":"Rolled back","priority":1}},{"op":"delete","id":-1}]}`)
+		require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+		assert.Len(t, deliveries(), before)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		pending := deliveries()[0]
+		rec := call(token, http.MethodPost, hook+"/deliveries/"+strconv.Itoa(pending.ID)+"/replay", "", "")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		require.NoError(t, dbInstance.Model(&model.Delivery{}).Where("id = ?", pending.ID).
+			Updates(map[string]interface{}{"status": model.FailedDelivery, "attempts": 8, "next_attempt_at": nil}).Error)
+		rec = call(otherToken, http.MethodPost, hook+"/deliveries/"+strconv.Itoa(pending.ID)+"/replay", "", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = call(token, http.MethodPost, hook+"/deliveries/"+strconv.Itoa(pending.ID)+"/replay", "", "")
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		var replay struct {
+			Data model.Delivery
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &replay))
+		assert.Equal(t, model.PendingDelivery, replay.Data.Status)
+		require.NotNil(t, replay.Data.ReplayOf)
+		assert.Equal(t, pending.ID, *replay.Data.ReplayOf)
+		assert.JSONEq(t, string(pending.Payload), string(replay.Data.Payload))
+		assert.Equal(t, replay.Data.ID, deliveries()[0].ID)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rec := call(otherToken, http.MethodDelete, hook, "", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = call(token, http.MethodDelete, hook, "", "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		rec = call(token, http.MethodGet, hook+"/deliveries", "", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		var count int64
+		require.NoError(t, dbInstance.Model(&model.Delivery{}).Where("webhook_id = ?", created.Data.ID).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
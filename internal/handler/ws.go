@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/event"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Limits of a WebSocket connection.
+const (
+	// wsQueueSize is the number of messages a client can lag behind before it is disconnected.
+	wsQueueSize = 64
+	// wsMaxMessageSize is the largest message accepted from a client.
+	wsMaxMessageSize = 64 << 10
+	// wsWriteTimeout is how long writing a message to a client may take.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// wsPingInterval is how often clients are pinged, they are disconnected when they fail to answer
+// before the next ping.
+var wsPingInterval = 30 * time.Second
+
+// Types of the messages sent by clients.
+const (
+	subscribeMessage   = "subscribe"
+	unsubscribeMessage = "unsubscribe"
+	commandMessage     = "command"
+	presenceMessage    = "presence"
+)
+
+// Types of the messages sent to clients, besides presence.
+const (
+	welcomeMessage    = "welcome"
+	subscribedMessage = "subscribed"
+	resultMessage     = "result"
+	eventMessage      = "event"
+	errorMessage      = "error"
+)
+
+// WSClientMessage is a message sent by a client over the WebSocket
+type WSClientMessage struct {
+	Type string `json:"type" validate:"required,oneof=subscribe unsubscribe command presence"`
+	// ID is echoed in the reply to the message.
+	ID string `json:"id,omitempty" validate:"max=100"`
+	// Lists are the projects to subscribe to or unsubscribe from, 0 being the todos without a project.
+	Lists []int `json:"lists,omitempty" validate:"max=100,dive,min=0"`
+	// All subscribes to or unsubscribes from every list.
+	All bool `json:"all,omitempty"`
+	// Command is the operation a command message runs, as an operation of a batch.
+	Command *BatchOperation `json:"command,omitempty" validate:"required_if=Type command"`
+	// TodoID is the todo a presence message is about.
+	TodoID int           `json:"todo_id,omitempty" validate:"min=0"`
+	State  PresenceState `json:"state,omitempty" validate:"omitempty,oneof=online viewing editing"`
+}
+
+// WSServerMessage is a message sent to a client over the WebSocket
+type WSServerMessage struct {
+	Type string `json:"type"`
+	// ID is the id of the client message replied to.
+	ID string `json:"id,omitempty"`
+	// Connection identifies the connection in presence messages, sent on welcome.
+	Connection string `json:"connection,omitempty"`
+	// Lists are the subscribed lists, nil when subscribed to every list.
+	Lists    []int            `json:"lists,omitempty"`
+	All      bool             `json:"all,omitempty"`
+	Result   *BatchResult     `json:"result,omitempty"`
+	Event    *model.TodoEvent `json:"event,omitempty"`
+	Presence []Presence       `json:"presence,omitempty"`
+	Errors   []Error          `json:"errors,omitempty"`
+}
+
+// WSHandler is the request handler for the WebSocket endpoint.
+type WSHandler interface {
+	Connect(c echo.Context) error
+}
+
+type wsHandler struct {
+	Handler
+	service  service.Todo
+	broker   *event.Broker
+	presence *presenceHub
+	upgrader websocket.Upgrader
+	lastID   int64
+}
+
+// NewWS returns a new instance of the WebSocket handler.
+func NewWS(s service.Todo, b *event.Broker) WSHandler {
+	return &wsHandler{
+		service:  s,
+		broker:   b,
+		presence: newPresenceHub(),
+		upgrader: websocket.Upgrader{
+			// Clients authenticate with a token rather than cookies, any origin is safe.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+}
+
+// WSRequest is the request parameter for opening a WebSocket
+type WSRequest struct {
+	// Client is a name telling the client apart in presence messages.
+	Client string `query:"client" validate:"max=100"`
+}
+
+// @Summary		Open a WebSocket for live editing
+// @Description	Messages are JSON objects. Clients send "subscribe" and "unsubscribe" with lists (project ids, 0 for the todos without a project) or all, "command" with a batch operation run through the todo service, and "presence" with a todo_id and a state (online, viewing, editing).
+// @Description	The server sends "welcome" with the connection id, "subscribed", "result" with the outcome of a command, "event" with a model.TodoEvent of the subscribed lists, "presence" with the presence of every client of the user and "error".
+// @Description	Commands require a credential allowed to write, read-only API keys get an error message.
+// @Description	A client falling behind the messages sent to it is disconnected with the close code 1013. Browsers can send the credential as the access_token query parameter.
+// @Tags			todos
+// @Security		BearerAuth
+// @Param			client			query	string	false	"Name of the client shown in presence messages"
+// @Param			access_token	query	string	false	"Access token or API key, for clients unable to set the Authorization header"
+// @Success		101
+// @Failure		400	{object}	ResponseError
+// @Failure		503	{object}	ResponseError
+// @Router			/ws [get]
+func (h *wsHandler) Connect(c echo.Context) error {
+	var req WSRequest
+	if err := h.MustBind(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest,
+			ResponseError{Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+	}
+
+	userID := currentUser(c)
+	sub, err := h.broker.Subscribe(userID, nil)
+	if err != nil {
+		if err == event.ErrClosed {
+			return c.JSON(http.StatusServiceUnavailable,
+				ResponseError{Errors: []Error{{Code: errors.CodeServiceUnavailable, Message: err.Error()}}})
+		}
+		return c.JSON(http.StatusInternalServerError,
+			ResponseError{Errors: []Error{{Code: errors.CodeInternalServerError, Message: err.Error()}}})
+	}
+	defer sub.Close()
+
+	ws, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// The upgrader already responded with the error.
+		return nil
+	}
+	conn := &wsConn{
+		id:     strconv.FormatInt(atomic.AddInt64(&h.lastID, 1), 10),
+		userID: userID,
+		scope:  currentScope(c),
+		client: req.Client,
+		ws:     ws,
+		send:   make(chan WSServerMessage, wsQueueSize),
+		done:   make(chan struct{}),
+	}
+	go conn.writeLoop()
+	conn.enqueue(WSServerMessage{Type: welcomeMessage, Connection: conn.id})
+	h.presence.join(conn)
+	defer h.presence.leave(conn)
+	go conn.forward(sub)
+
+	conn.readLoop(func(msg *WSClientMessage) {
+		h.handle(c, conn, msg)
+	})
+	return nil
+}
+
+// handle runs a message of a client.
+func (h *wsHandler) handle(c echo.Context, conn *wsConn, msg *WSClientMessage) {
+	if err := c.Validate(msg); err != nil {
+		conn.enqueue(WSServerMessage{Type: errorMessage, ID: msg.ID,
+			Errors: []Error{{Code: errors.CodeBadRequest, Message: err.Error()}}})
+		return
+	}
+	switch msg.Type {
+	case subscribeMessage, unsubscribeMessage:
+		all, lists := conn.subscribe(msg.Type == subscribeMessage, msg.All, msg.Lists)
+		conn.enqueue(WSServerMessage{Type: subscribedMessage, ID: msg.ID, All: all, Lists: lists})
+	case commandMessage:
+		// The upgrade is a GET, which read-only credentials are allowed, while every command changes todos.
+		if !conn.scope.Allows(http.MethodPost) {
+			conn.enqueue(WSServerMessage{Type: errorMessage, ID: msg.ID,
+				Errors: []Error{{Code: errors.CodeForbidden, Message: model.ErrInsufficientScope.Error()}}})
+			return
+		}
+		result := runOperation(c, h.service.ForOwner(conn.userID), *msg.Command)
+		conn.enqueue(WSServerMessage{Type: resultMessage, ID: msg.ID, Result: &result})
+	case presenceMessage:
+		p := Presence{Connection: conn.id, Client: conn.client, State: msg.State}
+		switch {
+		case msg.State == "":
+			conn.enqueue(WSServerMessage{Type: errorMessage, ID: msg.ID,
+				Errors: []Error{{Code: errors.CodeBadRequest, Message: "a presence message requires a state"}}})
+			return
+		case msg.State != OnlinePresence:
+			// Clients can only tell that they are looking at the todos of their user.
+			if _, err := h.service.ForOwner(conn.userID).Find(msg.TodoID); err != nil {
+				_, e := todoError(err)
+				conn.enqueue(WSServerMessage{Type: errorMessage, ID: msg.ID, Errors: []Error{e}})
+				return
+			}
+			p.TodoID = msg.TodoID
+		}
+		h.presence.set(conn, p)
+	}
+}
+
+// wsConn is a WebSocket connection of a client.
+type wsConn struct {
+	id     string
+	userID int
+	// scope is the scope of the credential the connection was opened with.
+	scope  model.Scope
+	client string
+	ws     *websocket.Conn
+	// send queues the messages to the client, which is disconnected when it is full.
+	send      chan WSServerMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	all   bool
+	lists map[int]bool
+}
+
+// enqueue queues a message to the client, disconnecting it when it lags behind. It never blocks, the
+// connection of a lagging client is closed in the background.
+func (c *wsConn) enqueue(msg WSServerMessage) {
+	select {
+	case <-c.done:
+	case c.send <- msg:
+	default:
+		c.closeOnce.Do(func() {
+			log.Warnf("disconnecting the WebSocket client %s lagging behind", c.id)
+			close(c.done)
+			go c.disconnect(websocket.CloseTryAgainLater, "too many pending messages")
+		})
+	}
+}
+
+// close sends a close message to the client and closes the connection.
+func (c *wsConn) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.disconnect(code, text)
+	})
+}
+
+// disconnect sends a close message to the client, waiting up to wsWriteTimeout, and closes the connection.
+func (c *wsConn) disconnect(code int, text string) {
+	_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text),
+		time.Now().Add(wsWriteTimeout))
+	_ = c.ws.Close()
+}
+
+// readLoop calls handle with the messages of the client until the connection is closed.
+func (c *wsConn) readLoop(handle func(msg *WSClientMessage)) {
+	defer c.close(websocket.CloseNormalClosure, "")
+	c.ws.SetReadLimit(wsMaxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(2 * wsPingInterval))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(2 * wsPingInterval))
+	})
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.enqueue(WSServerMessage{Type: errorMessage,
+				Errors: []Error{{Code: errors.CodeBadRequest, Message: "invalid message: " + err.Error()}}})
+			continue
+		}
+		handle(&msg)
+	}
+}
+
+// writeLoop writes the queued messages and the pings until the connection is closed.
+func (c *wsConn) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := c.ws.WriteJSON(msg); err != nil {
+				c.close(websocket.CloseInternalServerErr, "")
+				return
+			}
+		case <-ping.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.close(websocket.CloseInternalServerErr, "")
+				return
+			}
+		}
+	}
+}
+
+// forward queues the events of the subscribed lists until the connection or the subscription ends.
+func (c *wsConn) forward(sub *event.Subscription) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-sub.Done():
+			c.close(websocket.CloseGoingAway, "event stream ended")
+			return
+		case e := <-sub.Events():
+			if c.subscribes(e) {
+				c.enqueue(WSServerMessage{Type: eventMessage, Event: e})
+			}
+		}
+	}
+}
+
+// subscribe adds or removes lists, returning the subscribed ones.
+func (c *wsConn) subscribe(add bool, all bool, lists []int) (bool, []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lists == nil {
+		c.lists = make(map[int]bool)
+	}
+	if all {
+		c.all = add
+		c.lists = make(map[int]bool)
+	}
+	for _, list := range lists {
+		if add {
+			c.lists[list] = true
+		} else {
+			delete(c.lists, list)
+		}
+	}
+	if c.all {
+		return true, nil
+	}
+	res := make([]int, 0, len(c.lists))
+	for list := range c.lists {
+		res = append(res, list)
+	}
+	sort.Ints(res)
+	return false, res
+}
+
+// subscribes reports whether an event is about a subscribed list, before or after the change.
+func (c *wsConn) subscribes(e *model.TodoEvent) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.all {
+		return true
+	}
+	if e.Todo != nil && c.lists[listOf(e.Todo.ProjectID)] {
+		return true
+	}
+	if change, ok := e.Changes["ProjectID"]; ok {
+		var before *int
+		if json.Unmarshal(change.Before, &before) == nil && c.lists[listOf(before)] {
+			return true
+		}
+	}
+	return false
+}
+
+// listOf returns the list of a todo, 0 for the todos without a project.
+func listOf(projectID *int) int {
+	if projectID == nil {
+		return 0
+	}
+	return *projectID
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/errors"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectMessage reads the messages of a WebSocket until one of the given type.
+func expectMessage(t *testing.T, ws *websocket.Conn, typ string) WSServerMessage {
+	t.Helper()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		var msg WSServerMessage
+		require.NoError(t, ws.ReadJSON(&msg))
+		if msg.Type == typ {
+			return msg
+		}
+	}
+}
+
+func TestWSHandler(t *testing.T) {
+	e := echo.New()
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Project{}) })
+	Register(e, dbInstance, testAuthConfig)
+	token := login(t, e, "ws@example.com")
+
+	e.Listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = e.Start("") }()
+	url := "ws://" + e.Listener.Addr().String() + "/api/v1/ws"
+
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	laptop, _, err := websocket.DefaultDialer.Dial(url+"?client=laptop", http.Header{echo.HeaderAuthorization: {"Bearer " + token}})
+	require.NoError(t, err)
+	defer laptop.Close()
+	welcome := expectMessage(t, laptop, welcomeMessage)
+	assert.Equal(t, []Presence{{Connection: welcome.Connection, Client: "laptop", State: OnlinePresence}},
+		expectMessage(t, laptop, presenceMessage).Presence)
+
+	// Browsers send the credential in the query.
+	phone, _, err := websocket.DefaultDialer.Dial(url+"?client=phone&access_token="+token, nil)
+	require.NoError(t, err)
+	defer phone.Close()
+	phoneID := expectMessage(t, phone, welcomeMessage).Connection
+	assert.Len(t, expectMessage(t, laptop, presenceMessage).Presence, 2)
+
+	send := func(ws *websocket.Conn, msg string) {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(msg)))
+	}
+	send(laptop, `{"type":"subscribe", "id":"s1", "lists":[0]}`)
+	subscribed := expectMessage(t, laptop, subscribedMessage)
+	assert.Equal(t, "s1", subscribed.ID)
+	assert.Equal(t, []int{0}, subscribed.Lists)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/projects", strings.NewReader(`{"name":"Elsewhere"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var project struct {
+		Data model.Project
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &project))
+
+	t.Run("commands", func(t *testing.T) {
+		send(phone, `{"type":"command", "id":"c1", "command":{"op":"create", "body":{"task":"Elsewhere", "priority":1, "project_id":`+
+			strconv.Itoa(project.Data.ID)+`}}}`)
+		assert.Equal(t, http.StatusCreated, expectMessage(t, phone, resultMessage).Result.Status)
+		send(phone, `{"type":"command", "id":"c2", "command":{"op":"create", "body":{"task":"Inbox", "priority":1}}}`)
+		result := expectMessage(t, phone, resultMessage)
+		assert.Equal(t, "c2", result.ID)
+		require.Equal(t, http.StatusCreated, result.Result.Status)
+		require.NotNil(t, result.Result.Data)
+
+		// Only the todo of the subscribed list is sent.
+		event := expectMessage(t, laptop, eventMessage).Event
+		assert.Equal(t, model.TodoCreated, event.Type)
+		assert.Equal(t, result.Result.Data.ID, event.TodoID)
+
+		send(phone, `{"type":"command", "id":"c3", "command":{"op":"patch", "id":`+strconv.Itoa(result.Result.Data.ID)+
+			`, "body":{"status":"processing"}}}`)
+		assert.Equal(t, http.StatusOK, expectMessage(t, phone, resultMessage).Result.Status)
+		event = expectMessage(t, laptop, eventMessage).Event
+		assert.Equal(t, model.TodoUpdated, event.Type)
+		assert.Contains(t, event.Changes, "Status")
+
+		send(phone, `{"type":"command", "id":"c4", "command":{"op":"create", "body":{"priority":1}}}`)
+		result = expectMessage(t, phone, resultMessage)
+		assert.Equal(t, http.StatusBadRequest, result.Result.Status)
+		send(phone, `{"type":"command", "id":"c5", "command":{"op":"delete", "id":-1}}`)
+		assert.Equal(t, http.StatusNotFound, expectMessage(t, phone, resultMessage).Result.Status)
+	})
+
+	t.Run("read_scope", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/apikeys", strings.NewReader(`{"name":"dashboard", "scope":"read"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var key struct {
+			Data struct {
+				Key string
+			}
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &key))
+
+		reader, _, err := websocket.DefaultDialer.Dial(url+"?client=dashboard", http.Header{echo.HeaderAuthorization: {"Bearer " + key.Data.Key}})
+		require.NoError(t, err)
+		defer reader.Close()
+		expectMessage(t, reader, welcomeMessage)
+		assert.Len(t, expectMessage(t, laptop, presenceMessage).Presence, 3)
+		send(reader, `{"type":"command", "id":"r1", "command":{"op":"create", "body":{"task":"Read only", "priority":1}}}`)
+		msg := expectMessage(t, reader, errorMessage)
+		assert.Equal(t, "r1", msg.ID)
+		assert.Equal(t, errors.CodeForbidden, msg.Errors[0].Code)
+		var n int64
+		require.NoError(t, dbInstance.Model(&model.Todo{}).Where("task = ?", "Read only").Count(&n).Error)
+		assert.Zero(t, n)
+
+		// Subscriptions only read.
+		send(reader, `{"type":"subscribe", "id":"r2", "all":true}`)
+		assert.True(t, expectMessage(t, reader, subscribedMessage).All)
+		require.NoError(t, reader.Close())
+		assert.Len(t, expectMessage(t, laptop, presenceMessage).Presence, 2)
+	})
+
+	t.Run("invalid_messages", func(t *testing.T) {
+		send(phone, `not json`)
+		assert.Equal(t, "BAD_REQUEST", expectMessage(t, phone, errorMessage).Errors[0].Code)
+		send(phone, `{"type":"shout", "id":"x1"}`)
+		assert.Equal(t, "x1", expectMessage(t, phone, errorMessage).ID)
+		send(phone, `{"type":"command", "id":"x2"}`)
+		assert.Equal(t, "x2", expectMessage(t, phone, errorMessage).ID)
+	})
+
+	t.Run("presence", func(t *testing.T) {
+		send(phone, `{"type":"presence", "id":"p1", "todo_id":-5, "state":"editing"}`)
+		assert.Equal(t, "p1", expectMessage(t, phone, errorMessage).ID)
+
+		var todo model.Todo
+		require.NoError(t, dbInstance.Where("task = ?", "Inbox").Take(&todo).Error)
+		send(phone, `{"type":"presence", "todo_id":`+strconv.Itoa(todo.ID)+`, "state":"editing"}`)
+		presence := expectMessage(t, laptop, presenceMessage).Presence
+		require.Len(t, presence, 2)
+		assert.Contains(t, presence, Presence{Connection: phoneID, Client: "phone", TodoID: todo.ID, State: EditingPresence})
+
+		require.NoError(t, phone.Close())
+		assert.Len(t, expectMessage(t, laptop, presenceMessage).Presence, 1)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, e.Shutdown(ctx))
+		require.NoError(t, laptop.SetReadDeadline(time.Now().Add(5*time.Second)))
+		for {
+			if _, _, err := laptop.ReadMessage(); err != nil {
+				assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+				break
+			}
+		}
+	})
+}
+
+func TestWSConn_Backpressure(t *testing.T) {
+	conns := make(chan *websocket.Conn)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		conns <- ws
+	}))
+	defer server.Close()
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Nothing writes the queue of the connection, the second message overflows it.
+	conn := &wsConn{ws: <-conns, send: make(chan WSServerMessage, 1), done: make(chan struct{})}
+	conn.enqueue(WSServerMessage{Type: eventMessage})
+	conn.enqueue(WSServerMessage{Type: eventMessage})
+	_, _, err = client.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err)
+	// Later messages are dropped.
+	conn.enqueue(WSServerMessage{Type: eventMessage})
+}
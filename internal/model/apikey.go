@@ -0,0 +1,84 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, telling them apart from access tokens.
+const APIKeyPrefix = "todo_"
+
+// Scope is the permission level of an API key.
+type Scope string
+
+const (
+	// ReadScope only allows reading.
+	ReadScope = Scope("read")
+	// WriteScope allows reading and writing.
+	WriteScope = Scope("write")
+)
+
+// APIKey is a long-lived credential of a user for scripts and CI.
+type APIKey struct {
+	ID   int `gorm:"primaryKey"`
+	Name string
+	// Prefix is the beginning of the key, shown to recognize it.
+	Prefix string
+	// Hash is the SHA-256 of the key, the key itself is never stored.
+	Hash       string `gorm:"uniqueIndex" json:"-"`
+	Scope      Scope
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time `json:"RevokedAt,omitempty"`
+	// UserID is the user owning the key.
+	UserID int `gorm:"index" json:"-"`
+}
+
+// NewAPIKey returns a new API key model together with the key, which is only available at this point.
+func NewAPIKey(name string, scope Scope) (*APIKey, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return &APIKey{
+		Name:   name,
+		Prefix: key[:len(APIKeyPrefix)+6],
+		Hash:   HashAPIKey(key),
+		Scope:  scope,
+	}, key, nil
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether the credential is an API key rather than an access token.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// Principal is the user a verified credential acts for and what the credential permits.
+type Principal struct {
+	UserID int
+	Scope  Scope
+	// APIKey tells whether the credential is an API key rather than an access token.
+	APIKey bool
+}
+
+// Allows reports whether the scope permits requests of the given HTTP method.
+func (s Scope) Allows(method string) bool {
+	switch s {
+	case WriteScope:
+		return true
+	case ReadScope:
+		return method == "GET" || method == "HEAD" || method == "OPTIONS"
+	}
+	return false
+}
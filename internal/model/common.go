@@ -4,3 +4,51 @@ import "fmt"
 
 // ErrNotFound is the error for not found.
 var ErrNotFound = fmt.Errorf("not found")
+
+// ErrInvalidSearchQuery is the error for a malformed full-text search query.
+var ErrInvalidSearchQuery = fmt.Errorf("invalid search query")
+
+// ErrRecurrenceWithoutDueDate is the error for a recurring todo without a due date to repeat from.
+var ErrRecurrenceWithoutDueDate = fmt.Errorf("recurrence requires a due date")
+
+// ErrRecurrenceScope is the error for changing the recurrence of a single occurrence.
+var ErrRecurrenceScope = fmt.Errorf("recurrence can only be changed for future occurrences")
+
+// ErrParentNotFound is the error for a subtask whose parent todo does not exist.
+var ErrParentNotFound = fmt.Errorf("parent todo not found")
+
+// ErrParentCycle is the error for moving a todo under itself or one of its subtasks.
+var ErrParentCycle = fmt.Errorf("a todo cannot be a subtask of itself or of its subtasks")
+
+// ErrHasChildren is the error for deleting a todo that still has subtasks.
+var ErrHasChildren = fmt.Errorf("todo has subtasks")
+
+// ErrOpenChildren is the error for completing a todo whose subtasks are still open.
+var ErrOpenChildren = fmt.Errorf("todo has open subtasks")
+
+// ErrDuplicate is the error for creating a resource whose unique name is already taken.
+var ErrDuplicate = fmt.Errorf("already exists")
+
+// ErrProjectNotFound is the error for a todo referring to a project that does not exist.
+var ErrProjectNotFound = fmt.Errorf("project not found")
+
+// ErrInvalidCredentials is the error for a login with an unknown email or a wrong password.
+var ErrInvalidCredentials = fmt.Errorf("invalid email or password")
+
+// ErrInvalidToken is the error for a missing, malformed or expired access token.
+var ErrInvalidToken = fmt.Errorf("invalid access token")
+
+// ErrInsufficientScope is the error for a request the scope of its API key does not permit.
+var ErrInsufficientScope = fmt.Errorf("the API key does not permit this request")
+
+// ErrSessionRequired is the error for a request authenticated by an API key to an endpoint requiring an access token.
+var ErrSessionRequired = fmt.Errorf("this request requires an access token, API keys are not accepted")
+
+// ErrRevisionNotFound is the error for reverting a todo to a revision it does not have.
+var ErrRevisionNotFound = fmt.Errorf("revision not found")
+
+// ErrVersionMismatch is the error for changing a todo that was modified since the client read it.
+var ErrVersionMismatch = fmt.Errorf("todo was modified since it was read")
+
+// ErrDeliveryNotFailed is the error for replaying a webhook delivery that did not fail.
+var ErrDeliveryNotFailed = fmt.Errorf("only failed deliveries can be replayed")
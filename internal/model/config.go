@@ -1,12 +1,38 @@
 // Package model provides the data models for the application.
 package model
 
+import "time"
+
 // Config is the configuration for the application.
 type Config struct {
 	UI            UI
 	APIServer     Server
 	SwaggerServer Server
-	SQLite        SQLite
+	GRPCServer    Server
+	Database      Database
+	Auth          Auth
+	Trash         Trash
+	Webhooks      Webhooks
+	// SQLite is the database of the configurations written before Database.
+	//
+	// Deprecated: set Database instead.
+	SQLite SQLite
+}
+
+// SQLite is the former configuration for the SQLite database.
+type SQLite struct {
+	DBFilename string
+}
+
+// ApplySQLite uses the SQLite database of a former configuration when no database is configured,
+// reporting whether it did.
+func (c *Config) ApplySQLite() bool {
+	if c.Database.DSN != "" || c.SQLite.DBFilename == "" {
+		return false
+	}
+	c.Database.Driver = "sqlite"
+	c.Database.DSN = c.SQLite.DBFilename
+	return true
 }
 
 // UI is the configuration for the UI.
@@ -20,7 +46,49 @@ type Server struct {
 	Port   int
 }
 
-// SQLite is the configuration for the SQLite database.
-type SQLite struct {
-	DBFilename string `validate:"required"`
+// Database is the configuration for the database.
+type Database struct {
+	// Driver is sqlite or postgres.
+	Driver string `validate:"oneof=sqlite postgres"`
+	// DSN is the file of a SQLite database or the connection string of a Postgres one.
+	DSN string `validate:"required"`
+	// MaxOpenConns caps the open connections, zero for no limit.
+	MaxOpenConns int
+	// MaxIdleConns is the number of idle connections kept in the pool, zero for the default of 2.
+	MaxIdleConns int
+	// ConnMaxLifetime is how long a connection is reused before being closed, zero for ever.
+	ConnMaxLifetime time.Duration
+}
+
+// Auth is the configuration for user authentication.
+type Auth struct {
+	// SigningKey is the HMAC key signing the access tokens.
+	SigningKey string `validate:"required,min=32"`
+	// TokenTTL is how long an access token stays valid.
+	TokenTTL time.Duration
+}
+
+// Trash is the configuration for deleted todos.
+type Trash struct {
+	// Retention is how long deleted todos are kept before being purged, zero to keep them forever.
+	Retention time.Duration
+	// SweepInterval is how often expired todos are purged.
+	SweepInterval time.Duration
+}
+
+// Webhooks is the configuration for the delivery of webhook events.
+type Webhooks struct {
+	// PollInterval is how often the queue is checked for due deliveries.
+	PollInterval time.Duration
+	// Timeout is how long a webhook has to answer a delivery.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery fails.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for every following one.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two attempts, 0 leaves it uncapped.
+	MaxBackoff time.Duration
+	// AllowPrivateTargets lets deliveries reach private, loopback and link-local addresses, such as
+	// services on the host of the server.
+	AllowPrivateTargets bool
 }
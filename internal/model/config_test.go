@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_ApplySQLite(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		applied bool
+		want    Database
+	}{
+		{
+			name:    "former_configuration",
+			config:  Config{Database: Database{Driver: "sqlite"}, SQLite: SQLite{DBFilename: "tmp/gorm.db"}},
+			applied: true,
+			want:    Database{Driver: "sqlite", DSN: "tmp/gorm.db"},
+		},
+		{
+			name:   "database_wins",
+			config: Config{Database: Database{Driver: "postgres", DSN: "host=db"}, SQLite: SQLite{DBFilename: "tmp/gorm.db"}},
+			want:   Database{Driver: "postgres", DSN: "host=db"},
+		},
+		{
+			name:   "neither",
+			config: Config{Database: Database{Driver: "sqlite"}},
+			want:   Database{Driver: "sqlite"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.applied, tt.config.ApplySQLite())
+			assert.Equal(t, tt.want, tt.config.Database)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// TodoEvent is a change to a todo read back from its revision once it was saved.
+type TodoEvent struct {
+	// ID is the id of the revision, increasing with every change.
+	ID     int
+	Type   EventType
+	TodoID int
+	// UserID is the user owning the todo.
+	UserID     int `json:"-"`
+	OccurredAt time.Time
+	// Changes are the fields changed by the revision.
+	Changes Changes `json:"Changes,omitempty"`
+	// Todo is the todo as it was when the event was read, trashed ones included. It is nil once purged.
+	Todo *Todo `json:"Todo,omitempty"`
+}
+
+// NewTodoEvent returns the event of a revision of the given todo.
+func NewTodoEvent(rev *Revision, todo *Todo) *TodoEvent {
+	e := &TodoEvent{
+		ID:         rev.ID,
+		Type:       RevisionEvents(rev)[0],
+		TodoID:     rev.TodoID,
+		UserID:     rev.ActorID,
+		OccurredAt: rev.CreatedAt,
+		Todo:       todo,
+	}
+	if len(rev.Changes) > 0 && rev.Action != CreateAction {
+		e.Changes = rev.Changes
+	}
+	return e
+}
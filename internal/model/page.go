@@ -0,0 +1,44 @@
+package model
+
+import "fmt"
+
+// DefaultPageLimit is the number of items returned when no limit is requested.
+const DefaultPageLimit = 50
+
+// ErrInvalidCursor is the error for a malformed or foreign pagination cursor.
+var ErrInvalidCursor = fmt.Errorf("invalid cursor")
+
+// PageRequest is the keyset pagination request for list queries.
+type PageRequest struct {
+	// Limit is the maximum number of items to return.
+	Limit int
+	// Cursor is the opaque position returned as NextCursor by the previous page.
+	Cursor string
+	// WithTotal requests the total number of items matching the filters.
+	WithTotal bool
+	// Sort is the requested ordering, empty for the default one.
+	Sort []SortField
+}
+
+// NewPageRequest returns a new page request, applying the default limit when none is given.
+func NewPageRequest(limit int, cursor string, withTotal bool, sort []SortField) PageRequest {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	return PageRequest{
+		Limit:     limit,
+		Cursor:    cursor,
+		WithTotal: withTotal,
+		Sort:      sort,
+	}
+}
+
+// TodoPage is a single page of todos.
+type TodoPage struct {
+	// Todos are the todos on this page.
+	Todos []*Todo
+	// NextCursor is the cursor of the following page, empty on the last page.
+	NextCursor string
+	// Total is the number of todos matching the filters, set only when requested.
+	Total *int64
+}
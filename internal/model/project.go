@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Project is a list grouping todos.
+type Project struct {
+	ID    int `gorm:"primaryKey"`
+	Name  string
+	Color string
+	// Archived projects are kept but their todos are hidden from the default todo list.
+	Archived bool `gorm:"index"`
+	// Position orders the projects, lowest first.
+	Position  int
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	// UserID is the user owning the project.
+	UserID int `gorm:"index" json:"-"`
+}
+
+// NewProject returns a new instance of the project model.
+func NewProject(name, color string, position int) *Project {
+	return &Project{
+		Name:     name,
+		Color:    color,
+		Position: position,
+	}
+}
+
+// NewUpdateProject returns a new instance of the project model for updating.
+func NewUpdateProject(id int, name, color string) *Project {
+	return &Project{
+		ID:    id,
+		Name:  name,
+		Color: color,
+	}
+}
+
+// ProjectToken returns the project name as a single word, for file formats such as
+// todo.txt where names cannot contain whitespace.
+func ProjectToken(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// MatchesToken reports whether the project is the one a token names, ignoring case.
+func (p *Project) MatchesToken(token string) bool {
+	return strings.EqualFold(ProjectToken(p.Name), ProjectToken(token))
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// RevisionAction is the kind of change a revision records.
+type RevisionAction string
+
+const (
+	// CreateAction is the action for a created todo.
+	CreateAction = RevisionAction("create")
+	// UpdateAction is the action for an updated todo.
+	UpdateAction = RevisionAction("update")
+	// DeleteAction is the action for a todo moved to the trash.
+	DeleteAction = RevisionAction("delete")
+	// RestoreAction is the action for a todo restored from the trash.
+	RestoreAction = RevisionAction("restore")
+	// RevertAction is the action for a todo reverted to an earlier revision.
+	RevertAction = RevisionAction("revert")
+)
+
+// Revision is an immutable record of a change made to a todo.
+type Revision struct {
+	ID     int `gorm:"primaryKey"`
+	TodoID int `gorm:"index"`
+	Action RevisionAction
+	// ActorID is the user who made the change.
+	ActorID   int
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	// Changes are the fields changed by the revision.
+	Changes Changes `gorm:"serializer:json"`
+	// RevertedTo is the revision a revert restored the todo to.
+	RevertedTo *int `json:"RevertedTo,omitempty"`
+}
+
+// NewRevision returns a new revision of the todo going from the before to the after state.
+func NewRevision(todoID int, action RevisionAction, before, after TodoState) *Revision {
+	return &Revision{
+		TodoID:  todoID,
+		Action:  action,
+		Changes: DiffTodoStates(before, after),
+	}
+}
+
+// FieldChange is the value of a field before and after a revision.
+type FieldChange struct {
+	Before json.RawMessage `swaggertype:"object"`
+	After  json.RawMessage `swaggertype:"object"`
+}
+
+// Changes are the changed fields of a revision by their name.
+type Changes map[string]FieldChange
+
+// TodoState is the part of a todo tracked by its revisions.
+type TodoState struct {
+	Task         string
+	Status       Status
+	Priority     Priority
+	DueAt        *time.Time
+	ScheduledFor *time.Time
+	ParentID     *int
+	ProjectID    *int
+	Tags         []string
+}
+
+// NewTodoState returns the tracked state of a todo.
+func NewTodoState(t *Todo) TodoState {
+	s := TodoState{
+		Task:         t.Task,
+		Status:       t.Status,
+		Priority:     t.Priority,
+		DueAt:        utc(t.DueAt),
+		ScheduledFor: utc(t.ScheduledFor),
+		ParentID:     t.ParentID,
+		ProjectID:    t.ProjectID,
+	}
+	if len(t.Tags) > 0 {
+		s.Tags = t.Tags.Names()
+		sort.Strings(s.Tags)
+	}
+	return s
+}
+
+// fields returns the JSON encoded value of every field of the state.
+func (s TodoState) fields() (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// DiffTodoStates returns the fields that differ between two states.
+func DiffTodoStates(before, after TodoState) Changes {
+	changes := Changes{}
+	// Encoding the plain fields of TodoState cannot fail.
+	b, _ := before.fields()
+	a, _ := after.fields()
+	for name, value := range a {
+		if !bytes.Equal(b[name], value) {
+			changes[name] = FieldChange{Before: b[name], After: value}
+		}
+	}
+	return changes
+}
+
+// Apply returns the state with the changes applied.
+func (c Changes) Apply(s TodoState) (TodoState, error) {
+	fields, err := s.fields()
+	if err != nil {
+		return s, err
+	}
+	for name, change := range c {
+		fields[name] = change.After
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return s, err
+	}
+	var res TodoState
+	if err := json.Unmarshal(b, &res); err != nil {
+		return s, err
+	}
+	return res, nil
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/teambition/rrule-go"
+)
+
+// Series is the definition shared by the occurrences of a recurring todo.
+type Series struct {
+	ID       int `gorm:"primaryKey"`
+	Task     string
+	Priority Priority
+	// Recurrence is the RFC 5545 RRULE value, e.g. "FREQ=WEEKLY;BYDAY=MO".
+	Recurrence string
+	// Start is the DTSTART of the rule, the slot of the first occurrence.
+	Start     time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// NewSeries returns a new series starting at start.
+func NewSeries(task string, priority Priority, recurrence string, start time.Time) (*Series, error) {
+	rule, err := NormalizeRecurrence(recurrence)
+	if err != nil {
+		return nil, err
+	}
+	return &Series{
+		Task:       task,
+		Priority:   priority,
+		Recurrence: rule,
+		Start:      start.UTC().Truncate(time.Second),
+	}, nil
+}
+
+// Next returns the first occurrence of the series strictly after the given slot,
+// or false when the rule has no further occurrences.
+func (s *Series) Next(after time.Time) (time.Time, bool) {
+	opt, err := parseRecurrence(s.Recurrence)
+	if err != nil {
+		return time.Time{}, false
+	}
+	opt.Dtstart = s.Start
+	rule, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	next := rule.After(after, false)
+	return next, !next.IsZero()
+}
+
+// EditScope selects which occurrences of a recurring todo an update applies to.
+type EditScope string
+
+const (
+	// ThisOccurrence applies an update to the edited occurrence only.
+	ThisOccurrence = EditScope("this")
+	// FutureOccurrences applies an update to the edited occurrence and every one generated after it.
+	FutureOccurrences = EditScope("future")
+)
+
+// NormalizeRecurrence validates an RRULE value and returns its canonical form.
+func NormalizeRecurrence(s string) (string, error) {
+	opt, err := parseRecurrence(s)
+	if err != nil {
+		return "", err
+	}
+	return opt.RRuleString(), nil
+}
+
+func parseRecurrence(s string) (*rrule.ROption, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" || strings.ContainsAny(s, "\r\n") {
+		return nil, fmt.Errorf("invalid recurrence rule: %q", s)
+	}
+	opt, err := rrule.StrToROption(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence rule: %w", err)
+	}
+	return opt, nil
+}
+
+// IsValidRecurrence checks if the recurrence is a valid RFC 5545 RRULE value
+func IsValidRecurrence(fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true // Skip validation for empty or nil fields
+	}
+	_, err := parseRecurrence(fl.Field().String())
+	return err == nil
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortableFields maps the sort field names accepted by list queries to todo columns.
+var SortableFields = map[string]string{
+	"id":            "id",
+	"task":          "task",
+	"status":        "status",
+	"priority":      "priority",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+	"due_at":        "due_at",
+	"scheduled_for": "scheduled_for",
+}
+
+// SortField is a single column of a requested ordering.
+type SortField struct {
+	// Column is the todo column to sort by.
+	Column string
+	// Desc sorts in descending order when true.
+	Desc bool
+}
+
+// ParseSort parses a comma separated list of sort fields such as "-updated_at,task",
+// where a leading "-" requests descending order.
+func ParseSort(s string) ([]SortField, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var (
+		fields []SortField
+		seen   = map[string]bool{}
+	)
+	for _, part := range strings.Split(s, ",") {
+		name := strings.TrimSpace(part)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		column, ok := SortableFields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field: %q", name)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("duplicate sort field: %q", name)
+		}
+		seen[column] = true
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+	return fields, nil
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Tag is a label attached to todos, its name unique among the tags of its user.
+type Tag struct {
+	ID int `gorm:"primaryKey"`
+	// UserID is the user owning the tag.
+	UserID    int       `gorm:"uniqueIndex:idx_tags_user_id_name" json:"-"`
+	Name      string    `gorm:"uniqueIndex:idx_tags_user_id_name"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// NewTag returns a new instance of the tag model.
+func NewTag(name string) *Tag {
+	return &Tag{
+		Name: NormalizeTagName(name),
+	}
+}
+
+// NewUpdateTag returns a new instance of the tag model for updating.
+func NewUpdateTag(id int, name string) *Tag {
+	return &Tag{
+		ID:   id,
+		Name: NormalizeTagName(name),
+	}
+}
+
+// TagList is the tags of a todo, rendered as their names.
+type TagList []*Tag
+
+// MarshalJSON renders the tags as a list of names.
+func (l TagList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Names())
+}
+
+// UnmarshalJSON reads a list of tag names.
+func (l *TagList) UnmarshalJSON(b []byte) error {
+	var names []string
+	if err := json.Unmarshal(b, &names); err != nil {
+		return err
+	}
+	list := make(TagList, 0, len(names))
+	for _, name := range names {
+		list = append(list, NewTag(name))
+	}
+	*l = list
+	return nil
+}
+
+// Names returns the names of the tags.
+func (l TagList) Names() []string {
+	names := make([]string, 0, len(l))
+	for _, t := range l {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// NormalizeTagName returns the canonical form of a tag name, so that "Backend" and "backend " are the same tag.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTagNames normalizes tag names, dropping duplicates.
+func NormalizeTagNames(names []string) []string {
+	seen := map[string]bool{}
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	return res
+}
+
+// IsValidTagName checks if the tag name is usable in filters: at most 50 characters without whitespace or commas
+func IsValidTagName(fl validator.FieldLevel) bool {
+	if fl.Field().IsZero() {
+		return true // Skip validation for empty or nil fields
+	}
+	name := NormalizeTagName(fl.Field().String())
+	return name != "" && len(name) <= 50 && !strings.ContainsAny(name, ", \t\r\n")
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
 )
 
 // Todo is the model for the todo endpoint.
@@ -14,25 +15,171 @@ type Todo struct {
 	Priority  Priority
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	// DueAt is the deadline of the task.
+	DueAt *time.Time `gorm:"index" json:"DueAt,omitempty"`
+	// ScheduledFor is when work on the task is planned to start.
+	ScheduledFor *time.Time `json:"ScheduledFor,omitempty"`
+	// ParentID is the todo this one is a subtask of.
+	ParentID *int `gorm:"index" json:"ParentID,omitempty"`
+	// SeriesID links the occurrences of a recurring todo.
+	SeriesID *int    `gorm:"index" json:"SeriesID,omitempty"`
+	Series   *Series `json:"Series,omitempty"`
+	// OccurrenceAt is the slot of the recurrence rule this occurrence stands for.
+	OccurrenceAt *time.Time `json:"OccurrenceAt,omitempty"`
+	// DeletedAt is when the todo was moved to the trash.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	// Version is incremented on every change, it is sent as the ETag of the todo.
+	Version int `gorm:"not null;default:1" json:"-"`
+	// UserID is the user owning the todo.
+	UserID int `gorm:"index" json:"-"`
+	// ProjectID is the project the todo belongs to.
+	ProjectID *int `gorm:"index" json:"ProjectID,omitempty"`
+	// Tags are the labels of the todo.
+	Tags TagList `gorm:"many2many:todo_tags" json:"Tags,omitempty" swaggertype:"array,string"`
+	// Snippet is the highlighted match of a full-text search, empty otherwise.
+	Snippet string `gorm:"->;-:migration" json:"Snippet,omitempty"`
+	// Rank is the full-text search relevance, lower is better.
+	Rank float64 `gorm:"->;-:migration" json:"-"`
+}
+
+// TodoDetails are the optional attributes of a todo set on create and update.
+type TodoDetails struct {
+	// DueAt is the deadline of the task.
+	DueAt *time.Time
+	// ScheduledFor is when work on the task is planned to start.
+	ScheduledFor *time.Time
+	// Recurrence is the RFC 5545 RRULE making the todo repeat, starting at DueAt.
+	Recurrence string
+	// ParentID makes the todo a subtask of another todo.
+	ParentID *int
+	// Tags are the names of the labels of the todo.
+	Tags []string
+	// ProjectID moves the todo into a project.
+	ProjectID *int
+}
+
+// UpdateOptions control how an update is applied.
+type UpdateOptions struct {
+	// Scope selects the occurrences of a recurring todo the update applies to.
+	Scope EditScope
+	// Force marks a todo done even though some of its subtasks are still open.
+	Force bool
+	// IfMatch restricts the update to the given versions of the todo.
+	IfMatch Precondition
+}
+
+// Precondition is the versions of a todo a change applies to. A nil
+// precondition allows any version while an empty one allows none.
+type Precondition []int
+
+// Allows reports whether a change applies to the given version.
+func (p Precondition) Allows(version int) bool {
+	if p == nil {
+		return true
+	}
+	for _, v := range p {
+		if v == version {
+			return true
+		}
+	}
+	return false
 }
 
 // NewTodo returns a new instance of the todo model.
-func NewTodo(task string, priority Priority) *Todo {
+func NewTodo(task string, priority Priority, details TodoDetails) *Todo {
 	return &Todo{
-		Task:     task,
-		Priority: priority,
-		Status:   Created,
+		Task:         task,
+		Priority:     priority,
+		Status:       Created,
+		DueAt:        utc(details.DueAt),
+		ScheduledFor: utc(details.ScheduledFor),
+		ParentID:     details.ParentID,
+		ProjectID:    details.ProjectID,
 	}
 }
 
 // NewUpdateTodo returns a new instance of the todo model for updating.
-func NewUpdateTodo(id int, task string, priority Priority, status Status) *Todo {
+func NewUpdateTodo(id int, task string, priority Priority, status Status, details TodoDetails) *Todo {
 	return &Todo{
-		ID:       id,
-		Task:     task,
-		Status:   status,
-		Priority: priority,
+		ID:           id,
+		Task:         task,
+		Status:       status,
+		Priority:     priority,
+		DueAt:        utc(details.DueAt),
+		ScheduledFor: utc(details.ScheduledFor),
+		ParentID:     details.ParentID,
+		ProjectID:    details.ProjectID,
+	}
+}
+
+// TodoNode is a todo with its nested subtasks.
+type TodoNode struct {
+	*Todo
+	Children []*TodoNode
+}
+
+// NewTodoTree nests the given descendants under root by their parent.
+func NewTodoTree(root *Todo, descendants []*Todo) *TodoNode {
+	nodes := map[int]*TodoNode{root.ID: {Todo: root, Children: []*TodoNode{}}}
+	for _, t := range descendants {
+		nodes[t.ID] = &TodoNode{Todo: t, Children: []*TodoNode{}}
+	}
+	for _, t := range descendants {
+		if t.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*t.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[t.ID])
+		}
+	}
+	return nodes[root.ID]
+}
+
+// TrashedTodo is a todo in the trash.
+type TrashedTodo struct {
+	*Todo
+	// DeletedAt is when the todo was moved to the trash.
+	DeletedAt time.Time
+}
+
+// NewTrashedTodos returns the trashed todos with the time they were deleted at.
+func NewTrashedTodos(todos []*Todo) []*TrashedTodo {
+	res := make([]*TrashedTodo, 0, len(todos))
+	for _, t := range todos {
+		res = append(res, &TrashedTodo{Todo: t, DeletedAt: t.DeletedAt.Time})
+	}
+	return res
+}
+
+// DeleteMode selects what happens to the subtasks of a deleted todo.
+type DeleteMode string
+
+const (
+	// RefuseDelete refuses to delete a todo that has subtasks.
+	RefuseDelete = DeleteMode("refuse")
+	// CascadeDelete deletes a todo together with all of its subtasks.
+	CascadeDelete = DeleteMode("cascade")
+	// ReparentDelete moves the subtasks of a deleted todo to its own parent.
+	ReparentDelete = DeleteMode("reparent")
+)
+
+// IsRecurring reports whether the todo is an occurrence of a recurring series.
+func (t *Todo) IsRecurring() bool {
+	return t.SeriesID != nil
+}
+
+// IsOverdue reports whether the task is past its due date and not done yet.
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.DueAt.Before(now) && t.Status != Done
+}
+
+// utc normalizes dates to UTC so that they compare correctly as stored values.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
 	}
+	u := t.UTC()
+	return &u
 }
 
 // Status is the status of the task.
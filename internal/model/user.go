@@ -0,0 +1,46 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User is an account owning todos.
+type User struct {
+	ID    int    `gorm:"primaryKey"`
+	Email string `gorm:"uniqueIndex"`
+	// PasswordHash is the bcrypt hash of the password, never rendered.
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+// NewUser returns a new instance of the user model with the password hashed.
+func NewUser(email, password string) (*User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return &User{
+		Email:        NormalizeEmail(email),
+		PasswordHash: string(hash),
+	}, nil
+}
+
+// CheckPassword reports whether password matches the password of the user.
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// NormalizeEmail returns the canonical form of an email address used to look up accounts.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// AccessToken is a signed token authenticating the requests of a user.
+type AccessToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
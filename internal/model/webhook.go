@@ -0,0 +1,243 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// SignatureHeader carries the signature of a webhook delivery.
+const SignatureHeader = "X-Webhook-Signature"
+
+// EventHeader carries the event type of a webhook delivery.
+const EventHeader = "X-Webhook-Event"
+
+// DeliveryHeader carries the id of a webhook delivery, which stays the same across its attempts.
+const DeliveryHeader = "X-Webhook-Delivery"
+
+// EventType is the kind of change to a todo webhooks are notified of.
+type EventType string
+
+const (
+	// TodoCreated is the event for a created todo.
+	TodoCreated = EventType("todo.created")
+	// TodoUpdated is the event for any change to a todo.
+	TodoUpdated = EventType("todo.updated")
+	// TodoStatusChanged is the event for a todo whose status changed.
+	TodoStatusChanged = EventType("todo.status_changed")
+	// TodoDeleted is the event for a todo moved to the trash.
+	TodoDeleted = EventType("todo.deleted")
+	// TodoRestored is the event for a todo restored from the trash.
+	TodoRestored = EventType("todo.restored")
+)
+
+// EventTypes are the event types a webhook can subscribe to.
+var EventTypes = []EventType{TodoCreated, TodoUpdated, TodoStatusChanged, TodoDeleted, TodoRestored}
+
+// IsValidEventType validates an event type.
+func IsValidEventType(fl validator.FieldLevel) bool {
+	event := fl.Field().Interface().(EventType)
+	for _, e := range EventTypes {
+		if event == e {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidWebhookURL validates the URL of a webhook: an absolute http or https URL, other schemes could reach
+// anything the server can.
+func IsValidWebhookURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+// RevisionEvents returns the events of a revision.
+func RevisionEvents(rev *Revision) []EventType {
+	switch rev.Action {
+	case CreateAction:
+		return []EventType{TodoCreated}
+	case DeleteAction:
+		return []EventType{TodoDeleted}
+	case RestoreAction:
+		return []EventType{TodoRestored}
+	}
+	events := []EventType{TodoUpdated}
+	if _, ok := rev.Changes["Status"]; ok {
+		events = append(events, TodoStatusChanged)
+	}
+	return events
+}
+
+// Webhook is a subscription of a URL to the events of the todos of a user.
+type Webhook struct {
+	ID  int `gorm:"primaryKey"`
+	URL string
+	// Secret is the HMAC key signing the deliveries.
+	Secret    string      `json:"-"`
+	Events    []EventType `gorm:"serializer:json"`
+	CreatedAt time.Time   `gorm:"autoCreateTime"`
+	// UserID is the user owning the webhook.
+	UserID int `gorm:"index" json:"-"`
+}
+
+// NewWebhook returns a new webhook model, a secret is generated when none is given.
+func NewWebhook(url string, events []EventType, secret string) (*Webhook, error) {
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+	return &Webhook{
+		URL:    url,
+		Secret: secret,
+		Events: events,
+	}, nil
+}
+
+// Subscribes reports whether the webhook is notified of the event.
+func (w *Webhook) Subscribes(event EventType) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature of a delivery body, "sha256=" followed by its hex encoded HMAC-SHA256.
+func (w *Webhook) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Event is the body of a webhook delivery.
+type Event struct {
+	Type       EventType
+	OccurredAt time.Time
+	TodoID     int
+	// Todo is the state of the todo after the change.
+	Todo TodoState
+	// Changes are the fields changed by an update.
+	Changes Changes `json:"Changes,omitempty"`
+}
+
+// DeliveryStatus is the state of a webhook delivery.
+type DeliveryStatus string
+
+const (
+	// PendingDelivery is a delivery waiting for its next attempt.
+	PendingDelivery = DeliveryStatus("pending")
+	// SucceededDelivery is a delivery the webhook accepted.
+	SucceededDelivery = DeliveryStatus("succeeded")
+	// FailedDelivery is a delivery given up on after its last attempt.
+	FailedDelivery = DeliveryStatus("failed")
+)
+
+// Delivery is an event queued for a webhook, kept with the outcome of its attempts.
+type Delivery struct {
+	ID        int `gorm:"primaryKey"`
+	WebhookID int `gorm:"index"`
+	Event     EventType
+	// Payload is the JSON encoded Event sent as the body.
+	Payload  json.RawMessage `gorm:"type:text" swaggertype:"object"`
+	Status   DeliveryStatus
+	Attempts int
+	// NextAttemptAt is when a pending delivery is attempted next.
+	NextAttemptAt *time.Time `gorm:"index" json:"NextAttemptAt,omitempty"`
+	LastAttemptAt *time.Time `json:"LastAttemptAt,omitempty"`
+	// ResponseStatus is the HTTP status the webhook answered the last attempt with.
+	ResponseStatus int    `json:"ResponseStatus,omitempty"`
+	LastError      string `json:"LastError,omitempty"`
+	// ReplayOf is the failed delivery this one replays.
+	ReplayOf  *int      `json:"ReplayOf,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// NewDelivery returns a new delivery of an event, due right away.
+func NewDelivery(webhookID int, event *Event) (*Delivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &Delivery{
+		WebhookID:     webhookID,
+		Event:         event.Type,
+		Payload:       payload,
+		Status:        PendingDelivery,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+// Replay returns a new delivery of the same payload, due right away.
+func (d *Delivery) Replay() *Delivery {
+	now := time.Now().UTC()
+	return &Delivery{
+		WebhookID:     d.WebhookID,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        PendingDelivery,
+		NextAttemptAt: &now,
+		ReplayOf:      &d.ID,
+	}
+}
+
+// RetryPolicy is how failed delivery attempts are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts before a delivery fails.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for every following one.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two attempts, the delay keeps doubling when it is not positive.
+	MaxBackoff time.Duration
+}
+
+// Succeed records a successful attempt answered with the given status.
+func (d *Delivery) Succeed(at time.Time, status int) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.ResponseStatus = status
+	d.LastError = ""
+	d.Status = SucceededDelivery
+	d.NextAttemptAt = nil
+}
+
+// Fail records a failed attempt, scheduling the next one with an exponential backoff
+// or failing the delivery after the last attempt. Status is zero when no response was received.
+func (d *Delivery) Fail(at time.Time, status int, reason string, policy RetryPolicy) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.ResponseStatus = status
+	d.LastError = reason
+	if d.Attempts >= policy.MaxAttempts {
+		d.Status = FailedDelivery
+		d.NextAttemptAt = nil
+		return
+	}
+	delay := policy.Backoff
+	// The doubling stops at the cap, or before the delay overflows when there is none.
+	for i := 1; i < d.Attempts && delay < math.MaxInt64/2 && (policy.MaxBackoff <= 0 || delay < policy.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	next := at.Add(delay)
+	d.NextAttemptAt = &next
+}
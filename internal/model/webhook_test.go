@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelivery_Fail(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		// delays are the expected delays after each failed attempt, 0 when the delivery failed.
+		delays []time.Duration
+	}{
+		{
+			name:   "capped",
+			policy: RetryPolicy{MaxAttempts: 5, Backoff: time.Minute, MaxBackoff: 3 * time.Minute},
+			delays: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute, 0},
+		},
+		{
+			name:   "uncapped",
+			policy: RetryPolicy{MaxAttempts: 5, Backoff: time.Minute},
+			delays: []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Delivery{Status: PendingDelivery}
+			for i, delay := range tt.delays {
+				d.Fail(at, 500, "server error", tt.policy)
+				assert.Equal(t, i+1, d.Attempts)
+				if delay == 0 {
+					assert.Equal(t, FailedDelivery, d.Status)
+					assert.Nil(t, d.NextAttemptAt)
+					continue
+				}
+				assert.Equal(t, PendingDelivery, d.Status)
+				require.NotNil(t, d.NextAttemptAt)
+				assert.Equal(t, delay, d.NextAttemptAt.Sub(at), "attempt %d", i+1)
+			}
+		})
+	}
+
+	t.Run("no_overflow", func(t *testing.T) {
+		d := &Delivery{Attempts: 100}
+		d.Fail(at, 0, "timeout", RetryPolicy{MaxAttempts: 1000, Backoff: time.Minute})
+		require.NotNil(t, d.NextAttemptAt)
+		assert.True(t, d.NextAttemptAt.After(at))
+	})
+}
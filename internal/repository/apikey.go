@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// APIKey is the repository for API keys.
+type APIKey interface {
+	Create(k *model.APIKey) error
+	Revoke(id int, at time.Time) error
+	FindAll() ([]*model.APIKey, error)
+	FindByHash(hash string) (*model.APIKey, error)
+	Touch(id int, at time.Time) error
+	ForOwner(userID int) APIKey
+}
+
+type apiKey struct {
+	db *gorm.DB
+	// owner is the user whose keys the repository reads and writes.
+	owner int
+}
+
+// NewAPIKey returns a new instance of the API key repository.
+func NewAPIKey(db *gorm.DB) APIKey {
+	return &apiKey{
+		db: db,
+	}
+}
+
+func (ak *apiKey) Create(k *model.APIKey) error {
+	k.UserID = ak.owner
+	if err := ak.db.Create(k).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ak *apiKey) Revoke(id int, at time.Time) error {
+	result := ak.owned().Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrNotFound
+	}
+	log.Info("Revoked API key with id: ", id)
+	return nil
+}
+
+func (ak *apiKey) FindAll() ([]*model.APIKey, error) {
+	var keys []*model.APIKey
+	if err := ak.owned().Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// FindByHash returns the unrevoked key of any user with the given hash.
+func (ak *apiKey) FindByHash(hash string) (*model.APIKey, error) {
+	var key *model.APIKey
+	err := ak.db.Where("hash = ? AND revoked_at IS NULL", hash).Take(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// Touch records that the key was used at the given time.
+func (ak *apiKey) Touch(id int, at time.Time) error {
+	return ak.db.Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// ForOwner returns the repository of the API keys owned by the given user.
+func (ak *apiKey) ForOwner(userID int) APIKey {
+	return &apiKey{db: ak.db, owner: userID}
+}
+
+// owned returns a query restricted to the API keys of the owner.
+func (ak *apiKey) owned() *gorm.DB {
+	return ak.db.Where("api_keys.user_id = ?", ak.owner)
+}
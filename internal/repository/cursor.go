@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+)
+
+// cursor is the decoded form of an opaque pagination cursor.
+type cursor struct {
+	// Order is the ordering the cursor was issued for.
+	Order string `json:"o"`
+	// Values are the ordering column values of the last row of the previous page.
+	Values []json.RawMessage `json:"v"`
+	// Now is the reference time of the first page, the following pages are read as of the same time
+	// so that the todos becoming overdue meanwhile keep their place.
+	Now time.Time `json:"n"`
+}
+
+func orderSignature(orders []order) string {
+	parts := make([]string, 0, len(orders))
+	for _, o := range orders {
+		parts = append(parts, o.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeCursor returns the cursor pointing just after t.
+func encodeCursor(orders []order, t *model.Todo, now time.Time) (string, error) {
+	c := cursor{Order: orderSignature(orders), Now: now}
+	for _, o := range orders {
+		v, err := json.Marshal(columnValue(t, o.column, now))
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, v)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor parses s into the values of the ordering columns and the reference time of the first page.
+func decodeCursor(orders []order, s string) ([]interface{}, time.Time, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, time.Time{}, model.ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, time.Time{}, model.ErrInvalidCursor
+	}
+	if c.Order != orderSignature(orders) || len(c.Values) != len(orders) || c.Now.IsZero() {
+		return nil, time.Time{}, model.ErrInvalidCursor
+	}
+	values := make([]interface{}, 0, len(orders))
+	for i, o := range orders {
+		v, err := parseColumnValue(o.column, c.Values[i])
+		if err != nil {
+			return nil, time.Time{}, model.ErrInvalidCursor
+		}
+		values = append(values, v)
+	}
+	return values, c.Now.UTC(), nil
+}
+
+// columnValue returns the value of the given ordering column of t.
+func columnValue(t *model.Todo, column string, now time.Time) interface{} {
+	switch column {
+	case "id":
+		return t.ID
+	case "task":
+		return t.Task
+	case "status":
+		return t.Status
+	case "priority":
+		return t.Priority
+	case "created_at":
+		return t.CreatedAt
+	case "updated_at":
+		return t.UpdatedAt
+	case "due_at":
+		return dateOrNoDate(t.DueAt)
+	case "scheduled_for":
+		return dateOrNoDate(t.ScheduledFor)
+	case "overdue":
+		if t.IsOverdue(now) {
+			return 1
+		}
+		return 0
+	case "rank":
+		return t.Rank
+	}
+	return nil
+}
+
+func dateOrNoDate(t *time.Time) time.Time {
+	if t == nil {
+		return noDate
+	}
+	return *t
+}
+
+// parseColumnValue decodes a cursor value into the Go type of the given column,
+// so that it binds to the query the same way the stored value was written.
+func parseColumnValue(column string, raw json.RawMessage) (interface{}, error) {
+	switch column {
+	case "id", "overdue":
+		var v int
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "task":
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "status":
+		var v model.Status
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "priority":
+		var v model.Priority
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "created_at", "updated_at", "due_at", "scheduled_for":
+		var v time.Time
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "rank":
+		var v float64
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+	return nil, fmt.Errorf("unknown cursor column: %s", column)
+}
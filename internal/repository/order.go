@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// noDate stands in for a missing date so that nullable date columns sort last
+// in ascending order and can still be compared by keyset pagination.
+var noDate = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// order is a single column of an ORDER BY clause.
+type order struct {
+	column string
+	desc   bool
+}
+
+// expr returns the SQL expression of the ordering column and its arguments.
+// now is the reference time of the query, used by the overdue column.
+func (o order) expr(now time.Time) (string, []interface{}) {
+	switch o.column {
+	case "rank":
+		return searchTable + ".rank", nil
+	case "overdue":
+		return "CASE WHEN todos.due_at < ? AND todos.status <> ? THEN 1 ELSE 0 END", []interface{}{now, model.Done}
+	case "due_at", "scheduled_for":
+		return "COALESCE(todos." + o.column + ", ?)", []interface{}{noDate}
+	}
+	return "todos." + o.column, nil
+}
+
+func (o order) String() string {
+	if o.desc {
+		return o.column + " desc"
+	}
+	return o.column + " asc"
+}
+
+// defaultOrder is the ordering of todo lists, overdue todos first. The trailing
+// id keeps the ordering total so that keyset pagination never skips or repeats a row.
+var defaultOrder = []order{
+	{column: "overdue", desc: true},
+	{column: "priority", desc: true},
+	{column: "created_at", desc: true},
+	{column: "id", desc: true},
+}
+
+// searchOrder is the ordering of full-text search results, best match first.
+var searchOrder = []order{
+	{column: "rank"},
+	{column: "id", desc: true},
+}
+
+// newOrder returns the ordering for the requested sort fields, falling back to
+// defaultOrder. The id column is appended as a tiebreaker when missing.
+func newOrder(fields []model.SortField) []order {
+	if len(fields) == 0 {
+		return defaultOrder
+	}
+	orders := make([]order, 0, len(fields)+1)
+	hasID := false
+	for _, f := range fields {
+		orders = append(orders, order{column: f.Column, desc: f.Desc})
+		if f.Column == "id" {
+			hasID = true
+		}
+	}
+	if !hasID {
+		orders = append(orders, order{column: "id", desc: true})
+	}
+	return orders
+}
+
+// orderBy returns the ORDER BY clause of the given ordering.
+func orderBy(orders []order, now time.Time) clause.OrderBy {
+	var (
+		parts []string
+		args  []interface{}
+	)
+	for _, o := range orders {
+		sql, vars := o.expr(now)
+		if o.desc {
+			sql += " DESC"
+		}
+		parts = append(parts, sql)
+		args = append(args, vars...)
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(parts, ", "), Vars: args, WithoutParentheses: true}}
+}
+
+// seekAfter restricts tx to the rows strictly after the cursor values in the given ordering.
+func seekAfter(tx *gorm.DB, orders []order, values []interface{}, now time.Time) *gorm.DB {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+	for i, o := range orders {
+		var conds []string
+		for j := 0; j < i; j++ {
+			sql, vars := orders[j].expr(now)
+			conds = append(conds, sql+" = ?")
+			args = append(args, vars...)
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if o.desc {
+			op = " < ?"
+		}
+		sql, vars := o.expr(now)
+		conds = append(conds, sql+op)
+		args = append(args, vars...)
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(conds, " AND ")+")")
+	}
+	return tx.Where(strings.Join(clauses, " OR "), args...)
+}
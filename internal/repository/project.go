@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Project is the repository for the project endpoint.
+type Project interface {
+	Create(p *model.Project) error
+	Delete(id int) error
+	Update(p *model.Project) error
+	Find(id int) (*model.Project, error)
+	FindAll(qry map[string]interface{}) ([]*model.Project, error)
+	NextPosition() (int, error)
+	ForOwner(userID int) Project
+	Within(tx *gorm.DB) Project
+}
+
+type project struct {
+	db *gorm.DB
+	// owner is the user whose projects the repository reads and writes.
+	owner int
+}
+
+// NewProject returns a new instance of the project repository.
+func NewProject(db *gorm.DB) Project {
+	return &project{
+		db: db,
+	}
+}
+
+func (pr *project) Create(p *model.Project) error {
+	p.UserID = pr.owner
+	if err := pr.db.Create(p).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *project) Update(p *model.Project) error {
+	p.UserID = pr.owner
+	if err := pr.db.Save(p).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pr *project) Delete(id int) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, pr.owner).Delete(&model.Project{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrNotFound
+		}
+		// The todos of the project are kept, outside of any project.
+		err := tx.Model(&model.Todo{}).Where("project_id = ?", id).
+			Updates(map[string]interface{}{"project_id": nil, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+		log.Info("Deleted project with id: ", id)
+		return nil
+	})
+}
+
+func (pr *project) Find(id int) (*model.Project, error) {
+	var project *model.Project
+	err := pr.owned().Where("id = ?", id).Take(&project).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+func (pr *project) FindAll(qry map[string]interface{}) ([]*model.Project, error) {
+	var projects []*model.Project
+	if err := pr.owned().Where(qry).Order("position, id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// NextPosition returns the position placing a project after all existing ones.
+func (pr *project) NextPosition() (int, error) {
+	var position int
+	err := pr.owned().Model(&model.Project{}).Select("COALESCE(MAX(position), -1) + 1").Scan(&position).Error
+	if err != nil {
+		return 0, err
+	}
+	return position, nil
+}
+
+// ForOwner returns the repository of the projects owned by the given user.
+func (pr *project) ForOwner(userID int) Project {
+	return &project{db: pr.db, owner: userID}
+}
+
+// Within returns the repository of the projects of the owner reading and writing with tx, the
+// connection of a todo repository running in a transaction.
+func (pr *project) Within(tx *gorm.DB) Project {
+	return &project{db: tx, owner: pr.owner}
+}
+
+// owned returns a query restricted to the projects of the owner.
+func (pr *project) owned() *gorm.DB {
+	return pr.db.Where("projects.user_id = ?", pr.owner)
+}
+
+// archivedProjectIDs selects the ids of archived projects, whose todos the default list hides.
+const archivedProjectIDs = "SELECT projects.id FROM projects WHERE projects.archived = ?"
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"gorm.io/gorm"
+)
+
+// ownedTodoIDs selects the ids of the todos of a user, trashed ones included.
+const ownedTodoIDs = "SELECT todos.id FROM todos WHERE todos.user_id = ?"
+
+// revisions returns a query on the revisions of the todos of the owner.
+func (td *todo) revisions() *gorm.DB {
+	return td.db.Where("revisions.todo_id IN ("+ownedTodoIDs+")", td.owner)
+}
+
+// CreateRevision records a change made by the owner.
+func (td *todo) CreateRevision(rev *model.Revision) error {
+	rev.ActorID = td.owner
+	if err := td.db.Create(rev).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindRevisions returns the revisions of a todo, oldest first.
+func (td *todo) FindRevisions(todoID int) ([]*model.Revision, error) {
+	var revisions []*model.Revision
+	err := td.revisions().Where("revisions.todo_id = ?", todoID).Order("revisions.id").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// FindRevisionsAfter returns the revisions of every user following the given one, oldest first.
+func (td *todo) FindRevisionsAfter(id int, limit int) ([]*model.Revision, error) {
+	var revisions []*model.Revision
+	if err := td.db.Where("revisions.id > ?", id).Order("revisions.id").Limit(limit).Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// LastRevisionID returns the id of the latest revision of every user, zero when there is none.
+func (td *todo) LastRevisionID() (int, error) {
+	var id int
+	if err := td.db.Model(&model.Revision{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// FindAllByIDs returns the todos of every user with the given ids, trashed ones included.
+func (td *todo) FindAllByIDs(ids []int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := td.db.Unscoped().Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("tags.name")
+	}).Where("todos.id IN ?", ids).Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"gorm.io/gorm"
+)
+
+func (td *todo) CreateSeries(s *model.Series) error {
+	if err := td.db.Create(s).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (td *todo) UpdateSeries(s *model.Series) error {
+	if err := td.db.Save(s).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (td *todo) FindSeries(id int) (*model.Series, error) {
+	var series *model.Series
+	err := td.db.Where("id = ?", id).Take(&series).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return series, nil
+}
+
+// FindOccurrence returns the todo standing for the given slot of a series.
+func (td *todo) FindOccurrence(seriesID int, at time.Time) (*model.Todo, error) {
+	var todo *model.Todo
+	err := td.owned().Where("series_id = ? AND occurrence_at = ?", seriesID, at).Take(&todo).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return todo, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Tag is the repository for the tag endpoint.
+type Tag interface {
+	Create(t *model.Tag) error
+	Delete(id int) error
+	Update(t *model.Tag) error
+	Find(id int) (*model.Tag, error)
+	FindAll() ([]*model.Tag, error)
+	ForOwner(userID int) Tag
+}
+
+type tag struct {
+	db *gorm.DB
+	// owner is the user whose tags the repository reads and writes.
+	owner int
+}
+
+// NewTag returns a new instance of the tag repository.
+func NewTag(db *gorm.DB) Tag {
+	return &tag{
+		db: db,
+	}
+}
+
+func (tg *tag) Create(t *model.Tag) error {
+	t.UserID = tg.owner
+	if err := tg.db.Create(t).Error; err != nil {
+		return duplicateError(err)
+	}
+	return nil
+}
+
+func (tg *tag) Update(t *model.Tag) error {
+	t.UserID = tg.owner
+	if err := tg.db.Save(t).Error; err != nil {
+		return duplicateError(err)
+	}
+	return nil
+}
+
+func (tg *tag) Delete(id int) error {
+	return tg.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, tg.owner).Delete(&model.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrNotFound
+		}
+		if err := tx.Exec("DELETE FROM todo_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		log.Info("Deleted tag with id: ", id)
+		return nil
+	})
+}
+
+func (tg *tag) Find(id int) (*model.Tag, error) {
+	var tag *model.Tag
+	err := tg.owned().Where("id = ?", id).Take(&tag).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (tg *tag) FindAll() ([]*model.Tag, error) {
+	var tags []*model.Tag
+	if err := tg.owned().Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// ForOwner returns the repository of the tags owned by the given user.
+func (tg *tag) ForOwner(userID int) Tag {
+	return &tag{db: tg.db, owner: userID}
+}
+
+// owned returns a query restricted to the tags of the owner.
+func (tg *tag) owned() *gorm.DB {
+	return tg.db.Where("tags.user_id = ?", tg.owner)
+}
+
+// duplicateErrors are the messages SQLite and Postgres report for unique constraint violations.
+var duplicateErrors = []string{"UNIQUE constraint failed", "(SQLSTATE 23505)"}
+
+// duplicateError maps unique constraint violations to model.ErrDuplicate.
+func duplicateError(err error) error {
+	for _, msg := range duplicateErrors {
+		if strings.Contains(err.Error(), msg) {
+			return model.ErrDuplicate
+		}
+	}
+	return err
+}
+
+// taggedIDs selects the ids of todos carrying a tag, to be completed with a WHERE on tags.
+const taggedIDs = "SELECT todo_tags.todo_id FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id"
+
+// SetTags replaces the tags of t with the tags of the owner of the given names, creating missing tags.
+func (td *todo) SetTags(t *model.Todo, names []string) error {
+	names = model.NormalizeTagNames(names)
+	sort.Strings(names)
+	tags := make(model.TagList, 0, len(names))
+	for _, name := range names {
+		tag := model.NewTag(name)
+		tag.UserID = td.owner
+		if err := td.db.Where("user_id = ? AND name = ?", td.owner, tag.Name).FirstOrCreate(tag).Error; err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+	// Replacing the association saves t, hooks are skipped so that its update time is left to Update.
+	if err := td.db.Session(&gorm.Session{SkipHooks: true}).Model(t).Omit("Tags.*").Association("Tags").Replace(tags); err != nil {
+		return err
+	}
+	t.Tags = tags
+	return nil
+}
@@ -2,60 +2,132 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
 	"github.com/fardinabir/todo-manager-app/internal/model"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Todo is the repository for the todo endpoint.
 type Todo interface {
 	Create(t *model.Todo) error
-	Delete(id int) error
+	Delete(t *model.Todo) error
 	Update(t *model.Todo) error
 	Find(id int) (*model.Todo, error)
-	FindAll(qry map[string]interface{}) ([]*model.Todo, error)
+	FindAll(qry map[string]interface{}, page model.PageRequest) (*model.TodoPage, error)
+	FindEach(fn func(t *model.Todo) error) error
+	CreateSeries(s *model.Series) error
+	UpdateSeries(s *model.Series) error
+	FindSeries(id int) (*model.Series, error)
+	FindOccurrence(seriesID int, at time.Time) (*model.Todo, error)
+	FindChildren(id int) ([]*model.Todo, error)
+	FindChildrenOf(ids []int) ([]*model.Todo, error)
+	FindDescendants(id int) ([]*model.Todo, error)
+	Reparent(id int, parentID *int) error
+	SetTags(t *model.Todo, names []string) error
+	FindDeleted(id int) (*model.Todo, error)
+	FindAllDeleted() ([]*model.Todo, error)
+	Restore(id int) error
+	Purge(id int) error
+	PurgeAll() (int64, error)
+	PurgeDeletedBefore(before time.Time) (int64, error)
+	CreateRevision(rev *model.Revision) error
+	FindRevisions(todoID int) ([]*model.Revision, error)
+	FindRevisionsAfter(id int, limit int) ([]*model.Revision, error)
+	LastRevisionID() (int, error)
+	FindAllByIDs(ids []int) ([]*model.Todo, error)
+	Transaction(fn func(r Todo) error) error
+	DB() *gorm.DB
+	ForOwner(userID int) Todo
 }
 
+// searchTable is the full-text search index joined for "q" queries.
+const searchTable = db.SearchTable
+
 type todo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	search bool
+	// owner is the user whose todos the repository reads and writes.
+	owner int
 }
 
 // NewTodo returns a new instance of the todo repository.
-func NewTodo(db *gorm.DB) Todo {
+func NewTodo(gdb *gorm.DB) Todo {
 	return &todo{
-		db: db,
+		db:     gdb,
+		search: db.SearchAvailable(gdb),
 	}
 }
 
 func (td *todo) Create(t *model.Todo) error {
-	if err := td.db.Create(t).Error; err != nil {
-		return err
+	t.UserID = td.owner
+	t.Version = 1
+	if t.ID == 0 {
+		return td.db.Omit(clause.Associations).Create(t).Error
 	}
-	return nil
+	// Only an imported todo keeping its id can collide with an existing one. The insert runs in a
+	// savepoint as a failed statement aborts the whole transaction of the import on Postgres.
+	return td.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(t).Error; err != nil {
+			return duplicateError(err)
+		}
+		if db.IsPostgres(tx) {
+			// The id sequence isn't advanced by explicit ids, the next todos would collide with them.
+			return tx.Exec("SELECT setval(pg_get_serial_sequence('todos', 'id'), (SELECT MAX(id) FROM todos))").Error
+		}
+		return nil
+	})
 }
 
+// Update saves the todo if it is still at the version it was read at, and increments its version.
 func (td *todo) Update(t *model.Todo) error {
-	if err := td.db.Save(t).Error; err != nil {
-		return err
+	t.UserID = td.owner
+	version := t.Version
+	t.Version++
+	result := td.owned().Model(t).Select("*").Omit(clause.Associations).Where("todos.version = ?", version).Updates(t)
+	if result.Error != nil {
+		t.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		t.Version = version
+		return model.ErrVersionMismatch
 	}
 	return nil
 }
 
-func (td *todo) Delete(id int) error {
-	result := td.db.Where("id = ?", id).Delete(&model.Todo{})
-	if result.RowsAffected == 0 {
-		return model.ErrNotFound
-	}
+// Delete moves the todo to the trash if it is still at the version it was read at.
+func (td *todo) Delete(t *model.Todo) error {
+	result := td.owned().Where("todos.id = ? AND todos.version = ?", t.ID, t.Version).Delete(&model.Todo{})
 	if result.Error != nil {
 		return result.Error
 	}
-	log.Info("Deleted todo with id: ", id)
+	if result.RowsAffected == 0 {
+		return model.ErrVersionMismatch
+	}
+	log.Info("Deleted todo with id: ", t.ID)
 	return nil
 }
 
+// owned returns a query restricted to the todos of the owner.
+func (td *todo) owned() *gorm.DB {
+	return td.db.Where("todos.user_id = ?", td.owner)
+}
+
+// withAssociations returns a query on the todos of the owner preloading their series and tags.
+func (td *todo) withAssociations() *gorm.DB {
+	return td.owned().Preload("Series").Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("tags.name")
+	})
+}
+
 func (td *todo) Find(id int) (*model.Todo, error) {
 	var todo *model.Todo
-	err := td.db.Where("id = ?", id).Take(&todo).Error
+	err := td.withAssociations().Where("todos.id = ?", id).Take(&todo).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, model.ErrNotFound
@@ -65,20 +137,165 @@ func (td *todo) Find(id int) (*model.Todo, error) {
 	return todo, nil
 }
 
-func (td *todo) FindAll(qry map[string]interface{}) ([]*model.Todo, error) {
-	var todos []*model.Todo
-	tx := td.db
+func (td *todo) FindAll(qry map[string]interface{}, page model.PageRequest) (*model.TodoPage, error) {
+	tx := td.withAssociations().Model(&model.Todo{})
+	now := time.Now().UTC()
+
+	searching := false
+	if val, ok := qry["q"].(string); ok {
+		if td.search {
+			tx = tx.Joins("JOIN "+searchTable+" ON "+searchTable+".rowid = todos.id").
+				Where(searchTable+" MATCH ?", val)
+			searching = true
+		} else {
+			tx = taskContains(tx, val)
+		}
+		delete(qry, "q")
+	}
+
+	orders := newOrder(page.Sort)
+	if searching && len(page.Sort) == 0 {
+		orders = searchOrder
+	}
+	var after []interface{}
+	if page.Cursor != "" {
+		values, pinned, err := decodeCursor(orders, page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		// The pages after the first are read as of its time, the overdue filter and ordering included.
+		after, now = values, pinned
+	}
 
 	if val, ok := qry["task"].(string); ok {
-		tx = tx.Where("task LIKE ?", "%"+val+"%")
+		tx = taskContains(tx, val)
 		delete(qry, "task")
 	}
+	if val, ok := qry["project_id"].(int); ok {
+		tx = tx.Where("todos.project_id = ?", val)
+		delete(qry, "project_id")
+	} else {
+		// Todos of archived projects only show up when listing their project.
+		tx = tx.Where("todos.project_id IS NULL OR todos.project_id NOT IN ("+archivedProjectIDs+")", true)
+	}
+	if val, ok := qry["tag"].([]string); ok {
+		// Every tag must be present.
+		for _, name := range val {
+			tx = tx.Where("todos.id IN ("+taggedIDs+" WHERE tags.name = ?)", name)
+		}
+		delete(qry, "tag")
+	}
+	if val, ok := qry["tag_any"].([]string); ok {
+		tx = tx.Where("todos.id IN ("+taggedIDs+" WHERE tags.name IN ?)", val)
+		delete(qry, "tag_any")
+	}
+	if val, ok := qry["tag_none"].([]string); ok {
+		tx = tx.Where("todos.id NOT IN ("+taggedIDs+" WHERE tags.name IN ?)", val)
+		delete(qry, "tag_none")
+	}
+	if val, ok := qry["due_before"].(time.Time); ok {
+		tx = tx.Where("todos.due_at < ?", val)
+		delete(qry, "due_before")
+	}
+	if val, ok := qry["due_after"].(time.Time); ok {
+		tx = tx.Where("todos.due_at > ?", val)
+		delete(qry, "due_after")
+	}
+	if val, ok := qry["overdue"].(bool); ok {
+		if val {
+			tx = tx.Where("todos.due_at < ? AND todos.status <> ?", now, model.Done)
+		} else {
+			tx = tx.Where("todos.due_at IS NULL OR todos.due_at >= ? OR todos.status = ?", now, model.Done)
+		}
+		delete(qry, "overdue")
+	}
 	if len(qry) > 0 {
 		tx = tx.Where(qry)
 	}
-	err := tx.Order("priority desc").Order("created_at desc").Find(&todos).Error
-	if err != nil {
-		return nil, err
+
+	res := &model.TodoPage{}
+	if page.WithTotal {
+		var total int64
+		if err := tx.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, searchError(err)
+		}
+		res.Total = &total
+	}
+
+	if searching {
+		tx = tx.Select("todos.*, " + searchTable + ".rank AS rank, " +
+			"snippet(" + searchTable + ", 0, '<mark>', '</mark>', '...', 16) AS snippet")
+	}
+	if after != nil {
+		tx = seekAfter(tx, orders, after, now)
+	}
+	tx = tx.Order(orderBy(orders, now))
+
+	// Fetch one extra row to find out whether a next page exists.
+	var todos []*model.Todo
+	if err := tx.Limit(page.Limit + 1).Find(&todos).Error; err != nil {
+		return nil, searchError(err)
+	}
+	if len(todos) > page.Limit {
+		todos = todos[:page.Limit]
+		next, err := encodeCursor(orders, todos[len(todos)-1], now)
+		if err != nil {
+			return nil, err
+		}
+		res.NextCursor = next
+	}
+	res.Todos = todos
+	return res, nil
+}
+
+// eachBatchSize is the number of todos FindEach loads at once.
+const eachBatchSize = 100
+
+// FindEach calls fn with every todo in the order of their ids, loading them in batches.
+func (td *todo) FindEach(fn func(t *model.Todo) error) error {
+	var todos []*model.Todo
+	return td.withAssociations().FindInBatches(&todos, eachBatchSize, func(_ *gorm.DB, _ int) error {
+		for _, t := range todos {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (td *todo) Transaction(fn func(r Todo) error) error {
+	return td.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&todo{db: tx, search: td.search, owner: td.owner})
+	})
+}
+
+// DB returns the connection the repository reads and writes with, the transaction
+// for the repository given to the function of Transaction.
+func (td *todo) DB() *gorm.DB {
+	return td.db
+}
+
+// ForOwner returns the repository of the todos owned by the given user.
+func (td *todo) ForOwner(userID int) Todo {
+	return &todo{db: td.db, search: td.search, owner: userID}
+}
+
+// taskContains filters the todos whose task contains val, ignoring case. LIKE ignores the case of ASCII
+// letters on SQLite but not on Postgres, both sides are lowered to match the same todos on either.
+func taskContains(tx *gorm.DB, val string) *gorm.DB {
+	return tx.Where("LOWER(todos.task) LIKE LOWER(?)", "%"+val+"%")
+}
+
+// searchErrors are the messages FTS5 reports for malformed MATCH expressions.
+var searchErrors = []string{"fts5:", "unterminated string", "unknown special query"}
+
+// searchError maps FTS5 query syntax errors to model.ErrInvalidSearchQuery.
+func searchError(err error) error {
+	for _, msg := range searchErrors {
+		if strings.HasPrefix(err.Error(), msg) {
+			return model.ErrInvalidSearchQuery
+		}
 	}
-	return todos, nil
+	return err
 }
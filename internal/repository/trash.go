@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// trashed returns a query on the deleted todos of the owner.
+func (td *todo) trashed() *gorm.DB {
+	return td.owned().Unscoped().Where("todos.deleted_at IS NOT NULL")
+}
+
+// FindDeleted returns the todo of the given id from the trash.
+func (td *todo) FindDeleted(id int) (*model.Todo, error) {
+	var todo *model.Todo
+	err := td.trashed().Where("todos.id = ?", id).Take(&todo).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return todo, nil
+}
+
+// FindAllDeleted returns the todos in the trash, most recently deleted first.
+func (td *todo) FindAllDeleted() ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := td.trashed().Preload("Tags", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("tags.name")
+	}).Order("todos.deleted_at desc, todos.id desc").Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// Restore moves the todo of the given id out of the trash.
+func (td *todo) Restore(id int) error {
+	result := td.trashed().Model(&model.Todo{}).Where("todos.id = ?", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrNotFound
+	}
+	log.Info("Restored todo with id: ", id)
+	return nil
+}
+
+// Purge permanently deletes the todo of the given id from the trash.
+func (td *todo) Purge(id int) error {
+	if _, err := td.FindDeleted(id); err != nil {
+		return err
+	}
+	return td.db.Transaction(func(tx *gorm.DB) error {
+		return purge(tx, []int{id})
+	})
+}
+
+// PurgeAll permanently deletes every todo in the trash.
+func (td *todo) PurgeAll() (int64, error) {
+	var ids []int
+	if err := td.trashed().Model(&model.Todo{}).Pluck("todos.id", &ids).Error; err != nil {
+		return 0, err
+	}
+	err := td.db.Transaction(func(tx *gorm.DB) error {
+		return purge(tx, ids)
+	})
+	return int64(len(ids)), err
+}
+
+// PurgeDeletedBefore permanently deletes the todos of every user deleted before the given time.
+func (td *todo) PurgeDeletedBefore(before time.Time) (int64, error) {
+	var ids []int
+	err := td.db.Unscoped().Model(&model.Todo{}).
+		Where("todos.deleted_at IS NOT NULL AND todos.deleted_at < ?", before).
+		Pluck("todos.id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	err = td.db.Transaction(func(tx *gorm.DB) error {
+		return purge(tx, ids)
+	})
+	return int64(len(ids)), err
+}
+
+// purge permanently deletes the todos of the given ids along with their tags and revisions,
+// moving their remaining subtasks to the top level.
+func purge(tx *gorm.DB, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Exec("DELETE FROM todo_tags WHERE todo_id IN ?", ids).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("todo_id IN ?", ids).Delete(&model.Revision{}).Error; err != nil {
+		return err
+	}
+	err := tx.Unscoped().Model(&model.Todo{}).Where("parent_id IN ?", ids).
+		Updates(map[string]interface{}{"parent_id": nil, "version": gorm.Expr("version + 1")}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("id IN ?", ids).Delete(&model.Todo{}).Error; err != nil {
+		return err
+	}
+	log.Info("Purged todos with ids: ", ids)
+	return nil
+}
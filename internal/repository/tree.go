@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"gorm.io/gorm"
+)
+
+// descendantIDs selects the ids of every subtask below the todo bound to its placeholder.
+const descendantIDs = `WITH RECURSIVE subtree(id) AS (
+	SELECT id FROM todos WHERE parent_id = ?
+	UNION
+	SELECT todos.id FROM todos JOIN subtree ON todos.parent_id = subtree.id
+) SELECT id FROM subtree`
+
+func (td *todo) FindChildren(id int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := td.withAssociations().
+		Where("parent_id = ?", id).
+		Order(orderBy(defaultOrder, time.Now().UTC())).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (td *todo) FindChildrenOf(ids []int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := td.withAssociations().
+		Where("parent_id IN ?", ids).
+		Order(orderBy(defaultOrder, time.Now().UTC())).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (td *todo) FindDescendants(id int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := td.withAssociations().
+		Where("id IN ("+descendantIDs+")", id).
+		Order(orderBy(defaultOrder, time.Now().UTC())).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// Reparent moves the direct subtasks of the given todo under parentID.
+func (td *todo) Reparent(id int, parentID *int) error {
+	return td.owned().Model(&model.Todo{}).Where("parent_id = ?", id).
+		Updates(map[string]interface{}{"parent_id": parentID, "version": gorm.Expr("version + 1")}).Error
+}
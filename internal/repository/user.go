@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"gorm.io/gorm"
+)
+
+// User is the repository for user accounts.
+type User interface {
+	Create(u *model.User) error
+	Find(id int) (*model.User, error)
+	FindByEmail(email string) (*model.User, error)
+	ClaimUnowned(userID int) (int64, error)
+}
+
+type user struct {
+	db *gorm.DB
+}
+
+// NewUser returns a new instance of the user repository.
+func NewUser(db *gorm.DB) User {
+	return &user{
+		db: db,
+	}
+}
+
+func (ur *user) Create(u *model.User) error {
+	if err := ur.db.Create(u).Error; err != nil {
+		return duplicateError(err)
+	}
+	return nil
+}
+
+func (ur *user) Find(id int) (*model.User, error) {
+	return ur.take("id = ?", id)
+}
+
+func (ur *user) FindByEmail(email string) (*model.User, error) {
+	return ur.take("email = ?", model.NormalizeEmail(email))
+}
+
+func (ur *user) take(query string, args ...interface{}) (*model.User, error) {
+	var user *model.User
+	err := ur.db.Where(query, args...).Take(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ClaimUnowned gives the user the todos, projects and tags created before there were users, merging the tags
+// into the ones of the user of the same name. It returns the number of todos claimed.
+func (ur *user) ClaimUnowned(userID int) (int64, error) {
+	var claimed int64
+	err := ur.db.Transaction(func(tx *gorm.DB) error {
+		// Unowned tags are only attached to unowned todos, whose links move to the tag of the user of the same name.
+		err := tx.Exec("UPDATE todo_tags SET tag_id = (SELECT owned.id FROM tags JOIN tags AS owned ON owned.name = tags.name "+
+			"WHERE tags.id = todo_tags.tag_id AND owned.user_id = ?) WHERE tag_id IN (SELECT tags.id FROM tags "+
+			"JOIN tags AS owned ON owned.name = tags.name WHERE tags.user_id IS NULL AND owned.user_id = ?)", userID, userID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("DELETE FROM tags WHERE user_id IS NULL AND name IN (SELECT name FROM tags WHERE user_id = ?)", userID).Error
+		if err != nil {
+			return err
+		}
+		for _, m := range []interface{}{&model.Tag{}, &model.Project{}} {
+			if err := tx.Model(m).Where("user_id IS NULL").Update("user_id", userID).Error; err != nil {
+				return err
+			}
+		}
+		// Trashed todos are claimed too, hooks are skipped so that their update time is kept.
+		result := tx.Session(&gorm.Session{SkipHooks: true}).Unscoped().Model(&model.Todo{}).Where("user_id IS NULL").
+			UpdateColumn("user_id", userID)
+		claimed = result.RowsAffected
+		return result.Error
+	})
+	return claimed, err
+}
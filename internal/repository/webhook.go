@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Webhook is the repository for webhooks and their deliveries.
+type Webhook interface {
+	Create(w *model.Webhook) error
+	Delete(id int) error
+	Find(id int) (*model.Webhook, error)
+	FindAll() ([]*model.Webhook, error)
+	CreateDelivery(d *model.Delivery) error
+	UpdateDelivery(d *model.Delivery) error
+	FindDelivery(webhookID, id int) (*model.Delivery, error)
+	FindDeliveries(webhookID, limit int) ([]*model.Delivery, error)
+	FindDueDeliveries(before time.Time, limit int) ([]*model.Delivery, error)
+	FindWebhook(id int) (*model.Webhook, error)
+	ForOwner(userID int) Webhook
+	Within(tx *gorm.DB) Webhook
+}
+
+type webhook struct {
+	db *gorm.DB
+	// owner is the user whose webhooks the repository reads and writes.
+	owner int
+}
+
+// NewWebhook returns a new instance of the webhook repository.
+func NewWebhook(db *gorm.DB) Webhook {
+	return &webhook{
+		db: db,
+	}
+}
+
+func (wh *webhook) Create(w *model.Webhook) error {
+	w.UserID = wh.owner
+	if err := wh.db.Create(w).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes a webhook together with its deliveries.
+func (wh *webhook) Delete(id int) error {
+	return wh.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("webhooks.user_id = ?", wh.owner).Delete(&model.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrNotFound
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&model.Delivery{}).Error; err != nil {
+			return err
+		}
+		log.Info("Deleted webhook with id: ", id)
+		return nil
+	})
+}
+
+func (wh *webhook) Find(id int) (*model.Webhook, error) {
+	var w *model.Webhook
+	if err := wh.owned().Take(&w, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+func (wh *webhook) FindAll() ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	if err := wh.owned().Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (wh *webhook) CreateDelivery(d *model.Delivery) error {
+	if err := wh.db.Create(d).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDelivery saves the outcome of an attempt of a delivery.
+func (wh *webhook) UpdateDelivery(d *model.Delivery) error {
+	return wh.db.Model(d).Select("Status", "Attempts", "NextAttemptAt", "LastAttemptAt", "ResponseStatus", "LastError").
+		Updates(d).Error
+}
+
+// FindDelivery returns a delivery of a webhook of the owner.
+func (wh *webhook) FindDelivery(webhookID, id int) (*model.Delivery, error) {
+	var d *model.Delivery
+	err := wh.deliveries(webhookID).Take(&d, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// FindDeliveries returns the latest deliveries of a webhook of the owner, newest first.
+func (wh *webhook) FindDeliveries(webhookID, limit int) ([]*model.Delivery, error) {
+	var deliveries []*model.Delivery
+	if err := wh.deliveries(webhookID).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindDueDeliveries returns the pending deliveries of every user due before the given time, oldest first.
+func (wh *webhook) FindDueDeliveries(before time.Time, limit int) ([]*model.Delivery, error) {
+	var deliveries []*model.Delivery
+	err := wh.db.Where("status = ? AND next_attempt_at <= ?", model.PendingDelivery, before).
+		Order("next_attempt_at, id").Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindWebhook returns the webhook of any user with the given id.
+func (wh *webhook) FindWebhook(id int) (*model.Webhook, error) {
+	var w *model.Webhook
+	if err := wh.db.Take(&w, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, model.ErrNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// ForOwner returns the repository of the webhooks owned by the given user.
+func (wh *webhook) ForOwner(userID int) Webhook {
+	return &webhook{db: wh.db, owner: userID}
+}
+
+// Within returns the repository of the webhooks of the owner reading and writing with tx, the
+// connection of a todo repository running in a transaction.
+func (wh *webhook) Within(tx *gorm.DB) Webhook {
+	return &webhook{db: tx, owner: wh.owner}
+}
+
+// owned returns a query restricted to the webhooks of the owner.
+func (wh *webhook) owned() *gorm.DB {
+	return wh.db.Where("webhooks.user_id = ?", wh.owner)
+}
+
+// deliveries returns a query on the deliveries of a webhook of the owner.
+func (wh *webhook) deliveries(webhookID int) *gorm.DB {
+	return wh.db.Where("deliveries.webhook_id = ? AND deliveries.webhook_id IN (SELECT webhooks.id FROM webhooks WHERE webhooks.user_id = ?)",
+		webhookID, wh.owner)
+}
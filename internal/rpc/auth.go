@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/pb/todov1"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// readMethods are the methods of the TodoService allowed to read-only API keys.
+var readMethods = map[string]bool{
+	todov1.TodoService_GetTodo_FullMethodName:   true,
+	todov1.TodoService_ListTodos_FullMethodName: true,
+	todov1.TodoService_Watch_FullMethodName:     true,
+}
+
+// userIDKey is the key of the id of the authenticated user in the context of a call.
+type userIDKey struct{}
+
+// currentUser returns the id of the user authenticated for the call.
+func currentUser(ctx context.Context) int {
+	userID, _ := ctx.Value(userIDKey{}).(int)
+	return userID
+}
+
+// Authenticator checks the access token or API key sent as the authorization metadata of the calls to the
+// TodoService, as handler.Authenticate does for the requests. Other services, such as reflection, are open.
+type Authenticator struct {
+	credentials service.Credential
+}
+
+// NewAuthenticator returns a new instance of the authenticator.
+func NewAuthenticator(credentials service.Credential) *Authenticator {
+	return &Authenticator{credentials: credentials}
+}
+
+// Unary returns the interceptor authenticating unary calls.
+func (a *Authenticator) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor authenticating streaming calls.
+func (a *Authenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate returns the context of a call with the id of the authenticated user.
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, "/"+todov1.TodoService_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var credential string
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, value, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			credential = value
+		}
+	}
+	if credential == "" {
+		return nil, status.Error(codes.Unauthenticated, model.ErrInvalidToken.Error())
+	}
+
+	principal, err := a.credentials.Verify(credential)
+	if err != nil {
+		if err == model.ErrInvalidToken {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	// The scopes are defined on HTTP methods, reading methods stand for GET.
+	httpMethod := http.MethodPost
+	if readMethods[method] {
+		httpMethod = http.MethodGet
+	}
+	if !principal.Scope.Allows(httpMethod) {
+		return nil, status.Error(codes.PermissionDenied, model.ErrInsufficientScope.Error())
+	}
+	return context.WithValue(ctx, userIDKey{}, principal.UserID), nil
+}
+
+// authenticatedStream is a server stream whose context carries the authenticated user.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,361 @@
+// Package rpc provides the gRPC services of the application.
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/event"
+	"github.com/fardinabir/todo-manager-app/internal/handler"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/pb/todov1"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// statuses maps the statuses of the protobuf messages to those of the model.
+var statuses = map[todov1.Status]model.Status{
+	todov1.Status_STATUS_CREATED:    model.Created,
+	todov1.Status_STATUS_PROCESSING: model.Processing,
+	todov1.Status_STATUS_DONE:       model.Done,
+}
+
+type todoServer struct {
+	todov1.UnimplementedTodoServiceServer
+	service   service.Todo
+	broker    *event.Broker
+	validator *handler.CustomValidator
+}
+
+// NewTodo returns a new instance of the TodoService on top of the todo service, its changes being
+// watched through the broker. Requests are checked with the validation rules of the REST API.
+func NewTodo(s service.Todo, b *event.Broker) todov1.TodoServiceServer {
+	return &todoServer{service: s, broker: b, validator: handler.NewCustomValidator()}
+}
+
+func (t *todoServer) CreateTodo(ctx context.Context, in *todov1.CreateTodoRequest) (*todov1.Todo, error) {
+	req := handler.CreateRequest{
+		Task:         in.Task,
+		Priority:     model.Priority(in.Priority),
+		DueAt:        timeOf(in.DueAt),
+		ScheduledFor: timeOf(in.ScheduledFor),
+		Recurrence:   in.Recurrence,
+		ParentID:     intOf(in.ParentId),
+		Tags:         in.Tags,
+		ProjectID:    intOf(in.ProjectId),
+	}
+	if err := t.validator.Validate(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	todo, err := t.service.ForOwner(currentUser(ctx)).Create(req.Task, req.Priority, req.Details())
+	if err != nil {
+		return nil, todoError(err)
+	}
+	return newTodo(todo), nil
+}
+
+func (t *todoServer) GetTodo(ctx context.Context, in *todov1.GetTodoRequest) (*todov1.Todo, error) {
+	todo, err := t.service.ForOwner(currentUser(ctx)).Find(int(in.Id))
+	if err != nil {
+		return nil, todoError(err)
+	}
+	return newTodo(todo), nil
+}
+
+func (t *todoServer) ListTodos(ctx context.Context, in *todov1.ListTodosRequest) (*todov1.ListTodosResponse, error) {
+	req := handler.FindAllRequest{
+		Limit:     int(in.Limit),
+		Cursor:    in.Cursor,
+		WithTotal: in.WithTotal,
+		Sort:      in.Sort,
+	}
+	if err := t.validator.Validate(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	sortFields, err := model.ParseSort(req.Sort)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	qry := url.Values{}
+	if in.Q != "" {
+		qry.Set("q", in.Q)
+	}
+	if in.Task != "" {
+		qry.Set("task", in.Task)
+	}
+	if in.Status != todov1.Status_STATUS_UNSPECIFIED {
+		qry.Set("status", string(statusOf(in.Status)))
+	}
+	if in.DueBefore != nil {
+		qry.Set("due_before", in.DueBefore.AsTime().Format(time.RFC3339Nano))
+	}
+	if in.DueAfter != nil {
+		qry.Set("due_after", in.DueAfter.AsTime().Format(time.RFC3339Nano))
+	}
+	if in.Overdue != nil {
+		qry.Set("overdue", strconv.FormatBool(*in.Overdue))
+	}
+	for key, tags := range map[string][]string{"tag": in.Tags, "tag_any": in.TagsAny, "tag_none": in.TagsNone} {
+		if len(tags) > 0 {
+			qry[key] = tags
+		}
+	}
+	if in.ProjectId != nil {
+		qry.Set("project_id", strconv.FormatInt(*in.ProjectId, 10))
+	}
+
+	page, err := t.service.ForOwner(currentUser(ctx)).FindAll(qry, model.NewPageRequest(req.Limit, req.Cursor, req.WithTotal, sortFields))
+	if err != nil {
+		return nil, todoError(err)
+	}
+	res := &todov1.ListTodosResponse{Todos: make([]*todov1.Todo, len(page.Todos)), NextCursor: page.NextCursor, Total: page.Total}
+	for i, todo := range page.Todos {
+		res.Todos[i] = newTodo(todo)
+	}
+	return res, nil
+}
+
+func (t *todoServer) UpdateTodo(ctx context.Context, in *todov1.UpdateTodoRequest) (*todov1.Todo, error) {
+	if len(in.GetUpdateMask().GetPaths()) > 0 {
+		return t.patchTodo(ctx, in)
+	}
+	req := handler.UpdateRequestBody{
+		Task:         in.Task,
+		Status:       statusOf(in.Status),
+		Priority:     model.Priority(in.Priority),
+		DueAt:        timeOf(in.DueAt),
+		ScheduledFor: timeOf(in.ScheduledFor),
+		Recurrence:   in.Recurrence,
+		Scope:        model.EditScope(in.Scope),
+		ParentID:     intOf(in.ParentId),
+		Force:        in.Force,
+		Tags:         in.Tags,
+		ProjectID:    intOf(in.ProjectId),
+	}
+	if err := t.validator.Validate(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts := req.Options()
+	if in.IfMatchVersion != nil {
+		opts.IfMatch = model.Precondition{int(*in.IfMatchVersion)}
+	}
+
+	todo, err := t.service.ForOwner(currentUser(ctx)).Update(int(in.Id), req.Task, req.Priority, req.Status, req.Details(), opts)
+	if err != nil {
+		return nil, todoError(err)
+	}
+	return newTodo(todo), nil
+}
+
+// patchTodo changes the fields of a todo listed by the update mask of the request, as a merge patch
+// of the REST API would.
+func (t *todoServer) patchTodo(ctx context.Context, in *todov1.UpdateTodoRequest) (*todov1.Todo, error) {
+	// The fields are named as in the JSON body of the REST API, an empty value clears the field.
+	fields := map[string]interface{}{
+		"task":          in.Task,
+		"status":        statusOf(in.Status),
+		"priority":      model.Priority(in.Priority),
+		"due_at":        timeOf(in.DueAt),
+		"scheduled_for": timeOf(in.ScheduledFor),
+		"parent_id":     intOf(in.ParentId),
+		"tags":          in.Tags,
+		"project_id":    intOf(in.ProjectId),
+	}
+	patch := make(map[string]interface{}, len(in.UpdateMask.Paths)+3)
+	for _, path := range in.UpdateMask.Paths {
+		value, ok := fields[path]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown field in the update mask: %s", path)
+		}
+		patch[path] = value
+	}
+	// Recurrence, scope and force are not stored, they only apply to this update.
+	if in.Recurrence != "" {
+		patch["recurrence"] = in.Recurrence
+	}
+	if in.Scope != "" {
+		patch["scope"] = in.Scope
+	}
+	if in.Force {
+		patch["force"] = true
+	}
+	var precondition model.Precondition
+	if in.IfMatchVersion != nil {
+		precondition = model.Precondition{int(*in.IfMatchVersion)}
+	}
+
+	todo, err := handler.ApplyPatch(t.validator.Validate, t.service.ForOwner(currentUser(ctx)), int(in.Id), patch, precondition)
+	if err != nil {
+		if he, ok := err.(*echo.HTTPError); ok {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprint(he.Message))
+		}
+		return nil, todoError(err)
+	}
+	return newTodo(todo), nil
+}
+
+func (t *todoServer) DeleteTodo(ctx context.Context, in *todov1.DeleteTodoRequest) (*emptypb.Empty, error) {
+	req := handler.DeleteRequest{ID: int(in.Id), Mode: model.DeleteMode(in.Mode)}
+	if err := t.validator.Validate(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var precondition model.Precondition
+	if in.IfMatchVersion != nil {
+		precondition = model.Precondition{int(*in.IfMatchVersion)}
+	}
+
+	if err := t.service.ForOwner(currentUser(ctx)).Delete(req.ID, req.Mode, precondition); err != nil {
+		return nil, todoError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (t *todoServer) Watch(in *todov1.WatchRequest, stream todov1.TodoService_WatchServer) error {
+	var lastEventID *int
+	if in.LastEventId != nil {
+		id := int(*in.LastEventId)
+		lastEventID = &id
+	}
+	sub, err := t.broker.Subscribe(currentUser(stream.Context()), lastEventID)
+	if err != nil {
+		if err == event.ErrClosed {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer sub.Close()
+	// The header tells the client the subscription is made, the changes made from now on being streamed.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	if sub.Reset {
+		if err := stream.Send(&todov1.TodoEvent{Type: "reset"}); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.Done():
+			// The subscriber lagged behind or the server is shutting down, the client resumes from its last event.
+			return status.Error(codes.Unavailable, "event stream ended")
+		case e := <-sub.Events():
+			if err := stream.Send(newTodoEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// todoError returns the status matching an error of the todo service, as handler.todoError does for HTTP.
+func todoError(err error) error {
+	switch err {
+	case model.ErrNotFound:
+		return status.Error(codes.NotFound, "todo not found")
+	case model.ErrVersionMismatch:
+		return status.Error(codes.Aborted, err.Error())
+	case model.ErrRecurrenceWithoutDueDate, model.ErrRecurrenceScope, model.ErrParentNotFound, model.ErrParentCycle,
+		model.ErrProjectNotFound, model.ErrInvalidCursor, model.ErrInvalidSearchQuery:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case model.ErrOpenChildren, model.ErrHasChildren:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// statusOf returns the model status of a protobuf status, an unknown value being left for the validation to reject.
+func statusOf(s todov1.Status) model.Status {
+	if s == todov1.Status_STATUS_UNSPECIFIED {
+		return ""
+	}
+	if st, ok := statuses[s]; ok {
+		return st
+	}
+	return model.Status(s.String())
+}
+
+// newTodo returns the protobuf message of a todo.
+func newTodo(todo *model.Todo) *todov1.Todo {
+	res := &todov1.Todo{
+		Id:           int64(todo.ID),
+		Task:         todo.Task,
+		Priority:     todov1.Priority(todo.Priority),
+		CreatedAt:    timestamppb.New(todo.CreatedAt),
+		UpdatedAt:    timestamppb.New(todo.UpdatedAt),
+		DueAt:        timestampOf(todo.DueAt),
+		ScheduledFor: timestampOf(todo.ScheduledFor),
+		ParentId:     int64Of(todo.ParentID),
+		SeriesId:     int64Of(todo.SeriesID),
+		ProjectId:    int64Of(todo.ProjectID),
+		Tags:         todo.Tags.Names(),
+		Version:      int64(todo.Version),
+	}
+	for s, st := range statuses {
+		if st == todo.Status {
+			res.Status = s
+		}
+	}
+	return res
+}
+
+// newTodoEvent returns the protobuf message of a todo event.
+func newTodoEvent(e *model.TodoEvent) *todov1.TodoEvent {
+	res := &todov1.TodoEvent{
+		Id:         int64(e.ID),
+		Type:       string(e.Type),
+		TodoId:     int64(e.TodoID),
+		OccurredAt: timestamppb.New(e.OccurredAt),
+	}
+	for field := range e.Changes {
+		res.ChangedFields = append(res.ChangedFields, field)
+	}
+	sort.Strings(res.ChangedFields)
+	if e.Todo != nil {
+		res.Todo = newTodo(e.Todo)
+	}
+	return res
+}
+
+func timeOf(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.AsTime()
+	return &v
+}
+
+func timestampOf(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func intOf(i *int64) *int {
+	if i == nil {
+		return nil
+	}
+	v := int(*i)
+	return &v
+}
+
+func int64Of(i *int) *int64 {
+	if i == nil {
+		return nil
+	}
+	v := int64(*i)
+	return &v
+}
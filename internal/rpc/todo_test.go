@@ -0,0 +1,297 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/event"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/pb/todov1"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+var testAuthConfig = model.Auth{SigningKey: "test-signing-key-of-at-least-32-bytes"}
+
+// newTestClient serves the TodoService over an in-memory listener and returns a client of it.
+func newTestClient(t *testing.T, gdb *gorm.DB) todov1.TodoServiceClient {
+	todoService := service.NewTodo(repository.NewTodo(gdb), repository.NewProject(gdb), repository.NewWebhook(gdb))
+	broker := event.NewBroker(todoService, event.Options{PollInterval: 10 * time.Millisecond})
+	authenticator := NewAuthenticator(service.NewCredential(service.NewAuth(repository.NewUser(gdb), testAuthConfig), service.NewAPIKey(repository.NewAPIKey(gdb))))
+	server := grpc.NewServer(grpc.UnaryInterceptor(authenticator.Unary()), grpc.StreamInterceptor(authenticator.Stream()))
+	todov1.RegisterTodoServiceServer(server, NewTodo(todoService, broker))
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis) //nolint:errcheck
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		broker.Close()
+		server.Stop()
+	})
+	return todov1.NewTodoServiceClient(conn)
+}
+
+// login registers a user and returns the context of its calls.
+func login(t *testing.T, gdb *gorm.DB, email string) context.Context {
+	auth := service.NewAuth(repository.NewUser(gdb), testAuthConfig)
+	_, err := auth.Register(email, "correct horse")
+	require.NoError(t, err)
+	token, err := auth.Login(email, "correct horse")
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token.Token)
+}
+
+func clearDB(db *gorm.DB, models ...interface{}) {
+	for _, model := range models {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(model)
+	}
+}
+
+func TestTodoServer(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.Tag{}, model.APIKey{}, model.User{}) })
+	client := newTestClient(t, dbInstance)
+	ctx := login(t, dbInstance, "grpc@example.com")
+	otherCtx := login(t, dbInstance, "grpc-other@example.com")
+
+	due := timestamppb.New(time.Now().Add(24 * time.Hour).Truncate(time.Second))
+	created, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: "Write the gRPC API", Priority: todov1.Priority_PRIORITY_HIGH, DueAt: due, Tags: []string{"work"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Write the gRPC API", created.Task)
+	assert.Equal(t, todov1.Status_STATUS_CREATED, created.Status)
+	assert.Equal(t, todov1.Priority_PRIORITY_HIGH, created.Priority)
+	assert.True(t, due.AsTime().Equal(created.DueAt.AsTime()))
+	assert.Equal(t, []string{"work"}, created.Tags)
+
+	t.Run("Create with an invalid request", func(t *testing.T) {
+		_, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: ""})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: "No due date", Priority: todov1.Priority_PRIORITY_LOW, Recurrence: "FREQ=DAILY"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		todo, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: created.Id})
+		require.NoError(t, err)
+		assert.Equal(t, created.Task, todo.Task)
+
+		_, err = client.GetTodo(otherCtx, &todov1.GetTodoRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("List", func(t *testing.T) {
+		res, err := client.ListTodos(ctx, &todov1.ListTodosRequest{Tags: []string{"work"}, WithTotal: true})
+		require.NoError(t, err)
+		require.Len(t, res.Todos, 1)
+		assert.Equal(t, created.Id, res.Todos[0].Id)
+		require.NotNil(t, res.Total)
+		assert.EqualValues(t, 1, *res.Total)
+
+		res, err = client.ListTodos(ctx, &todov1.ListTodosRequest{Status: todov1.Status_STATUS_DONE})
+		require.NoError(t, err)
+		assert.Empty(t, res.Todos)
+
+		res, err = client.ListTodos(otherCtx, &todov1.ListTodosRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, res.Todos)
+
+		_, err = client.ListTodos(ctx, &todov1.ListTodosRequest{Sort: "unknown"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.ListTodos(ctx, &todov1.ListTodosRequest{Cursor: "not a cursor"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		stale := created.Version
+		updated, err := client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: created.Id, Task: "Ship the gRPC API", Status: todov1.Status_STATUS_DONE,
+			Priority: todov1.Priority_PRIORITY_LOW, IfMatchVersion: &stale})
+		require.NoError(t, err)
+		assert.Equal(t, "Ship the gRPC API", updated.Task)
+		assert.Equal(t, todov1.Status_STATUS_DONE, updated.Status)
+		assert.Nil(t, updated.DueAt)
+		assert.Empty(t, updated.Tags)
+		assert.Greater(t, updated.Version, stale)
+
+		_, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: created.Id, Task: "Stale", Status: todov1.Status_STATUS_DONE,
+			Priority: todov1.Priority_PRIORITY_LOW, IfMatchVersion: &stale})
+		assert.Equal(t, codes.Aborted, status.Code(err))
+
+		_, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: created.Id, Task: "No status", Priority: todov1.Priority_PRIORITY_LOW})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Update with a mask", func(t *testing.T) {
+		todo, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: "Review the mask", Priority: todov1.Priority_PRIORITY_LOW, DueAt: due, Tags: []string{"work"}})
+		require.NoError(t, err)
+		t.Cleanup(func() { _, _ = client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: todo.Id}) })
+
+		// The fields left out of the mask are kept, even though they are not set in the request.
+		updated, err := client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: todo.Id, Priority: todov1.Priority_PRIORITY_HIGH,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"priority"}}})
+		require.NoError(t, err)
+		assert.Equal(t, "Review the mask", updated.Task)
+		assert.Equal(t, todov1.Priority_PRIORITY_HIGH, updated.Priority)
+		assert.Equal(t, todov1.Status_STATUS_CREATED, updated.Status)
+		require.NotNil(t, updated.DueAt)
+		assert.True(t, due.AsTime().Equal(updated.DueAt.AsTime()))
+		assert.Equal(t, []string{"work"}, updated.Tags)
+
+		// A field of the mask left out of the request is cleared.
+		updated, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: todo.Id,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"due_at", "tags"}}})
+		require.NoError(t, err)
+		assert.Nil(t, updated.DueAt)
+		assert.Empty(t, updated.Tags)
+		assert.Equal(t, todov1.Priority_PRIORITY_HIGH, updated.Priority)
+
+		_, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: todo.Id,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"task"}}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: todo.Id,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"version"}}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		stale := todo.Version
+		_, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: todo.Id, Task: "Stale", IfMatchVersion: &stale,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"task"}}})
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: created.Id, Mode: "unknown"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.DeleteTodo(otherCtx, &todov1.DeleteTodoRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: created.Id})
+		require.NoError(t, err)
+		_, err = client.GetTodo(ctx, &todov1.GetTodoRequest{Id: created.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestTodoServer_Watch(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.APIKey{}, model.User{}) })
+	client := newTestClient(t, dbInstance)
+	ctx := login(t, dbInstance, "grpc-watch@example.com")
+	otherCtx := login(t, dbInstance, "grpc-watch-other@example.com")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &todov1.WatchRequest{})
+	require.NoError(t, err)
+	// The header is sent once the subscription is made, the events created from now on are streamed.
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	_, err = client.CreateTodo(otherCtx, &todov1.CreateTodoRequest{Task: "Not mine", Priority: todov1.Priority_PRIORITY_LOW})
+	require.NoError(t, err)
+	created, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: "Watch me", Priority: todov1.Priority_PRIORITY_LOW})
+	require.NoError(t, err)
+
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, string(model.TodoCreated), e.Type)
+	assert.Equal(t, created.Id, e.TodoId)
+	require.NotNil(t, e.Todo)
+	assert.Equal(t, "Watch me", e.Todo.Task)
+}
+
+func TestAuthenticator(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	err = db.Migrate(dbInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { clearDB(dbInstance, model.Todo{}, model.APIKey{}, model.User{}) })
+	client := newTestClient(t, dbInstance)
+	ctx := login(t, dbInstance, "grpc-auth@example.com")
+
+	user, err := repository.NewUser(dbInstance).FindByEmail("grpc-auth@example.com")
+	require.NoError(t, err)
+	_, readKey, err := service.NewAPIKey(repository.NewAPIKey(dbInstance)).ForOwner(user.ID).Create("read", model.ReadScope)
+	require.NoError(t, err)
+	readCtx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+readKey)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		want codes.Code
+	}{
+		{
+			name: "No credential",
+			ctx:  context.Background(),
+			call: func(ctx context.Context) error {
+				_, err := client.ListTodos(ctx, &todov1.ListTodosRequest{})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "Invalid token",
+			ctx:  metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer invalid"),
+			call: func(ctx context.Context) error {
+				_, err := client.ListTodos(ctx, &todov1.ListTodosRequest{})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "Access token",
+			ctx:  ctx,
+			call: func(ctx context.Context) error {
+				_, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: "Authenticated", Priority: todov1.Priority_PRIORITY_LOW})
+				return err
+			},
+			want: codes.OK,
+		},
+		{
+			name: "Read API key listing",
+			ctx:  readCtx,
+			call: func(ctx context.Context) error {
+				_, err := client.ListTodos(ctx, &todov1.ListTodosRequest{})
+				return err
+			},
+			want: codes.OK,
+		},
+		{
+			name: "Read API key creating",
+			ctx:  readCtx,
+			call: func(ctx context.Context) error {
+				_, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Task: "Not allowed", Priority: todov1.Priority_PRIORITY_LOW})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, status.Code(tt.call(tt.ctx)))
+		})
+	}
+}
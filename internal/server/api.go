@@ -34,7 +34,7 @@ func NewAPI(opts TodoAPIServerOpts) (Server, error) {
 	logger := log.NewEntry(log.StandardLogger())
 	log.SetFormatter(&log.JSONFormatter{})
 
-	dbInstance, err := db.New(opts.Config.SQLite.DBFilename)
+	dbInstance, err := db.New(opts.Config.Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
@@ -43,7 +43,7 @@ func NewAPI(opts TodoAPIServerOpts) (Server, error) {
 	engine.HideBanner = true
 	engine.HidePort = true
 
-	handler.Register(engine, dbInstance)
+	handler.Register(engine, dbInstance, opts.Config.Auth)
 
 	allowOrigins := []string{opts.Config.UI.URL}
 	if opts.Config.SwaggerServer.Enable {
@@ -51,9 +51,10 @@ func NewAPI(opts TodoAPIServerOpts) (Server, error) {
 	}
 	log.Info("CORS allowed origins: ", allowOrigins)
 	engine.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: allowOrigins,
-		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  allowOrigins,
+		AllowMethods:  []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "If-Match", "Last-Event-ID"},
+		ExposeHeaders: []string{"ETag"},
 	}))
 
 	engine.Use(requestLogger())
@@ -22,8 +22,9 @@ func TestNewAPI(t *testing.T) {
 			opts: TodoAPIServerOpts{
 				ListenPort: 8080,
 				Config: model.Config{
-					SQLite: model.SQLite{
-						DBFilename: ":memory:",
+					Database: model.Database{
+						Driver: "sqlite",
+						DSN:    ":memory:",
 					},
 					UI: model.UI{
 						URL: "http://localhost:3000",
@@ -41,8 +42,9 @@ func TestNewAPI(t *testing.T) {
 			opts: TodoAPIServerOpts{
 				ListenPort: 8080,
 				Config: model.Config{
-					SQLite: model.SQLite{
-						DBFilename: "/invalid/path/to/db",
+					Database: model.Database{
+						Driver: "sqlite",
+						DSN:    "/invalid/path/to/db",
 					},
 					UI: model.UI{
 						URL: "http://localhost:3000",
@@ -55,6 +57,19 @@ func TestNewAPI(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Unsupported database driver",
+			opts: TodoAPIServerOpts{
+				ListenPort: 8080,
+				Config: model.Config{
+					Database: model.Database{
+						Driver: "mysql",
+						DSN:    "todo:todo@/todo",
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
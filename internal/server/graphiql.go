@@ -0,0 +1,43 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// graphiQLPage is the GraphiQL IDE, loaded from a CDN and sending its queries to the GraphQL endpoint of the API server.
+var graphiQLPage = template.Must(template.New("graphiql").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+  <style>body { margin: 0; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+  <div id="graphiql">Loading…</div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    // The API requires an access token, set it in the headers tab.
+    ReactDOM.createRoot(document.getElementById("graphiql")).render(React.createElement(GraphiQL, {
+      fetcher: GraphiQL.createFetcher({ url: {{.Endpoint}} }),
+      defaultHeaders: JSON.stringify({ Authorization: "Bearer <access token>" }, null, 2),
+      defaultEditorToolsVisibility: "headers",
+    }));
+  </script>
+</body>
+</html>
+`))
+
+// graphiQL returns the handler serving GraphiQL for the given GraphQL endpoint.
+func graphiQL(endpoint string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		c.Response().WriteHeader(http.StatusOK)
+		return graphiQLPage.Execute(c.Response(), struct{ Endpoint string }{endpoint})
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/fardinabir/todo-manager-app/internal/common"
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/event"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/pb/todov1"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/rpc"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"gorm.io/gorm"
+)
+
+// grpcServer is the gRPC server for Todo
+type grpcServer struct {
+	port   int
+	server *grpc.Server
+	broker *event.Broker
+}
+
+// GRPCServerOpts is the options for the grpcServer
+type GRPCServerOpts struct {
+	ListenPort int
+	Config     model.Config
+}
+
+// NewGRPC returns a new instance of the gRPC server
+func NewGRPC(opts GRPCServerOpts) (Server, error) {
+	dbInstance, err := db.New(opts.Config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	return newGRPCServer(dbInstance, opts.ListenPort, opts.Config.Auth), nil
+}
+
+func newGRPCServer(gdb *gorm.DB, port int, authConfig model.Auth) *grpcServer {
+	todoService := service.NewTodo(repository.NewTodo(gdb), repository.NewProject(gdb), repository.NewWebhook(gdb))
+	broker := event.NewBroker(todoService, event.Options{})
+	authenticator := rpc.NewAuthenticator(service.NewCredential(service.NewAuth(repository.NewUser(gdb), authConfig), service.NewAPIKey(repository.NewAPIKey(gdb))))
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryLogger, authenticator.Unary()),
+		grpc.ChainStreamInterceptor(streamLogger, authenticator.Stream()),
+	)
+	todov1.RegisterTodoServiceServer(server, rpc.NewTodo(todoService, broker))
+	// Reflection lets clients such as grpcurl discover the services.
+	reflection.Register(server)
+
+	return &grpcServer{
+		port:   port,
+		server: server,
+		broker: broker,
+	}
+}
+
+func (s *grpcServer) Name() string {
+	return "grpcServer"
+}
+
+// Run starts the gRPC server
+func (s *grpcServer) Run() error {
+	log.Infof("%s %s serving on port %d", s.Name(), common.GetVersion(), s.port)
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(lis)
+}
+
+// Shutdown waits for the pending calls to finish, Watch streams being ended, and cancels them once ctx is done
+func (s *grpcServer) Shutdown(ctx context.Context) error {
+	log.Infof("shuting down %s %s serving on port %d", s.Name(), common.GetVersion(), s.port)
+	s.broker.Close()
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+	return nil
+}
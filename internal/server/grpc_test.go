@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/pb/todov1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGRPC(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    GRPCServerOpts
+		wantErr bool
+	}{
+		{
+			name: "Valid configuration",
+			opts: GRPCServerOpts{
+				ListenPort: 9090,
+				Config: model.Config{
+					Database: model.Database{
+						Driver: "sqlite",
+						DSN:    ":memory:",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid database configuration",
+			opts: GRPCServerOpts{
+				ListenPort: 9090,
+				Config: model.Config{
+					Database: model.Database{
+						Driver: "sqlite",
+						DSN:    "/invalid/path/to/db",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := NewGRPC(tt.opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Nil(t, server)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "grpcServer", server.Name())
+			assert.Equal(t, tt.opts.ListenPort, server.(*grpcServer).port)
+
+			services := server.(*grpcServer).server.GetServiceInfo()
+			assert.Contains(t, services, todov1.TodoService_ServiceDesc.ServiceName)
+			assert.Contains(t, services, "grpc.reflection.v1.ServerReflection")
+			assert.NoError(t, server.Shutdown(context.Background()))
+		})
+	}
+}
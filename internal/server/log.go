@@ -1,9 +1,17 @@
 package server
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func writeRequestLogJSON(_ echo.Context, v middleware.RequestLoggerValues) error {
@@ -37,3 +45,34 @@ func requestLogger() echo.MiddlewareFunc {
 		LogResponseSize:  true,
 	})
 }
+
+// unaryLogger logs the unary calls as requestLogger does for the requests.
+func unaryLogger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	writeCallLog(ctx, info.FullMethod, start, err)
+	return res, err
+}
+
+// streamLogger logs the streaming calls once they end.
+func streamLogger(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	writeCallLog(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func writeCallLog(ctx context.Context, method string, start time.Time, err error) {
+	fields := log.Fields{
+		"method":  method,
+		"status":  status.Code(err).String(),
+		"latency": time.Since(start),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields["remote_ip"] = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		fields["user_agent"] = strings.Join(md.Get("user-agent"), " ")
+	}
+	log.WithFields(fields).Info("finished")
+}
@@ -20,6 +20,8 @@ type swaggerServer struct {
 // SwaggerServerOpts is the options for the swaggerServer
 type SwaggerServerOpts struct {
 	ListenPort int
+	// GraphQLEndpoint is the URL GraphiQL sends its queries to, GraphiQL is not served when empty.
+	GraphQLEndpoint string
 }
 
 // NewSwagger returns a new instance of the Swagger server
@@ -34,6 +36,9 @@ func NewSwagger(opts SwaggerServerOpts) Server {
 	engine.Use(requestLogger())
 
 	engine.GET("/swagger/*", echoSwagger.WrapHandler)
+	if opts.GraphQLEndpoint != "" {
+		engine.GET("/graphiql", graphiQL(opts.GraphQLEndpoint))
+	}
 
 	s := &swaggerServer{
 		port:   opts.ListenPort,
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -34,3 +36,17 @@ func TestNewSwagger(t *testing.T) {
 	}
 	assert.True(t, foundSwaggerRoute, "Swagger route not found")
 }
+
+func TestNewSwagger_GraphiQL(t *testing.T) {
+	server := NewSwagger(SwaggerServerOpts{ListenPort: 8080})
+	rec := httptest.NewRecorder()
+	server.(*swaggerServer).engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphiql", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	server = NewSwagger(SwaggerServerOpts{ListenPort: 8080, GraphQLEndpoint: "http://localhost:1314/api/v1/graphql"})
+	rec = httptest.NewRecorder()
+	server.(*swaggerServer).engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphiql", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMETextHTMLCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Body.String(), `url: "http://localhost:1314/api/v1/graphql"`)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// trashSweeper periodically purges the todos that have been in the trash longer than the retention period
+type trashSweeper struct {
+	todos     service.Todo
+	retention time.Duration
+	interval  time.Duration
+	done      chan struct{}
+	stopOnce  sync.Once
+}
+
+// TrashSweeperOpts is the options for the TrashSweeper
+type TrashSweeperOpts struct {
+	Config model.Config
+}
+
+// NewTrashSweeper returns a new instance of the trash sweeper
+func NewTrashSweeper(opts TrashSweeperOpts) (Server, error) {
+	dbInstance, err := db.New(opts.Config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	return newTrashSweeper(dbInstance, opts.Config.Trash), nil
+}
+
+func newTrashSweeper(gdb *gorm.DB, cfg model.Trash) *trashSweeper {
+	interval := cfg.SweepInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &trashSweeper{
+		todos:     service.NewTodo(repository.NewTodo(gdb), repository.NewProject(gdb), repository.NewWebhook(gdb)),
+		retention: cfg.Retention,
+		interval:  interval,
+		done:      make(chan struct{}),
+	}
+}
+
+func (s *trashSweeper) Name() string {
+	return "trashSweeper"
+}
+
+// Run sweeps the trash once and then on every interval until Shutdown is called
+func (s *trashSweeper) Run() error {
+	log.Infof("%s purging todos deleted more than %s ago every %s", s.Name(), s.retention, s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.sweep()
+		select {
+		case <-s.done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *trashSweeper) sweep() {
+	n, err := s.todos.PurgeExpired(s.retention)
+	if err != nil {
+		log.Error("failed to purge the trash err: ", err)
+		return
+	}
+	if n > 0 {
+		log.Infof("purged %d todos from the trash", n)
+	}
+}
+
+// Shutdown stops the trash sweeper
+func (s *trashSweeper) Shutdown(_ context.Context) error {
+	log.Infof("shuting down %s", s.Name())
+	s.stopOnce.Do(func() { close(s.done) })
+	return nil
+}
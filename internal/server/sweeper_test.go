@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashSweeper(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+
+	expired := &model.Todo{Task: "Long deleted", Status: model.Created, Priority: 1}
+	require.NoError(t, dbInstance.Create(expired).Error)
+	require.NoError(t, dbInstance.Delete(expired).Error)
+	require.NoError(t, dbInstance.Unscoped().Model(expired).Update("deleted_at", time.Now().Add(-48*time.Hour)).Error)
+
+	s := newTrashSweeper(dbInstance, model.Trash{Retention: 24 * time.Hour, SweepInterval: time.Hour})
+	done := make(chan error)
+	go func() { done <- s.Run() }()
+
+	require.Eventually(t, func() bool {
+		var n int64
+		dbInstance.Unscoped().Model(&model.Todo{}).Where("id = ?", expired.ID).Count(&n)
+		return n == 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, <-done)
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
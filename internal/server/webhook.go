@@ -0,0 +1,216 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/common"
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// deliveryBatchSize is the number of due deliveries sent per poll of the queue.
+const deliveryBatchSize = 50
+
+// webhookDispatcher sends the queued webhook deliveries, retrying failed ones with an exponential backoff
+type webhookDispatcher struct {
+	webhooks service.Webhook
+	client   *http.Client
+	policy   model.RetryPolicy
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WebhookDispatcherOpts is the options for the WebhookDispatcher
+type WebhookDispatcherOpts struct {
+	Config model.Config
+}
+
+// NewWebhookDispatcher returns a new instance of the webhook dispatcher
+func NewWebhookDispatcher(opts WebhookDispatcherOpts) (Server, error) {
+	dbInstance, err := db.New(opts.Config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	return newWebhookDispatcher(dbInstance, opts.Config.Webhooks), nil
+}
+
+func newWebhookDispatcher(gdb *gorm.DB, cfg model.Webhooks) *webhookDispatcher {
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	policy := model.RetryPolicy{MaxAttempts: cfg.MaxAttempts, Backoff: cfg.Backoff, MaxBackoff: cfg.MaxBackoff}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	// Without a backoff, failed deliveries would be retried on every poll.
+	if policy.Backoff <= 0 {
+		policy.Backoff = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivateTargets {
+		// The address is checked once resolved, so that a public name cannot point to a private address.
+		dialer.Control = refusePrivateAddress
+		// Through a proxy the dialed address would be the one of the proxy, never the one of the target.
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+	return &webhookDispatcher{
+		webhooks: service.NewWebhook(repository.NewWebhook(gdb)),
+		client:   &http.Client{Timeout: timeout, Transport: transport},
+		policy:   policy,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *webhookDispatcher) Name() string {
+	return "webhookDispatcher"
+}
+
+// Run sends the due deliveries once and then on every interval until Shutdown is called
+func (s *webhookDispatcher) Run() error {
+	log.Infof("%s sending webhook deliveries every %s", s.Name(), s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.dispatch()
+		select {
+		case <-s.done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch sends the due deliveries until none is left or the dispatcher is shut down.
+func (s *webhookDispatcher) dispatch() {
+	for s.ctx.Err() == nil {
+		deliveries, err := s.webhooks.DueDeliveries(deliveryBatchSize)
+		if err != nil {
+			log.Error("failed to read the webhook deliveries err: ", err)
+			return
+		}
+		for _, d := range deliveries {
+			if s.ctx.Err() != nil {
+				return
+			}
+			if err := s.deliver(d); err != nil {
+				// The delivery is still due, reading the queue again would return it at once.
+				// It is retried on the next interval.
+				return
+			}
+		}
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+// deliver attempts a delivery and saves its outcome. It returns an error when the delivery could neither be
+// attempted nor its outcome saved.
+func (s *webhookDispatcher) deliver(d *model.Delivery) error {
+	wh, err := s.webhooks.Recipient(d)
+	if err != nil {
+		if err == model.ErrNotFound {
+			// The webhook was just deleted, its deliveries are deleted with it.
+			return nil
+		}
+		log.Error("failed to read the webhook of delivery ", d.ID, " err: ", err)
+		return err
+	}
+	status, err := s.send(wh, d)
+	if s.ctx.Err() != nil {
+		// An attempt cut short by the shutdown is not counted, it is made again on the next start.
+		return nil
+	}
+	now := time.Now().UTC()
+	switch {
+	case err != nil:
+		d.Fail(now, 0, err.Error(), s.policy)
+	case status < 200 || status > 299:
+		d.Fail(now, status, http.StatusText(status), s.policy)
+	default:
+		d.Succeed(now, status)
+	}
+	if d.Status == model.FailedDelivery {
+		log.Warnf("webhook delivery %d to %s failed after %d attempts", d.ID, wh.URL, d.Attempts)
+	}
+	if err := s.webhooks.RecordAttempt(d); err != nil {
+		log.Error("failed to save the webhook delivery ", d.ID, " err: ", err)
+		return err
+	}
+	return nil
+}
+
+// send posts the payload of a delivery signed with the secret of the webhook, returning the response status.
+func (s *webhookDispatcher) send(wh *model.Webhook, d *model.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, wh.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-manager-app/"+common.GetVersion().String())
+	req.Header.Set(model.EventHeader, string(d.Event))
+	req.Header.Set(model.DeliveryHeader, fmt.Sprint(d.ID))
+	req.Header.Set(model.SignatureHeader, wh.Sign(d.Payload))
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// Draining the body lets the connection be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	return res.StatusCode, nil
+}
+
+// errPrivateAddress is the error of a delivery to an address the webhooks are not allowed to reach.
+var errPrivateAddress = errors.New("webhooks are not allowed to reach private, loopback or link-local addresses")
+
+// refusePrivateAddress is a dialer control refusing the connections to private, loopback and link-local addresses.
+func refusePrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// Shutdown stops the webhook dispatcher, interrupting the attempt in progress
+func (s *webhookDispatcher) Shutdown(_ context.Context) error {
+	log.Infof("shuting down %s", s.Name())
+	s.stopOnce.Do(func() {
+		s.cancel()
+		close(s.done)
+	})
+	return nil
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedDelivery is a delivery received by the test webhook.
+type receivedDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+
+	var (
+		mu       sync.Mutex
+		received []receivedDelivery
+		status   = http.StatusInternalServerError
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, receivedDelivery{header: r.Header, body: body})
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+	setStatus := func(s int) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = s
+	}
+
+	const owner = 4242
+	webhooks := service.NewWebhook(repository.NewWebhook(dbInstance)).ForOwner(owner)
+	wh, err := webhooks.Create(receiver.URL, []model.EventType{model.TodoCreated, model.TodoStatusChanged}, "0123456789abcdef")
+	require.NoError(t, err)
+	todos := service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance)).ForOwner(owner)
+	todo, err := todos.Create("Notify me", model.High, model.TodoDetails{})
+	require.NoError(t, err)
+	// A change of the priority alone is not subscribed to.
+	_, err = todos.Update(todo.ID, todo.Task, model.Low, todo.Status, model.TodoDetails{}, model.UpdateOptions{})
+	require.NoError(t, err)
+
+	s := newWebhookDispatcher(dbInstance, model.Webhooks{
+		PollInterval: time.Hour,
+		Timeout:      time.Second,
+		MaxAttempts:  2,
+		Backoff:      time.Minute,
+		// The test webhook listens on the loopback.
+		AllowPrivateTargets: true,
+	})
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+
+	// The first attempt fails and is retried after the backoff.
+	s.dispatch()
+	deliveries, err := webhooks.Deliveries(wh.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	d := deliveries[0]
+	assert.Equal(t, model.PendingDelivery, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, d.ResponseStatus)
+	require.NotNil(t, d.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *d.NextAttemptAt, 5*time.Second)
+
+	// Nothing is due before the backoff elapsed.
+	s.dispatch()
+	require.Len(t, received, 1)
+
+	// The last attempt fails the delivery.
+	require.NoError(t, dbInstance.Model(&model.Delivery{}).Where("id = ?", d.ID).Update("next_attempt_at", time.Now().UTC()).Error)
+	s.dispatch()
+	require.Len(t, received, 2)
+	deliveries, err = webhooks.Deliveries(wh.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, model.FailedDelivery, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Nil(t, deliveries[0].NextAttemptAt)
+
+	// A replay sends the same payload again.
+	setStatus(http.StatusNoContent)
+	replay, err := webhooks.Replay(wh.ID, d.ID)
+	require.NoError(t, err)
+	s.dispatch()
+	require.Len(t, received, 3)
+	deliveries, err = webhooks.Deliveries(wh.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, replay.ID, deliveries[0].ID)
+	assert.Equal(t, model.SucceededDelivery, deliveries[0].Status)
+	assert.Equal(t, http.StatusNoContent, deliveries[0].ResponseStatus)
+	_, err = webhooks.Replay(wh.ID, replay.ID)
+	assert.Equal(t, model.ErrDeliveryNotFailed, err)
+
+	last := received[2]
+	assert.Equal(t, received[0].body, last.body)
+	assert.Equal(t, "todo.created", last.header.Get(model.EventHeader))
+	assert.Equal(t, strconv.Itoa(replay.ID), last.header.Get(model.DeliveryHeader))
+	assert.Equal(t, wh.Sign(last.body), last.header.Get(model.SignatureHeader))
+	assert.Equal(t, "sha256=", last.header.Get(model.SignatureHeader)[:7])
+	var event model.Event
+	require.NoError(t, json.Unmarshal(last.body, &event))
+	assert.Equal(t, model.TodoCreated, event.Type)
+	assert.Equal(t, todo.ID, event.TodoID)
+	assert.Equal(t, "Notify me", event.Todo.Task)
+}
+
+func TestWebhookDispatcher_Shutdown(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+
+	s := newWebhookDispatcher(dbInstance, model.Webhooks{PollInterval: time.Hour})
+	assert.Equal(t, 30*time.Second, s.policy.Backoff, "failed deliveries are not retried on every poll")
+	done := make(chan error)
+	go func() { done <- s.Run() }()
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.NoError(t, <-done)
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestWebhookDispatcher_PrivateTargets(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	const owner = 4343
+	webhooks := service.NewWebhook(repository.NewWebhook(dbInstance)).ForOwner(owner)
+	wh, err := webhooks.Create(receiver.URL, []model.EventType{model.TodoCreated}, "")
+	require.NoError(t, err)
+	_, err = service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance)).ForOwner(owner).Create("Stay inside", model.Low, model.TodoDetails{})
+	require.NoError(t, err)
+
+	s := newWebhookDispatcher(dbInstance, model.Webhooks{PollInterval: time.Hour, MaxAttempts: 2})
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+	s.dispatch()
+	assert.Empty(t, received, "the loopback is not reached")
+	deliveries, err := webhooks.Deliveries(wh.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Contains(t, deliveries[0].LastError, errPrivateAddress.Error())
+	assert.Nil(t, s.client.Transport.(*http.Transport).Proxy, "the targets are dialed directly to be checked")
+
+	for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "192.168.0.1:80", "169.254.169.254:80", "[::1]:80", "0.0.0.0:80"} {
+		assert.Equal(t, errPrivateAddress, refusePrivateAddress("tcp", address, nil), address)
+	}
+	assert.NoError(t, refusePrivateAddress("tcp", "93.184.216.34:443", nil))
+}
+
+// unreadableWebhooks is a webhook service failing to read the webhooks of the deliveries.
+type unreadableWebhooks struct {
+	service.Webhook
+}
+
+func (unreadableWebhooks) Recipient(*model.Delivery) (*model.Webhook, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestWebhookDispatcher_UnreadableWebhook(t *testing.T) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+
+	const owner = 4444
+	webhooks := service.NewWebhook(repository.NewWebhook(dbInstance)).ForOwner(owner)
+	wh, err := webhooks.Create("https://example.com/hook", []model.EventType{model.TodoCreated}, "")
+	require.NoError(t, err)
+	todos := service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance)).ForOwner(owner)
+	for i := 0; i < deliveryBatchSize; i++ {
+		_, err = todos.Create("Queue "+strconv.Itoa(i), model.Low, model.TodoDetails{})
+		require.NoError(t, err)
+	}
+
+	// A full batch of deliveries that cannot be attempted stays due, the round stops instead of reading it again.
+	s := newWebhookDispatcher(dbInstance, model.Webhooks{PollInterval: time.Hour})
+	s.webhooks = unreadableWebhooks{s.webhooks}
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+	done := make(chan struct{})
+	go func() {
+		s.dispatch()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the dispatch round does not stop")
+	}
+	deliveries, err := webhooks.Deliveries(wh.ID, deliveryBatchSize)
+	require.NoError(t, err)
+	require.Len(t, deliveries, deliveryBatchSize)
+	for _, d := range deliveries {
+		assert.Equal(t, model.PendingDelivery, d.Status)
+		assert.Zero(t, d.Attempts)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// APIKey is the service for API keys.
+type APIKey interface {
+	Create(name string, scope model.Scope) (*model.APIKey, string, error)
+	Revoke(id int) error
+	FindAll() ([]*model.APIKey, error)
+	Verify(key string) (*model.APIKey, error)
+	ForOwner(userID int) APIKey
+}
+
+type apiKey struct {
+	apiKeyRepository repository.APIKey
+}
+
+// NewAPIKey creates a new APIKey service.
+func NewAPIKey(r repository.APIKey) APIKey {
+	return &apiKey{r}
+}
+
+// ForOwner returns the service acting on the API keys of the given user.
+func (a *apiKey) ForOwner(userID int) APIKey {
+	return &apiKey{a.apiKeyRepository.ForOwner(userID)}
+}
+
+// Create issues a new key, returning it in clear text for the only time.
+func (a *apiKey) Create(name string, scope model.Scope) (*model.APIKey, string, error) {
+	apiKey, key, err := model.NewAPIKey(name, scope)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := a.apiKeyRepository.Create(apiKey); err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (a *apiKey) Revoke(id int) error {
+	if err := a.apiKeyRepository.Revoke(id, time.Now().UTC()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *apiKey) FindAll() ([]*model.APIKey, error) {
+	keys, err := a.apiKeyRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Verify returns the unrevoked API key matching key and records its use.
+func (a *apiKey) Verify(key string) (*model.APIKey, error) {
+	apiKey, err := a.apiKeyRepository.FindByHash(model.HashAPIKey(key))
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := a.apiKeyRepository.Touch(apiKey.ID, now); err != nil {
+		return nil, err
+	}
+	apiKey.LastUsedAt = &now
+	return apiKey, nil
+}
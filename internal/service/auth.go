@@ -0,0 +1,91 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Auth is the service for user registration and authentication.
+type Auth interface {
+	Register(email, password string) (*model.User, error)
+	Login(email, password string) (*model.AccessToken, error)
+	Verify(token string) (int, error)
+}
+
+type auth struct {
+	userRepository repository.User
+	signingKey     []byte
+	tokenTTL       time.Duration
+}
+
+// NewAuth creates a new Auth service signing access tokens with the configured key.
+func NewAuth(r repository.User, cfg model.Auth) Auth {
+	ttl := cfg.TokenTTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &auth{r, []byte(cfg.SigningKey), ttl}
+}
+
+func (a *auth) Register(email, password string) (*model.User, error) {
+	user, err := model.NewUser(email, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.userRepository.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (a *auth) Login(email, password string) (*model.AccessToken, error) {
+	user, err := a.userRepository.FindByEmail(email)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if !user.CheckPassword(password) {
+		return nil, model.ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.tokenTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(user.ID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString(a.signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AccessToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// Verify returns the id of the user the access token was issued to.
+func (a *auth) Verify(token string) (int, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.signingKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, model.ErrInvalidToken
+	}
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, model.ErrInvalidToken
+	}
+	// Tokens of deleted accounts are rejected.
+	if _, err := a.userRepository.Find(userID); err != nil {
+		if err == model.ErrNotFound {
+			return 0, model.ErrInvalidToken
+		}
+		return 0, err
+	}
+	return userID, nil
+}
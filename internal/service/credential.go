@@ -0,0 +1,36 @@
+package service
+
+import "github.com/fardinabir/todo-manager-app/internal/model"
+
+// Credential is the service verifying the credentials sent with the requests, access tokens and API keys alike,
+// so that every API authenticates them the same way.
+type Credential interface {
+	Verify(credential string) (*model.Principal, error)
+}
+
+type credential struct {
+	auth    Auth
+	apiKeys APIKey
+}
+
+// NewCredential returns a new instance of the credential service.
+func NewCredential(auth Auth, apiKeys APIKey) Credential {
+	return &credential{auth: auth, apiKeys: apiKeys}
+}
+
+// Verify returns the user and scope of an API key or access token, the latter having full access.
+// It fails with model.ErrInvalidToken when the credential is unknown, expired or revoked.
+func (c *credential) Verify(credential string) (*model.Principal, error) {
+	if model.IsAPIKey(credential) {
+		key, err := c.apiKeys.Verify(credential)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Principal{UserID: key.UserID, Scope: key.Scope, APIKey: true}, nil
+	}
+	userID, err := c.auth.Verify(credential)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Principal{UserID: userID, Scope: model.WriteScope}, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// Project is the service for the project endpoint.
+type Project interface {
+	Create(name, color string, position *int) (*model.Project, error)
+	Update(id int, name, color string, archived *bool, position *int) (*model.Project, error)
+	Delete(id int) error
+	Find(id int) (*model.Project, error)
+	FindAll(qry url.Values) ([]*model.Project, error)
+	Todos(id int, qry url.Values, page model.PageRequest) (*model.TodoPage, error)
+	ForOwner(userID int) Project
+}
+
+type project struct {
+	projectRepository repository.Project
+	todoService       Todo
+}
+
+// NewProject creates a new Project service, listing the todos of projects through the given Todo service.
+func NewProject(r repository.Project, todos Todo) Project {
+	return &project{r, todos}
+}
+
+// ForOwner returns the service acting on the projects of the given user.
+func (p *project) ForOwner(userID int) Project {
+	return &project{p.projectRepository.ForOwner(userID), p.todoService.ForOwner(userID)}
+}
+
+func (p *project) Create(name, color string, position *int) (*model.Project, error) {
+	var pos int
+	if position != nil {
+		pos = *position
+	} else {
+		// New projects go last unless placed explicitly.
+		next, err := p.projectRepository.NextPosition()
+		if err != nil {
+			return nil, err
+		}
+		pos = next
+	}
+	project := model.NewProject(name, color, pos)
+	if err := p.projectRepository.Create(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (p *project) Update(id int, name, color string, archived *bool, position *int) (*model.Project, error) {
+	currentProject, err := p.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	project := model.NewUpdateProject(id, name, color)
+	if project.Name == "" {
+		project.Name = currentProject.Name
+	}
+	if project.Color == "" {
+		project.Color = currentProject.Color
+	}
+	project.Archived = currentProject.Archived
+	if archived != nil {
+		project.Archived = *archived
+	}
+	project.Position = currentProject.Position
+	if position != nil {
+		project.Position = *position
+	}
+	project.CreatedAt = currentProject.CreatedAt
+	if err := p.projectRepository.Update(project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (p *project) Delete(id int) error {
+	if err := p.projectRepository.Delete(id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *project) Find(id int) (*model.Project, error) {
+	project, err := p.projectRepository.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (p *project) FindAll(qry url.Values) ([]*model.Project, error) {
+	processedQry := map[string]interface{}{}
+	if val, ok := qry["archived"]; ok {
+		archived, err := strconv.ParseBool(val[0])
+		if err != nil {
+			return nil, err
+		}
+		processedQry["archived"] = archived
+	}
+	projects, err := p.projectRepository.FindAll(processedQry)
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// Todos lists the todos of the project, including those of an archived project.
+func (p *project) Todos(id int, qry url.Values, page model.PageRequest) (*model.TodoPage, error) {
+	if _, err := p.Find(id); err != nil {
+		return nil, err
+	}
+	projectQry := url.Values{}
+	for k, v := range qry {
+		projectQry[k] = v
+	}
+	projectQry.Set("project_id", strconv.Itoa(id))
+	return p.todoService.FindAll(projectQry, page)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// startSeries makes todo the first occurrence of a new series repeating from its due date.
+func startSeries(r repository.Todo, todo *model.Todo, recurrence string) error {
+	if todo.DueAt == nil {
+		return model.ErrRecurrenceWithoutDueDate
+	}
+	series, err := model.NewSeries(todo.Task, todo.Priority, recurrence, *todo.DueAt)
+	if err != nil {
+		return err
+	}
+	if err := r.CreateSeries(series); err != nil {
+		return err
+	}
+	occurrenceAt := series.Start
+	todo.SeriesID = &series.ID
+	todo.Series = series
+	todo.OccurrenceAt = &occurrenceAt
+	return nil
+}
+
+// editSeries applies an update of todo to its series when the scope covers future occurrences.
+// A recurrence given for a todo that does not repeat yet starts a new series.
+func editSeries(r repository.Todo, todo *model.Todo, recurrence string, scope model.EditScope) error {
+	if !todo.IsRecurring() {
+		if recurrence == "" {
+			return nil
+		}
+		return startSeries(r, todo, recurrence)
+	}
+	if scope != model.FutureOccurrences {
+		if recurrence != "" {
+			return model.ErrRecurrenceScope
+		}
+		return nil
+	}
+
+	series := todo.Series
+	if series == nil {
+		var err error
+		if series, err = r.FindSeries(*todo.SeriesID); err != nil {
+			return err
+		}
+	}
+	series.Task = todo.Task
+	series.Priority = todo.Priority
+	if recurrence != "" {
+		rule, err := model.NormalizeRecurrence(recurrence)
+		if err != nil {
+			return err
+		}
+		// A new rule restarts the series from this occurrence.
+		if rule != series.Recurrence {
+			series.Recurrence = rule
+			series.Start = *todo.OccurrenceAt
+		}
+	}
+	if err := r.UpdateSeries(series); err != nil {
+		return err
+	}
+	todo.Series = series
+	return nil
+}
+
+// scheduleNext creates the occurrence following done, unless the series has
+// ended or the occurrence already exists.
+func (t *todo) scheduleNext(r repository.Todo, done *model.Todo) error {
+	series, err := r.FindSeries(*done.SeriesID)
+	if err != nil {
+		return err
+	}
+	after := series.Start
+	if done.OccurrenceAt != nil {
+		after = *done.OccurrenceAt
+	}
+	next, ok := series.Next(after)
+	if !ok {
+		return nil
+	}
+	if _, err := r.FindOccurrence(series.ID, next); err == nil {
+		return nil
+	} else if err != model.ErrNotFound {
+		return err
+	}
+	todo := &model.Todo{
+		Task:         series.Task,
+		Priority:     series.Priority,
+		Status:       model.Created,
+		DueAt:        &next,
+		SeriesID:     &series.ID,
+		OccurrenceAt: &next,
+		ProjectID:    done.ProjectID,
+	}
+	if err := r.Create(todo); err != nil {
+		return err
+	}
+	return t.record(r, todo.ID, model.CreateAction, model.TodoState{}, model.NewTodoState(todo))
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+func (t *todo) History(id int) ([]*model.Revision, error) {
+	if _, err := t.todoRepository.Find(id); err == model.ErrNotFound {
+		// The history of a todo stays available while it is in the trash.
+		if _, err := t.todoRepository.FindDeleted(id); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	revisions, err := t.todoRepository.FindRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// Revert sets the fields of a todo back to their values right after the given revision.
+func (t *todo) Revert(id int, revisionID int) (*model.Todo, error) {
+	var todo *model.Todo
+	err := t.todoRepository.Transaction(func(r repository.Todo) error {
+		current, err := r.Find(id)
+		if err != nil {
+			return err
+		}
+		revisions, err := r.FindRevisions(id)
+		if err != nil {
+			return err
+		}
+		state, err := replay(revisions, revisionID)
+		if err != nil {
+			return err
+		}
+
+		todo = &model.Todo{}
+		*todo = *current
+		todo.Task = state.Task
+		todo.Status = state.Status
+		todo.Priority = state.Priority
+		todo.DueAt = state.DueAt
+		todo.ScheduledFor = state.ScheduledFor
+		todo.ParentID = state.ParentID
+		todo.ProjectID = state.ProjectID
+		if todo.ParentID != nil {
+			if err := checkParent(r, id, *todo.ParentID); err != nil {
+				return err
+			}
+		}
+		if todo.ProjectID != nil {
+			if err := t.checkProject(r, *todo.ProjectID); err != nil {
+				return err
+			}
+		}
+		if err := r.Update(todo); err != nil {
+			return err
+		}
+		tags := state.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		if err := r.SetTags(todo, tags); err != nil {
+			return err
+		}
+		rev := model.NewRevision(id, model.RevertAction, model.NewTodoState(current), model.NewTodoState(todo))
+		rev.RevertedTo = &revisionID
+		if err := r.CreateRevision(rev); err != nil {
+			return err
+		}
+		return t.notify(r, rev, model.NewTodoState(todo))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// replay rebuilds the state of a todo right after the given revision.
+func replay(revisions []*model.Revision, revisionID int) (model.TodoState, error) {
+	var state model.TodoState
+	for _, rev := range revisions {
+		var err error
+		if state, err = rev.Changes.Apply(state); err != nil {
+			return state, err
+		}
+		if rev.ID == revisionID {
+			return state, nil
+		}
+	}
+	return state, model.ErrRevisionNotFound
+}
+
+// record adds a revision of a todo going from the before to the after state.
+// An update that changed nothing is not recorded.
+func (t *todo) record(r repository.Todo, todoID int, action model.RevisionAction, before, after model.TodoState) error {
+	rev := model.NewRevision(todoID, action, before, after)
+	if action == model.UpdateAction && len(rev.Changes) == 0 {
+		return nil
+	}
+	if err := r.CreateRevision(rev); err != nil {
+		return err
+	}
+	return t.notify(r, rev, after)
+}
+
+// Events returns the events of the changes of every user following the given event, oldest first.
+func (t *todo) Events(afterID int, limit int) ([]*model.TodoEvent, error) {
+	revisions, err := t.todoRepository.FindRevisionsAfter(afterID, limit)
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	ids := make([]int, 0, len(revisions))
+	for _, rev := range revisions {
+		ids = append(ids, rev.TodoID)
+	}
+	todos, err := t.todoRepository.FindAllByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*model.Todo, len(todos))
+	for _, todo := range todos {
+		byID[todo.ID] = todo
+	}
+	events := make([]*model.TodoEvent, 0, len(revisions))
+	for _, rev := range revisions {
+		events = append(events, model.NewTodoEvent(rev, byID[rev.TodoID]))
+	}
+	return events, nil
+}
+
+// LastEventID returns the id of the latest event of every user, zero when there is none.
+func (t *todo) LastEventID() (int, error) {
+	return t.todoRepository.LastRevisionID()
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// Tag is the service for the tag endpoint.
+type Tag interface {
+	Create(name string) (*model.Tag, error)
+	Update(id int, name string) (*model.Tag, error)
+	Delete(id int) error
+	Find(id int) (*model.Tag, error)
+	FindAll() ([]*model.Tag, error)
+	ForOwner(userID int) Tag
+}
+
+type tag struct {
+	tagRepository repository.Tag
+}
+
+// NewTag creates a new Tag service.
+func NewTag(r repository.Tag) Tag {
+	return &tag{r}
+}
+
+// ForOwner returns the service acting on the tags of the given user.
+func (t *tag) ForOwner(userID int) Tag {
+	return &tag{t.tagRepository.ForOwner(userID)}
+}
+
+func (t *tag) Create(name string) (*model.Tag, error) {
+	tag := model.NewTag(name)
+	if err := t.tagRepository.Create(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (t *tag) Update(id int, name string) (*model.Tag, error) {
+	currentTag, err := t.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	tag := model.NewUpdateTag(id, name)
+	tag.CreatedAt = currentTag.CreatedAt
+	if err := t.tagRepository.Update(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (t *tag) Delete(id int) error {
+	if err := t.tagRepository.Delete(id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *tag) Find(id int) (*model.Tag, error) {
+	tag, err := t.tagRepository.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (t *tag) FindAll() ([]*model.Tag, error) {
+	tags, err := t.tagRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -3,6 +3,9 @@ package service
 
 import (
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fardinabir/todo-manager-app/internal/model"
 	"github.com/fardinabir/todo-manager-app/internal/repository"
@@ -10,79 +13,317 @@ import (
 
 // Todo is the service for the todo endpoint.
 type Todo interface {
-	Create(task string, priority model.Priority) (*model.Todo, error)
-	Update(id int, task string, priority model.Priority, status model.Status) (*model.Todo, error)
-	Delete(id int) error
+	Create(task string, priority model.Priority, details model.TodoDetails) (*model.Todo, error)
+	Update(id int, task string, priority model.Priority, status model.Status, details model.TodoDetails, opts model.UpdateOptions) (*model.Todo, error)
+	Delete(id int, mode model.DeleteMode, ifMatch model.Precondition) error
 	Find(id int) (*model.Todo, error)
-	FindAll(qry url.Values) ([]*model.Todo, error)
+	Children(id int) ([]*model.Todo, error)
+	ChildrenOf(ids []int) (map[int][]*model.Todo, error)
+	Subtree(id int) (*model.TodoNode, error)
+	FindAll(qry url.Values, page model.PageRequest) (*model.TodoPage, error)
+	Trash() ([]*model.TrashedTodo, error)
+	Restore(id int) (*model.Todo, error)
+	Purge(id int) error
+	EmptyTrash() (int64, error)
+	PurgeExpired(retention time.Duration) (int64, error)
+	History(id int) ([]*model.Revision, error)
+	Revert(id int, revisionID int) (*model.Todo, error)
+	Events(afterID int, limit int) ([]*model.TodoEvent, error)
+	LastEventID() (int, error)
+	Export(fn func(todo *model.Todo) error) error
+	Import(todo *model.Todo, tags []string, project string) (*model.Todo, error)
+	Projects() ([]*model.Project, error)
+	Transaction(fn func(s Todo) error) error
+	ForOwner(userID int) Todo
 }
 
 type todo struct {
-	todoRepository repository.Todo
+	todoRepository    repository.Todo
+	projectRepository repository.Project
+	webhookRepository repository.Webhook
 }
 
-// NewTodo creates a new Todo service.
-func NewTodo(r repository.Todo) Todo {
-	return &todo{r}
+// NewTodo creates a new Todo service, checking the projects of the todos in the given repository
+// and queueing the deliveries of their changes to the given webhooks.
+func NewTodo(r repository.Todo, projects repository.Project, webhooks repository.Webhook) Todo {
+	return &todo{r, projects, webhooks}
 }
 
-func (t *todo) Create(task string, priority model.Priority) (*model.Todo, error) {
-	todo := model.NewTodo(task, priority)
-	if err := t.todoRepository.Create(todo); err != nil {
+// ForOwner returns the service acting on the todos of the given user.
+func (t *todo) ForOwner(userID int) Todo {
+	return &todo{t.todoRepository.ForOwner(userID), t.projectRepository.ForOwner(userID), t.webhookRepository.ForOwner(userID)}
+}
+
+// Transaction runs fn with a service whose changes are all saved when fn succeeds and discarded otherwise.
+// Changes that fail within fn are discarded on their own.
+func (t *todo) Transaction(fn func(s Todo) error) error {
+	return t.todoRepository.Transaction(func(r repository.Todo) error {
+		return fn(&todo{r, t.projectRepository.Within(r.DB()), t.webhookRepository.Within(r.DB())})
+	})
+}
+
+func (t *todo) Create(task string, priority model.Priority, details model.TodoDetails) (*model.Todo, error) {
+	todo := model.NewTodo(task, priority, details)
+	err := t.todoRepository.Transaction(func(r repository.Todo) error {
+		if details.ParentID != nil {
+			if err := checkParent(r, 0, *details.ParentID); err != nil {
+				return err
+			}
+		}
+		if details.ProjectID != nil {
+			if err := t.checkProject(r, *details.ProjectID); err != nil {
+				return err
+			}
+		}
+		if details.Recurrence != "" {
+			if err := startSeries(r, todo, details.Recurrence); err != nil {
+				return err
+			}
+		}
+		if err := r.Create(todo); err != nil {
+			return err
+		}
+		if details.Tags != nil {
+			if err := r.SetTags(todo, details.Tags); err != nil {
+				return err
+			}
+		}
+		return t.record(r, todo.ID, model.CreateAction, model.TodoState{}, model.NewTodoState(todo))
+	})
+	if err != nil {
 		return nil, err
 	}
 	return todo, nil
 }
 
-func (t *todo) Update(id int, task string, priority model.Priority, status model.Status) (*model.Todo, error) {
-	todo := model.NewUpdateTodo(id, task, priority, status)
-	// 現在の値を取得
-	currentTodo, err := t.Find(id)
+// Update replaces the todo of the given id, the optional attributes left out of details are cleared.
+func (t *todo) Update(id int, task string, priority model.Priority, status model.Status, details model.TodoDetails, opts model.UpdateOptions) (*model.Todo, error) {
+	todo := model.NewUpdateTodo(id, task, priority, status, details)
+	err := t.todoRepository.Transaction(func(r repository.Todo) error {
+		// 現在の値を取得
+		currentTodo, err := r.Find(id)
+		if err != nil {
+			return err
+		}
+		if !opts.IfMatch.Allows(currentTodo.Version) {
+			return model.ErrVersionMismatch
+		}
+		// The todo is replaced as a whole, fields left out of details are cleared.
+		if todo.ParentID != nil {
+			if err := checkParent(r, id, *todo.ParentID); err != nil {
+				return err
+			}
+		}
+		if todo.ProjectID != nil {
+			if err := t.checkProject(r, *todo.ProjectID); err != nil {
+				return err
+			}
+		}
+		todo.CreatedAt = currentTodo.CreatedAt
+		todo.Version = currentTodo.Version
+		todo.SeriesID = currentTodo.SeriesID
+		todo.Series = currentTodo.Series
+		todo.OccurrenceAt = currentTodo.OccurrenceAt
+
+		if todo.Status == model.Done && currentTodo.Status != model.Done && !opts.Force {
+			if err := checkChildrenDone(r, id); err != nil {
+				return err
+			}
+		}
+		if err := editSeries(r, todo, details.Recurrence, opts.Scope); err != nil {
+			return err
+		}
+		if err := r.Update(todo); err != nil {
+			return err
+		}
+		tags := details.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		if err := r.SetTags(todo, tags); err != nil {
+			return err
+		}
+		if err := t.record(r, id, model.UpdateAction, model.NewTodoState(currentTodo), model.NewTodoState(todo)); err != nil {
+			return err
+		}
+		// Completing an occurrence of a recurring todo schedules the next one.
+		if todo.Status == model.Done && currentTodo.Status != model.Done && todo.IsRecurring() {
+			return t.scheduleNext(r, todo)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	// 空文字列の場合、現在の値を使用
-	if todo.Task == "" {
-		todo.Task = currentTodo.Task
-	}
-	if todo.Status == "" {
-		todo.Status = currentTodo.Status
+	return todo, nil
+}
+
+func (t *todo) Delete(id int, mode model.DeleteMode, ifMatch model.Precondition) error {
+	return t.todoRepository.Transaction(func(r repository.Todo) error {
+		todo, err := r.Find(id)
+		if err != nil {
+			return err
+		}
+		if !ifMatch.Allows(todo.Version) {
+			return model.ErrVersionMismatch
+		}
+		children, err := r.FindChildren(id)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			switch mode {
+			case model.CascadeDelete:
+				descendants, err := r.FindDescendants(id)
+				if err != nil {
+					return err
+				}
+				for _, d := range descendants {
+					if err := r.Delete(d); err != nil {
+						return err
+					}
+					state := model.NewTodoState(d)
+					if err := t.record(r, d.ID, model.DeleteAction, state, state); err != nil {
+						return err
+					}
+				}
+			case model.ReparentDelete:
+				if err := r.Reparent(id, todo.ParentID); err != nil {
+					return err
+				}
+				for _, child := range children {
+					before := model.NewTodoState(child)
+					after := before
+					after.ParentID = todo.ParentID
+					if err := t.record(r, child.ID, model.UpdateAction, before, after); err != nil {
+						return err
+					}
+				}
+			default:
+				return model.ErrHasChildren
+			}
+		}
+		if err := r.Delete(todo); err != nil {
+			return err
+		}
+		state := model.NewTodoState(todo)
+		return t.record(r, id, model.DeleteAction, state, state)
+	})
+}
+
+func (t *todo) Find(id int) (*model.Todo, error) {
+	todo, err := t.todoRepository.Find(id)
+	if err != nil {
+		return nil, err
 	}
-	if todo.Priority == 0 {
-		todo.Priority = currentTodo.Priority
+	return todo, nil
+}
+
+func (t *todo) Children(id int) ([]*model.Todo, error) {
+	if _, err := t.todoRepository.Find(id); err != nil {
+		return nil, err
 	}
-	if err := t.todoRepository.Update(todo); err != nil {
+	children, err := t.todoRepository.FindChildren(id)
+	if err != nil {
 		return nil, err
 	}
-	return todo, nil
+	return children, nil
 }
 
-func (t *todo) Delete(id int) error {
-	if err := t.todoRepository.Delete(id); err != nil {
-		return err
+// ChildrenOf returns the direct subtasks of several todos, by the id of their parent.
+func (t *todo) ChildrenOf(ids []int) (map[int][]*model.Todo, error) {
+	children, err := t.todoRepository.FindChildrenOf(ids)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	byParent := make(map[int][]*model.Todo, len(ids))
+	for _, child := range children {
+		byParent[*child.ParentID] = append(byParent[*child.ParentID], child)
+	}
+	return byParent, nil
 }
 
-func (t *todo) Find(id int) (*model.Todo, error) {
-	todo, err := t.todoRepository.Find(id)
+func (t *todo) Subtree(id int) (*model.TodoNode, error) {
+	root, err := t.todoRepository.Find(id)
 	if err != nil {
 		return nil, err
 	}
-	return todo, nil
+	descendants, err := t.todoRepository.FindDescendants(id)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewTodoTree(root, descendants), nil
 }
 
-func (t *todo) FindAll(qry url.Values) ([]*model.Todo, error) {
+func (t *todo) FindAll(qry url.Values, page model.PageRequest) (*model.TodoPage, error) {
 	processedQry := map[string]interface{}{}
+	if val, ok := qry["q"]; ok && val[0] != "" {
+		processedQry["q"] = val[0]
+	}
 	if val, ok := qry["task"]; ok {
 		processedQry["task"] = val[0]
 	}
 	if val, ok := qry["status"]; ok {
 		processedQry["status"] = val[0]
 	}
-	todo, err := t.todoRepository.FindAll(processedQry)
+	if val, ok := qry["project_id"]; ok {
+		projectID, err := strconv.Atoi(val[0])
+		if err != nil {
+			return nil, err
+		}
+		processedQry["project_id"] = projectID
+	}
+	for _, key := range []string{"tag", "tag_any", "tag_none"} {
+		if val, ok := qry[key]; ok {
+			if names := splitTagNames(val); len(names) > 0 {
+				processedQry[key] = names
+			}
+		}
+	}
+	if val, ok := qry["due_before"]; ok {
+		dueBefore, err := time.Parse(time.RFC3339, val[0])
+		if err != nil {
+			return nil, err
+		}
+		processedQry["due_before"] = dueBefore.UTC()
+	}
+	if val, ok := qry["due_after"]; ok {
+		dueAfter, err := time.Parse(time.RFC3339, val[0])
+		if err != nil {
+			return nil, err
+		}
+		processedQry["due_after"] = dueAfter.UTC()
+	}
+	if val, ok := qry["overdue"]; ok {
+		overdue, err := strconv.ParseBool(val[0])
+		if err != nil {
+			return nil, err
+		}
+		processedQry["overdue"] = overdue
+	}
+	todos, err := t.todoRepository.FindAll(processedQry, page)
 	if err != nil {
 		return nil, err
 	}
-	return todo, nil
+	return todos, nil
+}
+
+// splitTagNames returns the tag names of a filter given as repeated and/or comma separated values.
+func splitTagNames(values []string) []string {
+	var names []string
+	for _, v := range values {
+		names = append(names, strings.Split(v, ",")...)
+	}
+	return model.NormalizeTagNames(names)
+}
+
+// checkProject checks, in the transaction of r, that the project a todo is moved into exists.
+func (t *todo) checkProject(r repository.Todo, projectID int) error {
+	if _, err := t.projectRepository.Within(r.DB()).Find(projectID); err != nil {
+		if err == model.ErrNotFound {
+			return model.ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// Export calls fn with every todo in the order of their ids.
+func (t *todo) Export(fn func(todo *model.Todo) error) error {
+	return t.todoRepository.FindEach(fn)
+}
+
+// Projects returns the projects the todos can belong to.
+func (t *todo) Projects() ([]*model.Project, error) {
+	return t.projectRepository.FindAll(map[string]interface{}{})
+}
+
+// Import saves a todo read from a file. Unlike Create it keeps the id, status and timestamps
+// the todo already has, new ones are assigned to those left empty. The todo goes to the project
+// of the owner the file names, it is left outside of any project when none has that name.
+func (t *todo) Import(todo *model.Todo, tags []string, project string) (*model.Todo, error) {
+	if todo.Status == "" {
+		todo.Status = model.Created
+	}
+	err := t.todoRepository.Transaction(func(r repository.Todo) error {
+		if project != "" {
+			p, err := t.findProject(r, project)
+			if err != nil {
+				return err
+			}
+			if p != nil {
+				todo.ProjectID = &p.ID
+			}
+		}
+		if todo.ParentID != nil {
+			if err := checkParent(r, 0, *todo.ParentID); err != nil {
+				return err
+			}
+		}
+		if todo.ProjectID != nil {
+			if err := t.checkProject(r, *todo.ProjectID); err != nil {
+				return err
+			}
+		}
+		if err := r.Create(todo); err != nil {
+			return err
+		}
+		if len(tags) > 0 {
+			if err := r.SetTags(todo, tags); err != nil {
+				return err
+			}
+		}
+		return t.record(r, todo.ID, model.CreateAction, model.TodoState{}, model.NewTodoState(todo))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// findProject returns the project a file names, nil when the owner has none of that name.
+func (t *todo) findProject(r repository.Todo, name string) (*model.Project, error) {
+	projects, err := t.projectRepository.Within(r.DB()).FindAll(map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.MatchesToken(name) {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
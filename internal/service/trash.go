@@ -0,0 +1,72 @@
+package service
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+func (t *todo) Trash() ([]*model.TrashedTodo, error) {
+	todos, err := t.todoRepository.FindAllDeleted()
+	if err != nil {
+		return nil, err
+	}
+	return model.NewTrashedTodos(todos), nil
+}
+
+// Restore moves a todo out of the trash. A subtask whose parent is gone moves to the top level.
+func (t *todo) Restore(id int) (*model.Todo, error) {
+	var todo *model.Todo
+	err := t.todoRepository.Transaction(func(r repository.Todo) error {
+		if err := r.Restore(id); err != nil {
+			return err
+		}
+		var err error
+		if todo, err = r.Find(id); err != nil {
+			return err
+		}
+		before := model.NewTodoState(todo)
+		if todo.ParentID != nil {
+			_, err := r.Find(*todo.ParentID)
+			if err != nil && err != model.ErrNotFound {
+				return err
+			}
+			if err == model.ErrNotFound {
+				todo.ParentID = nil
+				if err := r.Update(todo); err != nil {
+					return err
+				}
+			}
+		}
+		return t.record(r, id, model.RestoreAction, before, model.NewTodoState(todo))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+func (t *todo) Purge(id int) error {
+	if err := t.todoRepository.Purge(id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *todo) EmptyTrash() (int64, error) {
+	n, err := t.todoRepository.PurgeAll()
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// PurgeExpired permanently deletes the todos of every user that have been in the trash longer than retention.
+func (t *todo) PurgeExpired(retention time.Duration) (int64, error) {
+	n, err := t.todoRepository.PurgeDeletedBefore(time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// checkParent verifies that the todo with the given id, 0 for a new one, can become a subtask of parentID.
+func checkParent(r repository.Todo, id, parentID int) error {
+	if parentID == id {
+		return model.ErrParentCycle
+	}
+	if _, err := r.Find(parentID); err != nil {
+		if err == model.ErrNotFound {
+			return model.ErrParentNotFound
+		}
+		return err
+	}
+	if id == 0 {
+		return nil
+	}
+	descendants, err := r.FindDescendants(id)
+	if err != nil {
+		return err
+	}
+	for _, d := range descendants {
+		if d.ID == parentID {
+			return model.ErrParentCycle
+		}
+	}
+	return nil
+}
+
+// checkChildrenDone verifies that every subtask below the given todo is done.
+func checkChildrenDone(r repository.Todo, id int) error {
+	descendants, err := r.FindDescendants(id)
+	if err != nil {
+		return err
+	}
+	for _, d := range descendants {
+		if d.Status != model.Done {
+			return model.ErrOpenChildren
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+)
+
+// Webhook is the service for webhooks and their deliveries.
+type Webhook interface {
+	Create(url string, events []model.EventType, secret string) (*model.Webhook, error)
+	Delete(id int) error
+	Find(id int) (*model.Webhook, error)
+	FindAll() ([]*model.Webhook, error)
+	Deliveries(id int, limit int) ([]*model.Delivery, error)
+	Replay(id int, deliveryID int) (*model.Delivery, error)
+	DueDeliveries(limit int) ([]*model.Delivery, error)
+	Recipient(d *model.Delivery) (*model.Webhook, error)
+	RecordAttempt(d *model.Delivery) error
+	ForOwner(userID int) Webhook
+}
+
+type webhook struct {
+	webhookRepository repository.Webhook
+}
+
+// NewWebhook creates a new Webhook service.
+func NewWebhook(r repository.Webhook) Webhook {
+	return &webhook{r}
+}
+
+// ForOwner returns the service acting on the webhooks of the given user.
+func (w *webhook) ForOwner(userID int) Webhook {
+	return &webhook{w.webhookRepository.ForOwner(userID)}
+}
+
+// Create subscribes a URL to events, generating the secret when none is given.
+func (w *webhook) Create(url string, events []model.EventType, secret string) (*model.Webhook, error) {
+	wh, err := model.NewWebhook(url, events, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.webhookRepository.Create(wh); err != nil {
+		return nil, err
+	}
+	return wh, nil
+}
+
+func (w *webhook) Delete(id int) error {
+	if err := w.webhookRepository.Delete(id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *webhook) Find(id int) (*model.Webhook, error) {
+	wh, err := w.webhookRepository.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	return wh, nil
+}
+
+func (w *webhook) FindAll() ([]*model.Webhook, error) {
+	webhooks, err := w.webhookRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Deliveries returns the latest deliveries of a webhook, newest first.
+func (w *webhook) Deliveries(id int, limit int) ([]*model.Delivery, error) {
+	if _, err := w.webhookRepository.Find(id); err != nil {
+		return nil, err
+	}
+	deliveries, err := w.webhookRepository.FindDeliveries(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Replay queues a failed delivery of a webhook again, as a new delivery due right away.
+func (w *webhook) Replay(id int, deliveryID int) (*model.Delivery, error) {
+	d, err := w.webhookRepository.FindDelivery(id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != model.FailedDelivery {
+		return nil, model.ErrDeliveryNotFailed
+	}
+	replay := d.Replay()
+	if err := w.webhookRepository.CreateDelivery(replay); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// DueDeliveries returns the pending deliveries of every user whose next attempt is due.
+func (w *webhook) DueDeliveries(limit int) ([]*model.Delivery, error) {
+	deliveries, err := w.webhookRepository.FindDueDeliveries(time.Now().UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Recipient returns the webhook a delivery is sent to, whoever owns it.
+func (w *webhook) Recipient(d *model.Delivery) (*model.Webhook, error) {
+	wh, err := w.webhookRepository.FindWebhook(d.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	return wh, nil
+}
+
+// RecordAttempt saves the outcome of an attempt of a delivery.
+func (w *webhook) RecordAttempt(d *model.Delivery) error {
+	return w.webhookRepository.UpdateDelivery(d)
+}
+
+// notify queues a delivery of the events of a revision for every webhook of the owner subscribing to them.
+// The deliveries are saved with the revision, in the transaction of r, they are discarded when the change is.
+func (t *todo) notify(r repository.Todo, rev *model.Revision, after model.TodoState) error {
+	deliveries := t.webhookRepository.Within(r.DB())
+	webhooks, err := deliveries.FindAll()
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	for _, eventType := range model.RevisionEvents(rev) {
+		event := &model.Event{
+			Type:       eventType,
+			OccurredAt: time.Now().UTC(),
+			TodoID:     rev.TodoID,
+			Todo:       after,
+		}
+		if rev.Action != model.CreateAction {
+			event.Changes = rev.Changes
+		}
+		for _, wh := range webhooks {
+			if !wh.Subscribes(eventType) {
+				continue
+			}
+			d, err := model.NewDelivery(wh.ID, event)
+			if err != nil {
+				return err
+			}
+			if err := deliveries.CreateDelivery(d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+)
+
+// csvColumns are the columns of an exported CSV file. Tags are separated by spaces,
+// which tag names cannot contain.
+var csvColumns = []string{
+	"id", "task", "status", "priority", "created_at", "updated_at",
+	"due_at", "scheduled_for", "parent_id", "project_id", "project", "tags",
+}
+
+// csvWriter writes records as the rows of a CSV file.
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(csvColumns)
+}
+
+func (c *csvWriter) Write(r *Record) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.w.Write([]string{
+		formatInt(&r.ID),
+		r.Task,
+		string(r.Status),
+		strconv.Itoa(int(r.Priority)),
+		formatTime(r.CreatedAt),
+		formatTime(r.UpdatedAt),
+		formatTime(r.DueAt),
+		formatTime(r.ScheduledFor),
+		formatInt(r.ParentID),
+		formatInt(r.ProjectID),
+		r.Project,
+		strings.Join(r.Tags, " "),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvReader reads records from the rows of a CSV file, its header row names the columns
+// in any order. Only the task and priority columns are required.
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"task", "priority"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", name)
+		}
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (*Record, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+		}
+		return nil, err
+	}
+	value := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	r := &Record{
+		Task:    value("task"),
+		Status:  model.Status(value("status")),
+		Project: value("project"),
+		Tags:    strings.Fields(value("tags")),
+	}
+	if r.ID, err = parseInt(value("id")); err != nil {
+		return nil, recordError("id", err)
+	}
+	priority, err := parseInt(value("priority"))
+	if err != nil {
+		return nil, recordError("priority", err)
+	}
+	r.Priority = model.Priority(priority)
+	times := map[string]**time.Time{
+		"created_at":    &r.CreatedAt,
+		"updated_at":    &r.UpdatedAt,
+		"due_at":        &r.DueAt,
+		"scheduled_for": &r.ScheduledFor,
+	}
+	for name, field := range times {
+		if *field, err = parseTime(value(name)); err != nil {
+			return nil, recordError(name, err)
+		}
+	}
+	ids := map[string]**int{
+		"parent_id":  &r.ParentID,
+		"project_id": &r.ProjectID,
+	}
+	for name, field := range ids {
+		if *field, err = parseOptionalInt(value(name)); err != nil {
+			return nil, recordError(name, err)
+		}
+	}
+	return r, nil
+}
+
+func recordError(column string, err error) error {
+	return fmt.Errorf("%w: invalid %s: %s", ErrInvalidRecord, column, err)
+}
+
+func formatInt(i *int) string {
+	if i == nil || *i == 0 {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func parseOptionalInt(s string) (*int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func parseTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,369 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+)
+
+// MIMECalendar is the media type of iCalendar files.
+const MIMECalendar = "text/calendar"
+
+// icsProductID identifies the application as the producer of the calendars it writes.
+const icsProductID = "-//todo-manager-app//Todos//EN"
+
+// icsUIDPattern matches the UIDs given to exported todos, other UIDs are not linked to todo ids.
+var icsUIDPattern = regexp.MustCompile(`^todo-(\d+)@todo-manager-app$`)
+
+// icsStatuses map todo statuses to VTODO statuses (RFC 5545 section 3.8.1.11).
+var icsStatuses = map[model.Status]string{
+	model.Created:    "NEEDS-ACTION",
+	model.Processing: "IN-PROCESS",
+	model.Done:       "COMPLETED",
+}
+
+// icsPriorities map todo priorities to VTODO priorities, 1 being the highest and 9 the lowest.
+var icsPriorities = map[model.Priority]int{
+	model.High:   1,
+	model.Medium: 5,
+	model.Low:    9,
+}
+
+const (
+	icsDateTime = "20060102T150405Z"
+	icsLocal    = "20060102T150405"
+	icsDate     = "20060102"
+)
+
+func icsUID(id int) string {
+	return fmt.Sprintf("todo-%d@todo-manager-app", id)
+}
+
+// icsWriter writes records as the VTODO components of a calendar.
+type icsWriter struct {
+	w     *bufio.Writer
+	begun bool
+}
+
+func newICSWriter(w io.Writer) *icsWriter {
+	return &icsWriter{w: bufio.NewWriter(w)}
+}
+
+func (c *icsWriter) begin() {
+	if c.begun {
+		return
+	}
+	c.begun = true
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", icsProductID)
+}
+
+func (c *icsWriter) Write(r *Record) error {
+	c.begin()
+	c.line("BEGIN", "VTODO")
+	c.line("UID", icsUID(r.ID))
+	stamp := time.Now()
+	if r.UpdatedAt != nil {
+		stamp = *r.UpdatedAt
+	}
+	c.line("DTSTAMP", stamp.UTC().Format(icsDateTime))
+	if r.CreatedAt != nil {
+		c.line("CREATED", r.CreatedAt.UTC().Format(icsDateTime))
+	}
+	if r.UpdatedAt != nil {
+		c.line("LAST-MODIFIED", r.UpdatedAt.UTC().Format(icsDateTime))
+	}
+	c.line("SUMMARY", icsEscape(r.Task))
+	if status, ok := icsStatuses[r.Status]; ok {
+		c.line("STATUS", status)
+	}
+	if priority, ok := icsPriorities[r.Priority]; ok {
+		c.line("PRIORITY", strconv.Itoa(priority))
+	}
+	if r.ScheduledFor != nil {
+		c.line("DTSTART", r.ScheduledFor.UTC().Format(icsDateTime))
+	}
+	if r.DueAt != nil {
+		c.line("DUE", r.DueAt.UTC().Format(icsDateTime))
+	}
+	if r.ParentID != nil {
+		c.line("RELATED-TO", icsUID(*r.ParentID))
+	}
+	if len(r.Tags) > 0 {
+		tags := make([]string, len(r.Tags))
+		for i, tag := range r.Tags {
+			tags[i] = icsEscape(tag)
+		}
+		c.line("CATEGORIES", strings.Join(tags, ","))
+	}
+	c.line("END", "VTODO")
+	return nil
+}
+
+func (c *icsWriter) Close() error {
+	c.begin()
+	c.line("END", "VCALENDAR")
+	return c.w.Flush()
+}
+
+// line writes a content line, folded after 75 octets without splitting UTF-8 sequences.
+// Write errors are kept by the buffered writer and reported by Flush.
+func (c *icsWriter) line(name, value string) {
+	s := name + ":" + value
+	// Continuation lines start with a space, which counts towards their length.
+	for limit := 75; len(s) > limit; limit = 74 {
+		n := limit
+		for n > 0 && s[n]&0xC0 == 0x80 {
+			n--
+		}
+		_, _ = c.w.WriteString(s[:n] + "\r\n ")
+		s = s[n:]
+	}
+	_, _ = c.w.WriteString(s + "\r\n")
+}
+
+// icsEscape escapes a TEXT value.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icsUnescape reverts icsEscape.
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// icsSplit splits a list of TEXT values on the commas that are not escaped.
+func icsSplit(s string) []string {
+	var (
+		values []string
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, icsUnescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, icsUnescape(s[start:]))
+}
+
+// icsProperty is a content line of a calendar.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICSProperty parses an unfolded content line.
+func parseICSProperty(line string) (icsProperty, error) {
+	// The value starts at the first colon outside of a quoted parameter value.
+	quoted, sep := false, -1
+	for i := 0; i < len(line) && sep < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				sep = i
+			}
+		}
+	}
+	if sep < 0 {
+		return icsProperty{}, fmt.Errorf("malformed content line: %q", line)
+	}
+	parts := strings.Split(line[:sep], ";")
+	p := icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[sep+1:]}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// time parses a DATE or DATE-TIME value. Floating times are read in the zone of the
+// TZID parameter when it is known, in UTC otherwise.
+func (p icsProperty) time() (*time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+	switch {
+	case strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == len(icsDate):
+		t, err = time.Parse(icsDate, p.value)
+	case strings.HasSuffix(p.value, "Z"):
+		t, err = time.Parse(icsDateTime, p.value)
+	default:
+		loc := time.UTC
+		if tz, lerr := time.LoadLocation(p.params["TZID"]); p.params["TZID"] != "" && lerr == nil {
+			loc = tz
+		}
+		t, err = time.ParseInLocation(icsLocal, p.value, loc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", p.name, p.value)
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// icsReader reads records from the VTODO components of a calendar, the other components are skipped.
+type icsReader struct {
+	s *bufio.Scanner
+	// next is the line read ahead to find out whether the current one is folded.
+	next *string
+}
+
+func newICSReader(r io.Reader) (*icsReader, error) {
+	c := &icsReader{s: bufio.NewScanner(r)}
+	line, err := c.line()
+	if err == io.EOF || (err == nil && !strings.EqualFold(line, "BEGIN:VCALENDAR")) {
+		return nil, fmt.Errorf("expected an iCalendar file starting with BEGIN:VCALENDAR")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// line returns the next unfolded content line.
+func (c *icsReader) line() (string, error) {
+	var line string
+	if c.next != nil {
+		line, c.next = *c.next, nil
+	} else if c.s.Scan() {
+		line = c.s.Text()
+	} else if err := c.s.Err(); err != nil {
+		return "", err
+	} else {
+		return "", io.EOF
+	}
+	for c.s.Scan() {
+		next := c.s.Text()
+		if !strings.HasPrefix(next, " ") && !strings.HasPrefix(next, "\t") {
+			c.next = &next
+			break
+		}
+		line += next[1:]
+	}
+	if err := c.s.Err(); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r"), nil
+}
+
+func (c *icsReader) Read() (*Record, error) {
+	for {
+		line, err := c.line()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(line, "BEGIN:VTODO") {
+			return c.readTodo()
+		}
+	}
+}
+
+// readTodo reads the properties of a VTODO up to its end. The first invalid property
+// is reported once the whole component is consumed so that reading can go on.
+func (c *icsReader) readTodo() (*Record, error) {
+	r := &Record{Status: model.Created, Priority: model.Medium}
+	var invalid error
+	for {
+		line, err := c.line()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(line, "END:VTODO") {
+			break
+		}
+		p, err := parseICSProperty(line)
+		if err == nil {
+			err = r.setICSProperty(p)
+		}
+		if err != nil && invalid == nil {
+			invalid = err
+		}
+	}
+	if invalid != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRecord, invalid)
+	}
+	return r, nil
+}
+
+// setICSProperty sets the field of the record a VTODO property maps to.
+func (r *Record) setICSProperty(p icsProperty) (err error) {
+	switch p.name {
+	case "UID":
+		if m := icsUIDPattern.FindStringSubmatch(p.value); m != nil {
+			r.ID, _ = strconv.Atoi(m[1])
+		}
+	case "SUMMARY":
+		r.Task = icsUnescape(p.value)
+	case "STATUS":
+		status := ""
+		for s, value := range icsStatuses {
+			if strings.EqualFold(value, p.value) {
+				status = string(s)
+			}
+		}
+		if status == "" {
+			return fmt.Errorf("unsupported STATUS: %q", p.value)
+		}
+		r.Status = model.Status(status)
+	case "PRIORITY":
+		priority, err := strconv.Atoi(p.value)
+		if err != nil || priority < 0 || priority > 9 {
+			return fmt.Errorf("invalid PRIORITY: %q", p.value)
+		}
+		switch {
+		case priority == 0:
+			// Undefined, the default priority is kept.
+		case priority < 5:
+			r.Priority = model.High
+		case priority == 5:
+			r.Priority = model.Medium
+		default:
+			r.Priority = model.Low
+		}
+	case "DUE":
+		r.DueAt, err = p.time()
+	case "DTSTART":
+		r.ScheduledFor, err = p.time()
+	case "CREATED":
+		r.CreatedAt, err = p.time()
+	case "LAST-MODIFIED":
+		r.UpdatedAt, err = p.time()
+	case "RELATED-TO":
+		if reltype := p.params["RELTYPE"]; reltype != "" && !strings.EqualFold(reltype, "PARENT") {
+			return nil
+		}
+		if m := icsUIDPattern.FindStringSubmatch(p.value); m != nil {
+			id, _ := strconv.Atoi(m[1])
+			r.ParentID = &id
+		}
+	case "CATEGORIES":
+		for _, category := range icsSplit(p.value) {
+			// Tag names cannot contain whitespace.
+			if tag := strings.Join(strings.Fields(category), "-"); tag != "" {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+)
+
+// todo.txt lines (https://github.com/todotxt/todo.txt) carry the priority as a letter, a leading "x"
+// marks completed tasks, +project and @context tokens name the project and the tags.
+// Attributes without a place in the format are written as the key:value extensions
+// due (DueAt), t (ScheduledFor), status (Processing) and pri (the priority of completed tasks).
+
+// todoTxtPriorities map priorities to todo.txt priority letters.
+var todoTxtPriorities = map[model.Priority]string{
+	model.High:   "A",
+	model.Medium: "B",
+	model.Low:    "C",
+}
+
+// todoTxtPriority matches the priority at the start of an incomplete task.
+var todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+const todoTxtDate = "2006-01-02"
+
+// todoTxtWriter writes records as the lines of a todo.txt file.
+type todoTxtWriter struct {
+	w *bufio.Writer
+}
+
+func newTodoTxtWriter(w io.Writer) *todoTxtWriter {
+	return &todoTxtWriter{w: bufio.NewWriter(w)}
+}
+
+func (t *todoTxtWriter) Write(r *Record) error {
+	var words []string
+	priority := todoTxtPriorities[r.Priority]
+	if r.Status == model.Done {
+		words = append(words, "x")
+		if r.UpdatedAt != nil {
+			// The last update of a completed task is the best guess of its completion date.
+			words = append(words, r.UpdatedAt.UTC().Format(todoTxtDate))
+		}
+	} else if priority != "" {
+		words = append(words, "("+priority+")")
+	}
+	if r.CreatedAt != nil && (r.Status != model.Done || r.UpdatedAt != nil) {
+		words = append(words, r.CreatedAt.UTC().Format(todoTxtDate))
+	}
+	words = append(words, strings.Fields(r.Task)...)
+	if r.Project != "" {
+		words = append(words, "+"+model.ProjectToken(r.Project))
+	}
+	for _, tag := range r.Tags {
+		words = append(words, "@"+tag)
+	}
+	if r.DueAt != nil {
+		words = append(words, "due:"+formatTodoTxtTime(*r.DueAt))
+	}
+	if r.ScheduledFor != nil {
+		words = append(words, "t:"+formatTodoTxtTime(*r.ScheduledFor))
+	}
+	if r.Status == model.Processing {
+		words = append(words, "status:"+string(model.Processing))
+	}
+	if r.Status == model.Done && priority != "" {
+		words = append(words, "pri:"+priority)
+	}
+	_, err := t.w.WriteString(strings.Join(words, " ") + "\n")
+	return err
+}
+
+func (t *todoTxtWriter) Close() error {
+	return t.w.Flush()
+}
+
+// formatTodoTxtTime writes a time as a date when it is at midnight UTC, in RFC 3339 otherwise.
+func formatTodoTxtTime(t time.Time) string {
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format(todoTxtDate)
+	}
+	return t.Format(time.RFC3339)
+}
+
+func parseTodoTxtTime(s string) (*time.Time, error) {
+	t, err := time.Parse(todoTxtDate, s)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// todoTxtReader reads records from the lines of a todo.txt file, blank lines are skipped.
+type todoTxtReader struct {
+	s *bufio.Scanner
+}
+
+func newTodoTxtReader(r io.Reader) *todoTxtReader {
+	return &todoTxtReader{s: bufio.NewScanner(r)}
+}
+
+func (t *todoTxtReader) Read() (*Record, error) {
+	for t.s.Scan() {
+		if line := strings.TrimSpace(t.s.Text()); line != "" {
+			r, err := parseTodoTxtLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+			}
+			return r, nil
+		}
+	}
+	if err := t.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// parseTodoTxtLine parses a task. A priority letter after C is read as the low priority,
+// tasks without one have the medium priority.
+func parseTodoTxtLine(line string) (*Record, error) {
+	r := &Record{Status: model.Created, Priority: model.Medium}
+	words := strings.Fields(line)
+	date := func() *time.Time {
+		if len(words) == 0 {
+			return nil
+		}
+		d, err := time.Parse(todoTxtDate, words[0])
+		if err != nil {
+			return nil
+		}
+		words = words[1:]
+		return &d
+	}
+
+	priority := ""
+	if words[0] == "x" {
+		r.Status = model.Done
+		words = words[1:]
+		// A completion date comes before the creation date, which is only present with it.
+		if completed := date(); completed != nil {
+			r.UpdatedAt = completed
+			r.CreatedAt = date()
+		}
+	} else {
+		if m := todoTxtPriority.FindStringSubmatch(words[0]); m != nil {
+			priority = m[1]
+			words = words[1:]
+		}
+		r.CreatedAt = date()
+	}
+
+	var task []string
+	for _, word := range words {
+		var err error
+		key, value, _ := strings.Cut(word, ":")
+		switch {
+		case len(word) > 1 && word[0] == '+' && r.Project == "":
+			r.Project = word[1:]
+		case len(word) > 1 && word[0] == '@':
+			r.Tags = append(r.Tags, word[1:])
+		case key == "due" && value != "":
+			r.DueAt, err = parseTodoTxtTime(value)
+		case key == "t" && value != "":
+			r.ScheduledFor, err = parseTodoTxtTime(value)
+		case key == "pri" && todoTxtPriority.MatchString("("+value+")"):
+			priority = value
+		case key == "status" && value != "":
+			if r.Status != model.Done {
+				r.Status = model.Status(value)
+			}
+		default:
+			task = append(task, word)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", key, value)
+		}
+	}
+	r.Task = strings.Join(task, " ")
+
+	switch priority {
+	case "":
+	case "A":
+		r.Priority = model.High
+	case "B":
+		r.Priority = model.Medium
+	default:
+		r.Priority = model.Low
+	}
+	return r, nil
+}
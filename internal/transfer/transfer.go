@@ -0,0 +1,278 @@
+// Package transfer moves todos in and out of the application as files.
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/go-playground/validator/v10"
+)
+
+// Format is a file format todos are exported to and imported from.
+type Format string
+
+const (
+	// JSON is a JSON array of records.
+	JSON = Format("json")
+	// CSV is a header row naming the fields followed by one row per record.
+	CSV = Format("csv")
+	// ICS is an iCalendar file with a VTODO component per record.
+	ICS = Format("ics")
+	// TodoTxt is a todo.txt file with a line per record.
+	TodoTxt = Format("todotxt")
+)
+
+// ErrUnknownFormat is the error for a format no reader or writer exists for.
+var ErrUnknownFormat = fmt.Errorf("unknown format")
+
+// ErrInvalidRecord is the error for a record of a file that cannot be read, the records after it still can.
+var ErrInvalidRecord = fmt.Errorf("invalid record")
+
+// Record is a todo as written to a file.
+type Record struct {
+	ID           int            `json:"id,omitempty"`
+	Task         string         `json:"task" validate:"required"`
+	Status       model.Status   `json:"status,omitempty" validate:"validStatus"`
+	Priority     model.Priority `json:"priority" validate:"required,validPriority"`
+	CreatedAt    *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time     `json:"updated_at,omitempty"`
+	DueAt        *time.Time     `json:"due_at,omitempty"`
+	ScheduledFor *time.Time     `json:"scheduled_for,omitempty"`
+	ParentID     *int           `json:"parent_id,omitempty"`
+	ProjectID    *int           `json:"project_id,omitempty"`
+	// Project is the name of the project, it is used instead of ProjectID on import
+	// since project ids differ between environments and users.
+	Project string   `json:"project,omitempty"`
+	Tags    []string `json:"tags,omitempty" validate:"dive,validTagName"`
+}
+
+// validate checks records with the same rules as the requests of the API.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("validPriority", model.IsValidPriority)
+	_ = v.RegisterValidation("validStatus", model.IsValidStatus)
+	_ = v.RegisterValidation("validTagName", model.IsValidTagName)
+	return v
+}
+
+// ProjectNames returns the names of the projects of the service by their id.
+func ProjectNames(s service.Todo) (map[int]string, error) {
+	projects, err := s.Projects()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+	return names, nil
+}
+
+// NewRecord returns the record of a todo, naming its project after the given project names.
+func NewRecord(t *model.Todo, projects map[int]string) *Record {
+	createdAt, updatedAt := t.CreatedAt.UTC(), t.UpdatedAt.UTC()
+	r := &Record{
+		ID:           t.ID,
+		Task:         t.Task,
+		Status:       t.Status,
+		Priority:     t.Priority,
+		CreatedAt:    &createdAt,
+		UpdatedAt:    &updatedAt,
+		DueAt:        t.DueAt,
+		ScheduledFor: t.ScheduledFor,
+		ParentID:     t.ParentID,
+		ProjectID:    t.ProjectID,
+	}
+	if t.ProjectID != nil {
+		r.Project = projects[*t.ProjectID]
+	}
+	if len(t.Tags) > 0 {
+		r.Tags = t.Tags.Names()
+	}
+	return r
+}
+
+// Validate checks that the record can be imported.
+func (r *Record) Validate() error {
+	return validate.Struct(r)
+}
+
+// Writer writes records to a file.
+type Writer interface {
+	Write(r *Record) error
+	// Close writes what remains of the file, it does not close the underlying writer.
+	Close() error
+}
+
+// Reader reads records from a file.
+type Reader interface {
+	// Read returns the next record, io.EOF after the last one, and an error wrapping
+	// ErrInvalidRecord for a record that cannot be read.
+	Read() (*Record, error)
+}
+
+// NewWriter returns a writer of the given format.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case JSON:
+		return newJSONWriter(w), nil
+	case CSV:
+		return newCSVWriter(w), nil
+	case ICS:
+		return newICSWriter(w), nil
+	case TodoTxt:
+		return newTodoTxtWriter(w), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// NewReader returns a reader of the given format.
+func NewReader(format Format, r io.Reader) (Reader, error) {
+	switch format {
+	case JSON:
+		return newJSONReader(r)
+	case CSV:
+		return newCSVReader(r)
+	case ICS:
+		return newICSReader(r)
+	case TodoTxt:
+		return newTodoTxtReader(r), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+// Export writes every todo of the service and returns how many were written.
+func Export(s service.Todo, w Writer) (int, error) {
+	projects, err := ProjectNames(s)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	err = s.Export(func(t *model.Todo) error {
+		n++
+		return w.Write(NewRecord(t, projects))
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, w.Close()
+}
+
+// ImportOptions control how records are turned into todos.
+type ImportOptions struct {
+	// KeepIDs imports the todos with the ids of their records instead of new ones.
+	KeepIDs bool
+	// KeepTimestamps imports the todos with the creation and update times of their records.
+	KeepTimestamps bool
+}
+
+// RowError is a record that was not imported.
+type RowError struct {
+	// Row is the position of the record in the file, starting at 1.
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult is the outcome of an import.
+type ImportResult struct {
+	// Imported is the number of todos created.
+	Imported int
+	// Rejected are the records that were not imported.
+	Rejected []*RowError
+}
+
+// Import creates a todo for every valid record read. Invalid records are reported in the result
+// without stopping the import, which only fails when the file itself cannot be read any further.
+func Import(s service.Todo, r Reader, opts ImportOptions) (*ImportResult, error) {
+	res := &ImportResult{}
+	// ids maps the ids of the records to the ids of the todos they were imported as.
+	ids := map[int]int{}
+	for row := 1; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err == nil {
+			err = rec.Validate()
+		}
+		if err == nil {
+			err = importRecord(s, rec, opts, ids)
+		}
+		if err != nil {
+			if _, ok := err.(validator.ValidationErrors); !ok && !isRecordError(err) {
+				return res, err
+			}
+			res.Rejected = append(res.Rejected, &RowError{Row: row, Err: err})
+			continue
+		}
+		res.Imported++
+	}
+}
+
+// importRecord creates the todo of a record.
+func importRecord(s service.Todo, rec *Record, opts ImportOptions, ids map[int]int) error {
+	t := &model.Todo{
+		Task:         rec.Task,
+		Status:       rec.Status,
+		Priority:     rec.Priority,
+		DueAt:        utc(rec.DueAt),
+		ScheduledFor: utc(rec.ScheduledFor),
+		ParentID:     rec.ParentID,
+	}
+	if opts.KeepIDs {
+		t.ID = rec.ID
+	} else if rec.ParentID != nil {
+		// The parent must have been imported before its subtasks to know its new id.
+		id, ok := ids[*rec.ParentID]
+		if !ok {
+			return model.ErrParentNotFound
+		}
+		t.ParentID = &id
+	}
+	if opts.KeepTimestamps {
+		if rec.CreatedAt != nil {
+			t.CreatedAt = rec.CreatedAt.UTC()
+		}
+		if rec.UpdatedAt != nil {
+			t.UpdatedAt = rec.UpdatedAt.UTC()
+		}
+	}
+	if _, err := s.Import(t, rec.Tags, rec.Project); err != nil {
+		return err
+	}
+	if rec.ID != 0 {
+		ids[rec.ID] = t.ID
+	}
+	return nil
+}
+
+// isRecordError reports whether err only concerns a single record.
+func isRecordError(err error) bool {
+	return errors.Is(err, ErrInvalidRecord) ||
+		err == model.ErrParentNotFound ||
+		err == model.ErrParentCycle ||
+		err == model.ErrProjectNotFound ||
+		err == model.ErrDuplicate
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,238 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardinabir/todo-manager-app/internal/db"
+	"github.com/fardinabir/todo-manager-app/internal/model"
+	"github.com/fardinabir/todo-manager-app/internal/repository"
+	"github.com/fardinabir/todo-manager-app/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newService(t *testing.T) (service.Todo, *gorm.DB) {
+	dbInstance, err := db.NewMemory()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbInstance))
+	clear := func() {
+		dbInstance.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&model.Todo{})
+		dbInstance.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&model.Project{})
+	}
+	clear()
+	t.Cleanup(clear)
+	return service.NewTodo(repository.NewTodo(dbInstance), repository.NewProject(dbInstance), repository.NewWebhook(dbInstance)), dbInstance
+}
+
+func TestExportImport(t *testing.T) {
+	for _, format := range []Format{JSON, CSV, ICS} {
+		t.Run(string(format), func(t *testing.T) {
+			s, dbInstance := newService(t)
+			due := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+			parent, err := s.Create("Parent, with \"quotes\"", model.High, model.TodoDetails{DueAt: &due, Tags: []string{"work", "home"}})
+			require.NoError(t, err)
+			_, err = s.Create("Child", model.Low, model.TodoDetails{ParentID: &parent.ID})
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			w, err := NewWriter(format, &buf)
+			require.NoError(t, err)
+			n, err := Export(s, w)
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			// Importing next to the exported todos assigns new ids and links the child to the new parent.
+			r, err := NewReader(format, bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			res, err := Import(s, r, ImportOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, 2, res.Imported)
+			assert.Empty(t, res.Rejected)
+
+			var todos []*model.Todo
+			require.NoError(t, dbInstance.Preload("Tags").Order("id").Find(&todos).Error)
+			require.Len(t, todos, 4)
+			assert.Equal(t, parent.Task, todos[2].Task)
+			assert.Equal(t, model.High, todos[2].Priority)
+			assert.True(t, due.Equal(*todos[2].DueAt))
+			assert.ElementsMatch(t, []string{"home", "work"}, todos[2].Tags.Names())
+			require.NotNil(t, todos[3].ParentID)
+			assert.Equal(t, todos[2].ID, *todos[3].ParentID)
+
+			// Keeping the ids collides with the exported todos.
+			r, err = NewReader(format, bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			res, err = Import(s, r, ImportOptions{KeepIDs: true})
+			require.NoError(t, err)
+			assert.Zero(t, res.Imported)
+			require.Len(t, res.Rejected, 2)
+			assert.ErrorIs(t, res.Rejected[0], model.ErrDuplicate)
+		})
+	}
+}
+
+func TestImport_KeepIDsAndTimestamps(t *testing.T) {
+	s, dbInstance := newService(t)
+	input := `[
+		{"id": 100, "task": "Old", "status": "done", "priority": 2, "created_at": "2020-01-01T10:00:00Z", "updated_at": "2020-02-01T10:00:00Z"},
+		{"id": 101, "task": "Old child", "priority": 1, "parent_id": 100}
+	]`
+	r, err := NewReader(JSON, strings.NewReader(input))
+	require.NoError(t, err)
+	res, err := Import(s, r, ImportOptions{KeepIDs: true, KeepTimestamps: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Imported)
+
+	todo, err := s.Find(100)
+	require.NoError(t, err)
+	assert.Equal(t, model.Done, todo.Status)
+	assert.True(t, time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC).Equal(todo.CreatedAt))
+	assert.True(t, time.Date(2020, 2, 1, 10, 0, 0, 0, time.UTC).Equal(todo.UpdatedAt))
+
+	child, err := s.Find(101)
+	require.NoError(t, err)
+	assert.Equal(t, model.Created, child.Status)
+	require.NotNil(t, child.ParentID)
+	assert.Equal(t, 100, *child.ParentID)
+
+	var count int64
+	require.NoError(t, dbInstance.Model(&model.Revision{}).Where("todo_id IN ?", []int{100, 101}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestImport_Projects(t *testing.T) {
+	s, dbInstance := newService(t)
+	projects := repository.NewProject(dbInstance)
+	home := model.NewProject("Home Chores", "", 0)
+	require.NoError(t, projects.Create(home))
+	other := model.NewProject("Other user's", "", 0)
+	require.NoError(t, projects.ForOwner(4444).Create(other))
+
+	// Project ids come from another database, only the names are resolved.
+	input := fmt.Sprintf(`[
+		{"task": "By name", "priority": 1, "project_id": %d, "project": "home-chores"},
+		{"task": "Unknown name", "priority": 1, "project_id": %d, "project": "Garden"},
+		{"task": "By id only", "priority": 1, "project_id": %d}
+	]`, other.ID, home.ID, home.ID)
+	r, err := NewReader(JSON, strings.NewReader(input))
+	require.NoError(t, err)
+	res, err := Import(s, r, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Imported)
+
+	var todos []*model.Todo
+	require.NoError(t, dbInstance.Order("id").Find(&todos).Error)
+	require.Len(t, todos, 3)
+	require.NotNil(t, todos[0].ProjectID)
+	assert.Equal(t, home.ID, *todos[0].ProjectID)
+	assert.Nil(t, todos[1].ProjectID)
+	assert.Nil(t, todos[2].ProjectID)
+	all, err := s.Projects()
+	require.NoError(t, err)
+	assert.Len(t, all, 1, "no project is created")
+}
+
+func TestImport_RejectedRows(t *testing.T) {
+	s, _ := newService(t)
+	input := "task,priority,status,due_at,parent_id,tags\n" +
+		"Valid,1,,,,\n" +
+		",1,,,,\n" +
+		"Bad priority,7,,,,\n" +
+		"Bad status,1,archived,,,\n" +
+		"Bad date,1,,tomorrow,,\n" +
+		"Missing parent,1,,,42,\n" +
+		"Too few columns,1\n" +
+		"Also valid,3,processing,2030-01-01T00:00:00Z,,a b\n"
+	r, err := NewReader(CSV, strings.NewReader(input))
+	require.NoError(t, err)
+	res, err := Import(s, r, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Imported)
+
+	var rows []int
+	for _, rejected := range res.Rejected {
+		rows = append(rows, rejected.Row)
+	}
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7}, rows)
+	assert.ErrorIs(t, res.Rejected[3], ErrInvalidRecord)
+	assert.ErrorIs(t, res.Rejected[4], model.ErrParentNotFound)
+}
+
+func TestNewReader_Invalid(t *testing.T) {
+	_, err := NewReader("xml", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	_, err = NewReader(JSON, strings.NewReader(`{"task": "not an array"}`))
+	assert.Error(t, err)
+	_, err = NewReader(CSV, strings.NewReader("id,name\n"))
+	assert.Error(t, err)
+
+	// A truncated JSON file stops the import after the records read so far.
+	r, err := NewReader(JSON, strings.NewReader(`[{"task": "Kept", "priority": 1}, {"task": `))
+	require.NoError(t, err)
+	s, _ := newService(t)
+	res, err := Import(s, r, ImportOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, res.Imported)
+}
+
+func TestTodoTxt(t *testing.T) {
+	s, dbInstance := newService(t)
+	require.NoError(t, repository.NewProject(dbInstance).Create(model.NewProject("Family Affairs", "", 0)))
+	input := "(A) 2024-03-01 Call Mom +Family-Affairs @phone due:2024-03-05\n" +
+		"\n" +
+		"x 2024-03-04 2024-03-02 Pay rent @home pri:C\n" +
+		"(F) Read book status:processing t:2024-03-10T08:00:00Z see:notes\n" +
+		"Plan trip +family-affairs +Other\n" +
+		"Bad due date due:tomorrow\n"
+	r, err := NewReader(TodoTxt, strings.NewReader(input))
+	require.NoError(t, err)
+	res, err := Import(s, r, ImportOptions{KeepTimestamps: true})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Imported)
+	require.Len(t, res.Rejected, 1)
+	assert.Equal(t, 5, res.Rejected[0].Row)
+	assert.ErrorIs(t, res.Rejected[0], ErrInvalidRecord)
+
+	var todos []*model.Todo
+	require.NoError(t, dbInstance.Preload("Tags").Order("id").Find(&todos).Error)
+	require.Len(t, todos, 4)
+	assert.Equal(t, "Call Mom", todos[0].Task)
+	assert.Equal(t, model.High, todos[0].Priority)
+	assert.True(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Equal(todos[0].CreatedAt))
+	assert.True(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC).Equal(*todos[0].DueAt))
+	assert.Equal(t, []string{"phone"}, todos[0].Tags.Names())
+	assert.Equal(t, "Pay rent", todos[1].Task)
+	assert.Equal(t, model.Done, todos[1].Status)
+	assert.Equal(t, model.Low, todos[1].Priority)
+	assert.True(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC).Equal(todos[1].UpdatedAt))
+	assert.Equal(t, "Read book see:notes", todos[2].Task)
+	assert.Equal(t, model.Processing, todos[2].Status)
+	assert.Equal(t, model.Low, todos[2].Priority)
+	assert.True(t, time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC).Equal(*todos[2].ScheduledFor))
+	assert.Equal(t, "Plan trip +Other", todos[3].Task)
+	assert.Equal(t, model.Medium, todos[3].Priority)
+
+	// Both lines naming the project go to it, whatever the case.
+	require.NotNil(t, todos[0].ProjectID)
+	require.NotNil(t, todos[3].ProjectID)
+	assert.Equal(t, *todos[0].ProjectID, *todos[3].ProjectID)
+	projects, err := s.Projects()
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "Family Affairs", projects[0].Name)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(TodoTxt, &buf)
+	require.NoError(t, err)
+	n, err := Export(s, w)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "(A) 2024-03-01 Call Mom +Family-Affairs @phone due:2024-03-05\n"+
+		"x 2024-03-04 2024-03-02 Pay rent @home pri:C\n", strings.Join(strings.SplitAfter(buf.String(), "\n")[:2], ""))
+	assert.Contains(t, buf.String(), " Read book see:notes t:2024-03-10T08:00:00Z status:processing\n")
+}
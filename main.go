@@ -13,6 +13,11 @@ import (
 // @host			localhost:8080
 // @BasePath		/api/v1
 // @schemes		http
+//
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+// @description				Access token from /auth/login, sent as "Bearer <token>".
 func main() {
 	cmd.Execute()
 }